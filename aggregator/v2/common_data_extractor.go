@@ -6,30 +6,23 @@ import (
 	"github.com/fabiofenoglio/excelconv/config"
 )
 
-func ExtractCommonData(_ config.WorkflowContext, rows []Row) CommonData {
+func ExtractCommonData(ctx config.WorkflowContext, rows []Row) CommonData {
 
 	return CommonData{
-		CommonTimespan: extractCommonTimespan(rows),
+		CommonTimespan: extractCommonTimespan(rows, ctx.Config.Location()),
 	}
 }
 
-func extractCommonTimespan(rows []Row) CommonTimespan {
+func extractCommonTimespan(rows []Row, location *time.Location) CommonTimespan {
 
 	// compute the max time range to be shown between all days
 	minHourToShow := TimeOfDay{12, 0}
 	maxHourToShow := TimeOfDay{14, 0}
 
-	relativeToDay := func(startTime time.Time, competenceDate time.Time) TimeOfDay {
-		if startTime.YearDay() == competenceDate.YearDay() {
-			return TimeOfDay{startTime.Hour(), startTime.Minute()}
-		}
-		return TimeOfDay{startTime.Hour() + 24, startTime.Minute()}
-	}
-
 	for _, row := range rows {
 		activity := row.InputRow
 		if !activity.StartTime.IsZero() {
-			startRelative := relativeToDay(activity.StartTime, row.CompetenceDate)
+			startRelative := RelativeToDay(activity.StartTime, row.CompetenceDate, location)
 			if startRelative.IsBefore(minHourToShow) {
 				minHourToShow = startRelative
 			}
@@ -39,7 +32,7 @@ func extractCommonTimespan(rows []Row) CommonTimespan {
 		}
 
 		if !activity.EndTime.IsZero() {
-			endRelative := relativeToDay(activity.EndTime, row.CompetenceDate)
+			endRelative := RelativeToDay(activity.EndTime, row.CompetenceDate, location)
 			if endRelative.IsBefore(minHourToShow) {
 				minHourToShow = endRelative
 			}
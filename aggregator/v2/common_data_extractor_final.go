@@ -12,13 +12,22 @@ func ExtractCommonDataFinal(
 	output := currentCommonData
 
 	maxVisitingGroups := 0
+	maxLunchRows := 0
 
 	for _, day := range daysWithRoomsAndGroupingSlots {
 		if len(day.VisitingGroups) > maxVisitingGroups {
 			maxVisitingGroups = len(day.VisitingGroups)
 		}
+		lunchRows := len(day.Lunch.Turns)
+		if len(day.Lunch.Unassigned) > 0 {
+			lunchRows++
+		}
+		if lunchRows > maxLunchRows {
+			maxLunchRows = lunchRows
+		}
 	}
 
 	output.MaxVisitingGroupsPerDay = maxVisitingGroups
+	output.MaxLunchRowsPerDay = maxLunchRows
 	return output
 }
@@ -6,27 +6,30 @@ import (
 	"github.com/fabiofenoglio/excelconv/config"
 )
 
-func AssignCompetenceDay(_ config.WorkflowContext, rows []InputRow) []Row {
+func AssignCompetenceDay(ctx config.WorkflowContext, rows []InputRow) []Row {
 	out := make([]Row, 0, len(rows))
 
 	for _, row := range rows {
 		out = append(out, Row{
 			InputRow:       row,
-			CompetenceDate: extractCompetenceDay(row),
+			CompetenceDate: extractCompetenceDay(row, ctx.Config.CompetenceDayCutoff(), ctx.Config.Location()),
 		})
 	}
 
 	return out
 }
 
-func extractCompetenceDay(row InputRow) time.Time {
+// extractCompetenceDay returns the day the activity belongs to: the activities starting before the cutoff hour
+// belong to the day before, ex. the end of a night event.
+func extractCompetenceDay(row InputRow, cutoffHour int, location *time.Location) time.Time {
 	start := row.StartTime
 
 	if start.IsZero() {
 		return row.Date
 	}
+	start = start.In(location)
 
-	if start.Hour() >= 6 {
+	if start.Hour() >= cutoffHour {
 		return time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, start.Location())
 	}
 
@@ -0,0 +1,112 @@
+package aggregator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func TestAssignCompetenceDay(t *testing.T) {
+	rome := config.TimeZone()
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		start      time.Time
+		cutoffHour *int
+		timeZone   *time.Location
+		expected   time.Time
+	}
+
+	hour := func(h int) *int {
+		return &h
+	}
+
+	testCases := []testCase{
+		// the night event starting before midnight stays on its day
+		{start: time.Date(2025, 3, 10, 23, 30, 0, 0, rome), expected: time.Date(2025, 3, 10, 12, 0, 0, 0, rome)},
+		// the part after midnight belongs to the day before
+		{start: time.Date(2025, 3, 11, 0, 30, 0, 0, rome), expected: time.Date(2025, 3, 10, 12, 0, 0, 0, rome)},
+		{start: time.Date(2025, 3, 11, 5, 59, 0, 0, rome), expected: time.Date(2025, 3, 10, 12, 0, 0, 0, rome)},
+		{start: time.Date(2025, 3, 11, 6, 0, 0, 0, rome), expected: time.Date(2025, 3, 11, 12, 0, 0, 0, rome)},
+		// on the first day of the year the day before is in the previous year
+		{start: time.Date(2026, 1, 1, 1, 0, 0, 0, rome), expected: time.Date(2025, 12, 31, 12, 0, 0, 0, rome)},
+		// custom cutoff
+		{start: time.Date(2025, 3, 11, 2, 30, 0, 0, rome), cutoffHour: hour(3), expected: time.Date(2025, 3, 10, 12, 0, 0, 0, rome)},
+		{start: time.Date(2025, 3, 11, 4, 0, 0, 0, rome), cutoffHour: hour(3), expected: time.Date(2025, 3, 11, 12, 0, 0, 0, rome)},
+		{start: time.Date(2025, 3, 11, 0, 30, 0, 0, rome), cutoffHour: hour(0), expected: time.Date(2025, 3, 11, 12, 0, 0, 0, rome)},
+		// the hour is read in the configured time zone
+		{start: time.Date(2025, 3, 11, 1, 0, 0, 0, newYork), timeZone: newYork, expected: time.Date(2025, 3, 10, 12, 0, 0, 0, newYork)},
+		{start: time.Date(2025, 3, 11, 1, 0, 0, 0, newYork), timeZone: rome, expected: time.Date(2025, 3, 11, 12, 0, 0, 0, rome)},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			ctx := config.WorkflowContext{
+				Config: config.WorkflowContextConfig{
+					TimeZone:                testCase.timeZone,
+					CompetenceDayCutoffHour: testCase.cutoffHour,
+				},
+			}
+			out := AssignCompetenceDay(ctx, []InputRow{{
+				ID:        1,
+				Date:      time.Date(testCase.start.Year(), testCase.start.Month(), testCase.start.Day(), 12, 0, 0, 0, testCase.start.Location()),
+				StartTime: testCase.start,
+				EndTime:   testCase.start.Add(time.Hour),
+			}})
+			assert.Len(t, out, 1)
+			assert.True(t, testCase.expected.Equal(out[0].CompetenceDate), "expected %s, got %s", testCase.expected, out[0].CompetenceDate)
+		})
+	}
+}
+
+func TestRelativeToDay(t *testing.T) {
+	rome := config.TimeZone()
+
+	type testCase struct {
+		time           time.Time
+		competenceDate time.Time
+		expected       TimeOfDay
+	}
+
+	testCases := []testCase{
+		{time.Date(2025, 3, 10, 21, 0, 0, 0, rome), time.Date(2025, 3, 10, 12, 0, 0, 0, rome), TimeOfDay{21, 0}},
+		{time.Date(2025, 3, 11, 0, 0, 0, 0, rome), time.Date(2025, 3, 10, 12, 0, 0, 0, rome), TimeOfDay{24, 0}},
+		{time.Date(2025, 3, 11, 0, 30, 0, 0, rome), time.Date(2025, 3, 10, 12, 0, 0, 0, rome), TimeOfDay{24, 30}},
+		// crossing the end of the year
+		{time.Date(2026, 1, 1, 1, 15, 0, 0, rome), time.Date(2025, 12, 31, 12, 0, 0, 0, rome), TimeOfDay{25, 15}},
+		// crossing the change to the daylight saving time
+		{time.Date(2025, 3, 30, 4, 0, 0, 0, rome), time.Date(2025, 3, 29, 12, 0, 0, 0, rome), TimeOfDay{28, 0}},
+		// times in another time zone are converted
+		{time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC), time.Date(2025, 3, 10, 12, 0, 0, 0, rome), TimeOfDay{24, 30}},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, testCase.expected, RelativeToDay(testCase.time, testCase.competenceDate, rome))
+		})
+	}
+}
+
+func TestExtractCommonTimespanAcrossMidnight(t *testing.T) {
+	rome := config.TimeZone()
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, rome)
+
+	rows := []Row{
+		{InputRow: InputRow{ID: 1, StartTime: time.Date(2025, 3, 10, 9, 30, 0, 0, rome), EndTime: time.Date(2025, 3, 10, 11, 0, 0, 0, rome)}, CompetenceDate: day},
+		{InputRow: InputRow{ID: 2, StartTime: time.Date(2025, 3, 10, 21, 0, 0, 0, rome), EndTime: time.Date(2025, 3, 11, 0, 30, 0, 0, rome)}, CompetenceDate: day},
+		{InputRow: InputRow{ID: 3, StartTime: time.Date(2025, 3, 11, 0, 30, 0, 0, rome), EndTime: time.Date(2025, 3, 11, 1, 45, 0, 0, rome)}, CompetenceDate: day},
+	}
+
+	out := extractCommonTimespan(rows, rome)
+	assert.Equal(t, TimeOfDay{9, 30}, out.Start)
+	assert.Equal(t, TimeOfDay{25, 45}, out.End)
+}
@@ -1,7 +1,6 @@
 package aggregator
 
 import (
-	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -14,7 +13,7 @@ const (
 	layoutDateOrderable = "2006-01-02"
 )
 
-func AggregateByCompetenceDay(_ config.WorkflowContext, rows []Row, anagraphicsRef *parser.OutputAnagraphics) []scheduleForSingleDay {
+func AggregateByCompetenceDay(ctx config.WorkflowContext, rows []Row, anagraphicsRef *parser.OutputAnagraphics) []scheduleForSingleDay {
 	// group by competence date, ordering each group by start time ASC, end time ASC
 
 	grouped := make([]*scheduleForSingleDay, 0)
@@ -160,37 +159,10 @@ func AggregateByCompetenceDay(_ config.WorkflowContext, rows []Row, anagraphicsR
 
 			return strings.Compare(group.VisitingGroups[i].VisitingGroupCode, group.VisitingGroups[j].VisitingGroupCode) < 0
 		})
-
-		// assign sequential numbers to school groups based on the order just sorted
-		schoolNumbers := make(map[string]int)
-		schoolNumberProgressive := 0
-		schoolGroupsNumbers := make(map[string]int)
-		schoolGroupsNumbersProgressive := make(map[string]int)
-		for i, visitingGroup := range group.VisitingGroups {
-
-			groupRef := anagraphicsRef.VisitingGroups[visitingGroup.VisitingGroupCode]
-			schoolRef := anagraphicsRef.Schools[groupRef.SchoolCode]
-			schoolClassRef := anagraphicsRef.SchoolClasses[groupRef.SchoolClassCode]
-
-			schoolNumAssigned, ok := schoolNumbers[schoolRef.Code]
-			if !ok {
-				schoolNumAssigned = schoolNumberProgressive + 1
-				schoolNumberProgressive++
-				schoolNumbers[schoolRef.Code] = schoolNumAssigned
-			}
-
-			schoolGroupNumberAssigned, ok := schoolGroupsNumbers[schoolClassRef.Code]
-			if !ok {
-				schoolGroupNumberAssigned = schoolGroupsNumbersProgressive[schoolRef.Code] + 1
-				schoolGroupsNumbersProgressive[schoolRef.Code] = schoolGroupNumberAssigned
-				schoolGroupsNumbers[schoolClassRef.Code] = schoolGroupNumberAssigned
-			}
-
-			group.VisitingGroups[i].SequentialCode = fmt.Sprintf("%09d-%09d", schoolNumAssigned, schoolGroupNumberAssigned)
-			group.VisitingGroups[i].DisplayCode = fmt.Sprintf("%d-%s", schoolNumAssigned, numToChars(uint(schoolGroupNumberAssigned)))
-		}
 	}
 
+	assignDisplayCodes(ctx, grouped, anagraphicsRef)
+
 	out := make([]scheduleForSingleDay, 0, len(grouped))
 	for _, e := range grouped {
 		out = append(out, *e)
@@ -1,7 +1,6 @@
 package aggregator
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/fabiofenoglio/excelconv/config"
@@ -10,12 +9,14 @@ import (
 )
 
 func AggregateByRooomGroupSlotInRoom(
-	_ config.WorkflowContext,
+	ctx config.WorkflowContext,
 	days []ScheduleForSingleDayWithRoomsAndGroupedActivities,
 	anagraphicsRef *parser.OutputAnagraphics,
 ) []ScheduleForSingleDayWithRoomsAndGroupSlots {
 
 	grouped := make([]*ScheduleForSingleDayWithRoomsAndGroupSlots, 0)
+	gridStep := ctx.Config.GridStepMinutes()
+	explain := ctx.Config.ExplainPlacement
 
 	for _, daySchedule := range days {
 		mapped := &ScheduleForSingleDayWithRoomsAndGroupSlots{
@@ -50,31 +51,50 @@ func AggregateByRooomGroupSlotInRoom(
 				})
 			}
 
-			for _, activity := range roomSchedule.GroupedActivities {
+			activities := withPreviousSlots(ctx.Config.PreviousLayout, roomSchedule)
+
+			for _, activity := range activities {
+
+				if activity.PreviousSlotIndex != nil {
+					// the slot of the previous layout is created if missing, so that the activity can stay there
+					for len(mappedForThisRoom.Slots) <= *activity.PreviousSlotIndex {
+						mappedForThisRoom.Slots = append(mappedForThisRoom.Slots, ScheduleForSingleDayAndRoomGroupSlot{
+							SlotIndex: len(mappedForThisRoom.Slots),
+						})
+					}
+				}
 
 				var numSlotsToSpan int
 				var fitsAt int
-				var fitComputationLog []string
+				var candidates []SlotCandidate
+				var placementNote string
 
-				if room.GroupActivities && len(mappedForThisRoom.Slots) > 1 && noOtherActivitiesInSlots(activity, roomSchedule) {
+				if room.GroupActivities && len(mappedForThisRoom.Slots) > 1 && noOtherActivitiesInSlots(activity, roomSchedule, gridStep) {
 					// take all the slots as they are all available
 					fitsAt = 0
 					numSlotsToSpan = len(mappedForThisRoom.Slots)
+					placementNote = "nessun'altra attività in aula nello stesso orario, occupa tutte le colonne"
 
 				} else {
 					var fits bool
+					activity.FitComputationLog = make([]string, 0)
 					// find which slot to fill
-					fitsAt, _, fits, fitComputationLog = findBestSlotForGroupedActivity(activity, mappedForThisRoom.Slots, roomSchedule)
+					fitsAt, _, fits, candidates = findBestSlotForGroupedActivity(activity, mappedForThisRoom.Slots, roomSchedule, gridStep, explain)
 					if !fits {
 						// will force to create a new slot
 						fitsAt = len(mappedForThisRoom.Slots)
+						placementNote = "non entra in nessuna colonna, aggiunta una nuova colonna"
 					}
 					numSlotsToSpan = len(activity.Rows)
 				}
 
 				activity.StartingSlotIndex = fitsAt
-				activity.FitComputationLog = fitComputationLog
 				activity.NumOccupiedSlots = numSlotsToSpan
+				if explain {
+					activity.PlacementCandidates = candidates
+					activity.PlacementNote = placementNote
+					activity.FitComputationLog = describePlacement(fitsAt, placementNote, candidates)
+				}
 
 				for cnt := 0; cnt < numSlotsToSpan; cnt++ {
 					effectiveIndex := fitsAt + cnt
@@ -111,119 +131,142 @@ func findBestSlotForGroupedActivity(
 	act GroupedActivity,
 	slots []ScheduleForSingleDayAndRoomGroupSlot,
 	parent ScheduleForSingleDayAndRoomWithGroupedActivities,
-) (int, int, bool, []string) {
+	gridStep int,
+	explain bool,
+) (int, int, bool, []SlotCandidate) {
 	if len(slots) == 0 {
 		return -1, 0, false, nil
 	}
 
 	scoreMap := make(map[int]int)
-	logMap := make([]string, 0)
+	candidates := make([]SlotCandidate, 0)
 	numSlots := len(slots)
 	numSlotsForThisAct := len(act.Rows)
 
 	scoreSettings := database.GetEffectiveSlotPlacementPreferencesForRoom(parent.RoomCode)
 
-	doLog := false // should come from input
-
 	for slotIndex, slot := range slots {
-		if !groupedActivityFitsInTime(act, slotIndex, slots, 0) {
+		if !groupedActivityFitsInTime(act, slotIndex, slots, 0, gridStep) {
 			// no way the activity fits, not considering this slot.
+			if explain {
+				candidates = append(candidates, SlotCandidate{SlotIndex: slotIndex})
+			}
 			continue
 		}
 
 		score := 0
+		candidate := SlotCandidate{SlotIndex: slotIndex, Fits: true}
 
-		apply := func(target *int, amount int, reason string) {
+		apply := func(target *int, amount int, factor string) {
 			if amount == 0 {
 				return
 			}
-			newValue := *target + amount
-			if doLog {
-				logMap = append(logMap,
-					fmt.Sprintf("[%d] %v (%v -> %v) - %s", slotIndex, amount, *target, newValue, reason))
+			if explain {
+				candidate.Factors = append(candidate.Factors, SlotScoreFactor{Factor: factor, Points: amount})
 			}
-			*target = newValue
+			*target += amount
 		}
 
-		// prefer slots where it fits with some clearance before and after
-		if groupedActivityFitsInTime(act, slotIndex, slots, time.Minute*1) {
-			apply(&score, scoreSettings.PointsForFittingWithSmallClearance, "has small clearance")
+		// prefer slots where it fits with some clearance before and after: at least a free row of the grid,
+		// or better two of them
+		if groupedActivityFitsInTime(act, slotIndex, slots, time.Minute*1, gridStep) {
+			apply(&score, scoreSettings.PointsForFittingWithSmallClearance, FactorFittingWithSmallClearance)
 		}
-		if groupedActivityFitsInTime(act, slotIndex, slots, time.Minute*30) {
-			apply(&score, scoreSettings.PointsForFittingWithLotClearance, "has lot of clearance")
+		if groupedActivityFitsInTime(act, slotIndex, slots, time.Minute*time.Duration(2*gridStep), gridStep) {
+			apply(&score, scoreSettings.PointsForFittingWithLotClearance, FactorFittingWithLotClearance)
 		}
 
 		// prefer slots without activities on the right
 		if slotIndex < numSlots-numSlotsForThisAct {
-			if !groupedActivityFitsInTime(act, slotIndex+1, slots, 0) {
-				apply(&score, -scoreSettings.PenaltyForActivityImmediatelyToTheRight, "has activity on the right")
+			if !groupedActivityFitsInTime(act, slotIndex+1, slots, 0, gridStep) {
+				apply(&score, -scoreSettings.PenaltyForActivityImmediatelyToTheRight, FactorActivityImmediatelyToTheRight)
 			}
 		}
 		if slotIndex < numSlots-numSlotsForThisAct-1 {
-			if !groupedActivityFitsInTime(act, slotIndex+2, slots, 0) {
-				apply(&score, -scoreSettings.PenaltyForActivity2ndToTheRight, "has activity on the right (2nd)")
+			if !groupedActivityFitsInTime(act, slotIndex+2, slots, 0, gridStep) {
+				apply(&score, -scoreSettings.PenaltyForActivity2ndToTheRight, FactorActivity2ndToTheRight)
 			}
 		}
 
 		// prefer slots without activities on the left
 		if slotIndex > 0 {
-			if !groupedActivityFitsInTime(act, slotIndex-1, slots, 0) {
-				apply(&score, -scoreSettings.PenaltyForActivityImmediatelyToTheLeft, "has activity on the left")
+			if !groupedActivityFitsInTime(act, slotIndex-1, slots, 0, gridStep) {
+				apply(&score, -scoreSettings.PenaltyForActivityImmediatelyToTheLeft, FactorActivityImmediatelyToTheLeft)
 			}
 		}
 
 		// prefer slots where the same operator was
 		if len(act.OperatorCodes()) > 0 && sameOperatorHasOtherGroupedActivitiesInSlot(act.OperatorCodes(), slot) {
-			apply(&score, scoreSettings.PointsForOperatorHasOtherActivitiesInSlot, "operator has other activities in slot")
+			apply(&score, scoreSettings.PointsForOperatorHasOtherActivitiesInSlot, FactorOperatorHasOtherActivitiesInSlot)
 		}
 
 		// prefer slots where the same group was
 		if len(act.BookingCodes()) > 0 && sameGroupHasOtherGroupedActivitiesInSlot(act.BookingCodes(), slot) {
-			apply(&score, scoreSettings.PointsForGroupHasOtherActivitiesInSlot, "group has other activities in slot")
+			apply(&score, scoreSettings.PointsForGroupHasOtherActivitiesInSlot, FactorGroupHasOtherActivitiesInSlot)
 		}
 
 		// prefer the more empty slots
 		diff := -scoreSettings.PenaltyForEachOtherActivityInSlot * len(slot.GroupedActivities)
-		apply(&score, diff, "other activities in the slot")
+		apply(&score, diff, FactorEachOtherActivityInSlot)
+
+		// prefer the slot of the previous layout
+		if act.PreviousSlotIndex != nil && *act.PreviousSlotIndex == slotIndex {
+			apply(&score, scoreSettings.PointsForPreviousSlot, FactorPreviousSlot)
+		}
 
 		// prefer slots on the left when everything else is the same
 		diff = -slotIndex
-		apply(&score, diff, "prefer leftmost positions")
+		apply(&score, diff, FactorLeftmostPosition)
 
 		scoreMap[slotIndex] = score
+		if explain {
+			candidate.Score = score
+			candidates = append(candidates, candidate)
+		}
 	}
 
 	if len(scoreMap) == 0 {
 		// no valid slots
-		return -1, 0, false, nil
+		return -1, 0, false, candidates
 	}
 
-	// pick the highest score
+	// pick the highest score, the lowest slot index on ties
 	highestIndex, highestScore := -1, 0
-	for index, score := range scoreMap {
+	for index := 0; index < numSlots; index++ {
+		score, ok := scoreMap[index]
+		if !ok {
+			continue
+		}
 		if highestIndex < 0 || score > highestScore {
 			highestScore = score
 			highestIndex = index
 		}
 	}
 
-	return highestIndex, highestScore, true, logMap
+	for i := range candidates {
+		candidates[i].Chosen = candidates[i].SlotIndex == highestIndex
+	}
+
+	return highestIndex, highestScore, true, candidates
 }
 
 func noOtherActivitiesInSlots(
 	act GroupedActivity,
-	parent ScheduleForSingleDayAndRoomWithGroupedActivities) bool {
+	parent ScheduleForSingleDayAndRoomWithGroupedActivities,
+	gridStep int) bool {
 
 	if act.StartTime.IsZero() || act.EndTime.IsZero() {
 		return false
 	}
+	actStart, actEnd := SnapToGrid(act.StartTime, act.EndTime, gridStep)
 
 	for _, groupedActivity := range parent.GroupedActivities {
 		if groupedActivity.ID == act.ID {
 			continue
 		}
 
-		if act.StartTime.Before(groupedActivity.EndTime) && groupedActivity.StartTime.Before(act.EndTime) {
+		otherStart, otherEnd := SnapToGrid(groupedActivity.StartTime, groupedActivity.EndTime, gridStep)
+		if actStart.Before(otherEnd) && otherStart.Before(actEnd) {
 			return false
 		}
 	}
@@ -259,12 +302,15 @@ func sameGroupHasOtherGroupedActivitiesInSlot(codes []string, slot ScheduleForSi
 	return false
 }
 
-func groupedActivityFitsInTime(act GroupedActivity, slotIndex int, slots []ScheduleForSingleDayAndRoomGroupSlot, clearance time.Duration) bool {
+// groupedActivityFitsInTime checks the overlaps on the rows of the time grid the activities are drawn on,
+// so that two activities sharing a row are never placed in the same slot.
+func groupedActivityFitsInTime(act GroupedActivity, slotIndex int, slots []ScheduleForSingleDayAndRoomGroupSlot, clearance time.Duration, gridStep int) bool {
 	if act.StartTime.IsZero() || act.EndTime.IsZero() {
 		return true
 	}
-	actStartWithClearance := act.StartTime.Add(-clearance)
-	actEndWithClearance := act.EndTime.Add(clearance)
+	actStart, actEnd := SnapToGrid(act.StartTime, act.EndTime, gridStep)
+	actStartWithClearance := actStart.Add(-clearance)
+	actEndWithClearance := actEnd.Add(clearance)
 
 	numToCheck := len(act.Rows)
 	i := slotIndex
@@ -276,7 +322,8 @@ func groupedActivityFitsInTime(act GroupedActivity, slotIndex int, slots []Sched
 		}
 
 		for _, otherAct := range slots[i].GroupedActivities {
-			if actStartWithClearance.Before(otherAct.EndTime) && otherAct.StartTime.Before(actEndWithClearance) {
+			otherStart, otherEnd := SnapToGrid(otherAct.StartTime, otherAct.EndTime, gridStep)
+			if actStartWithClearance.Before(otherEnd) && otherStart.Before(actEndWithClearance) {
 				return false
 			}
 		}
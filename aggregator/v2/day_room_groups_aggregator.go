@@ -1,9 +1,11 @@
 package aggregator
 
 import (
+	"time"
+
 	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
 	"github.com/fabiofenoglio/excelconv/parser/v2"
-	"time"
 )
 
 const (
@@ -57,7 +59,7 @@ func AggregateByRooomGroupsOnSameActivity(
 			}
 
 			for _, groupedActivityEntry := range roomScheduleEntry.GroupedActivities {
-				if groupedActivityEntry.AnyConfirmed {
+				if groupedActivityEntry.AnyConfirmed && !groupedActivityEntry.StartTime.IsZero() {
 					dayEntry.NumeroGruppiAttivitaConfermateMarkers[groupedActivityEntry.StartTime]++
 					dayEntry.NumeroGruppiAttivitaConfermateMarkers[groupedActivityEntry.EndTime]--
 				}
@@ -84,12 +86,11 @@ func aggregateScheduleForSingleDayAndRoomWithGroupedActivities(
 	}
 
 	indexMap := make(map[string]int)
+	room := anagraphicsRef.Rooms[input.RoomCode]
 
 	for _, row := range input.Rows {
-		room := anagraphicsRef.Rooms[input.RoomCode]
-
-		// we group if they have the same start time, end time (and activity code ???)
-		if !room.GroupActivities || row.StartTime.IsZero() || row.EndTime.IsZero() || row.ActivityCode == "" {
+		if !room.GroupActivities || room.GroupingPolicy == database.GroupingNone ||
+			row.StartTime.IsZero() || row.EndTime.IsZero() || row.ActivityCode == "" {
 			// cannot be grouped
 			mapped.GroupedActivities = append(mapped.GroupedActivities, GroupedActivity{
 				StartTime:    row.StartTime,
@@ -100,10 +101,18 @@ func aggregateScheduleForSingleDayAndRoomWithGroupedActivities(
 			continue
 		}
 
-		hash := row.StartTime.Format(layoutDateHourMinsOrderable) + "/" +
-			row.EndTime.Format(layoutDateHourMinsOrderable)
+		var mappedAt int
+		var already bool
 
-		mappedAt, already := indexMap[hash]
+		if room.GroupingPolicy == database.GroupingOverlap {
+			mappedAt, already = findOverlappingGroup(mapped.GroupedActivities, row, room.GroupingTolerance)
+		} else {
+			hash := groupingHash(room.GroupingPolicy, row)
+			mappedAt, already = indexMap[hash]
+			if !already {
+				indexMap[hash] = len(mapped.GroupedActivities)
+			}
+		}
 
 		if !already {
 			// nothing mapped under this key yet
@@ -113,9 +122,15 @@ func aggregateScheduleForSingleDayAndRoomWithGroupedActivities(
 				Rows:      []OutputRow{row},
 			})
 			mappedAt = len(mapped.GroupedActivities) - 1
-			indexMap[hash] = mappedAt
 		} else {
-			mapped.GroupedActivities[mappedAt].Rows = append(mapped.GroupedActivities[mappedAt].Rows, row)
+			group := &mapped.GroupedActivities[mappedAt]
+			group.Rows = append(group.Rows, row)
+			if row.StartTime.Before(group.StartTime) {
+				group.StartTime = row.StartTime
+			}
+			if row.EndTime.After(group.EndTime) {
+				group.EndTime = row.EndTime
+			}
 		}
 
 		if row.Confirmed != nil && *row.Confirmed {
@@ -125,3 +140,38 @@ func aggregateScheduleForSingleDayAndRoomWithGroupedActivities(
 
 	return mapped
 }
+
+// groupingHash is the key of the group of the row, the activity is part of it only for the same_time_activity policy.
+func groupingHash(policy database.GroupingPolicy, row OutputRow) string {
+	hash := row.StartTime.Format(layoutDateHourMinsOrderable) + "/" +
+		row.EndTime.Format(layoutDateHourMinsOrderable)
+	if policy == database.GroupingSameTimeAndActivity {
+		hash += "/" + row.ActivityCode
+	}
+	return hash
+}
+
+// findOverlappingGroup finds the group whose first row starts and ends at most the tolerance apart from the row.
+// The first row is the reference so that the group cannot drift beyond the tolerance as rows join it.
+func findOverlappingGroup(groups []GroupedActivity, row OutputRow, tolerance time.Duration) (int, bool) {
+	for i, group := range groups {
+		if len(group.Rows) == 0 {
+			continue
+		}
+		first := group.Rows[0]
+		if first.ActivityCode == "" || first.StartTime.IsZero() || first.EndTime.IsZero() {
+			continue
+		}
+		if absDuration(row.StartTime.Sub(first.StartTime)) <= tolerance && absDuration(row.EndTime.Sub(first.EndTime)) <= tolerance {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,97 @@
+package aggregator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestGroupingPolicies(t *testing.T) {
+	row := func(id int, start, end time.Time, activity string) OutputRow {
+		return OutputRow{ID: id, StartTime: start, EndTime: end, RoomCode: "aula", ActivityCode: activity}
+	}
+
+	rows := []OutputRow{
+		row(1, at(9, 0), at(10, 0), "stelle"),
+		row(2, at(9, 0), at(10, 0), "pianeti"),
+		row(3, at(9, 0), at(10, 0), "stelle"),
+		// ends a few minutes later
+		row(4, at(9, 0), at(10, 5), "stelle"),
+		// starts a few minutes later
+		row(5, at(9, 10), at(10, 0), "pianeti"),
+		row(6, at(11, 0), at(12, 0), "stelle"),
+		// never grouped without an activity
+		row(7, at(11, 0), at(12, 0), ""),
+	}
+
+	// each row ends within the tolerance of the previous one, but not of the first one
+	chained := []OutputRow{
+		row(1, at(9, 0), at(10, 0), "stelle"),
+		row(2, at(9, 0), at(10, 10), "stelle"),
+		row(3, at(9, 0), at(10, 20), "stelle"),
+		row(4, at(9, 0), at(10, 30), "stelle"),
+	}
+
+	type testCase struct {
+		rows            []OutputRow
+		groupActivities bool
+		policy          database.GroupingPolicy
+		tolerance       time.Duration
+		expected        [][]int
+		expectedEnds    []time.Time
+	}
+
+	testCases := []testCase{
+		{nil, false, "", 0, [][]int{{1}, {2}, {3}, {4}, {5}, {6}, {7}}, nil},
+		{nil, true, database.GroupingNone, 0, [][]int{{1}, {2}, {3}, {4}, {5}, {6}, {7}}, nil},
+		// same time when not set
+		{nil, true, "", 0, [][]int{{1, 2, 3}, {4}, {5}, {6}, {7}}, nil},
+		{nil, true, database.GroupingSameTime, 0, [][]int{{1, 2, 3}, {4}, {5}, {6}, {7}}, nil},
+		{nil, true, database.GroupingSameTimeAndActivity, 0, [][]int{{1, 3}, {2}, {4}, {5}, {6}, {7}}, nil},
+		{nil, true, database.GroupingOverlap, 5 * time.Minute, [][]int{{1, 2, 3, 4}, {5}, {6}, {7}},
+			[]time.Time{at(10, 5), at(10, 0), at(12, 0), at(12, 0)}},
+		{nil, true, database.GroupingOverlap, 10 * time.Minute, [][]int{{1, 2, 3, 4, 5}, {6}, {7}},
+			[]time.Time{at(10, 5), at(12, 0), at(12, 0)}},
+		{chained, true, database.GroupingOverlap, 10 * time.Minute, [][]int{{1, 2}, {3, 4}},
+			[]time.Time{at(10, 10), at(10, 30)}},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			anagraphics := &parser.OutputAnagraphics{Rooms: map[string]parser.Room{"aula": {
+				Code:              "aula",
+				GroupActivities:   testCase.groupActivities,
+				GroupingPolicy:    testCase.policy,
+				GroupingTolerance: testCase.tolerance,
+			}}}
+
+			input := rows
+			if testCase.rows != nil {
+				input = testCase.rows
+			}
+			actual := aggregateScheduleForSingleDayAndRoomWithGroupedActivities(
+				ScheduleForSingleDayAndRoom{RoomCode: "aula", Rows: input}, anagraphics)
+
+			ids := make([][]int, 0, len(actual.GroupedActivities))
+			ends := make([]time.Time, 0, len(actual.GroupedActivities))
+			for _, group := range actual.GroupedActivities {
+				groupIDs := make([]int, 0, len(group.Rows))
+				for _, r := range group.Rows {
+					groupIDs = append(groupIDs, r.ID)
+				}
+				ids = append(ids, groupIDs)
+				ends = append(ends, group.EndTime)
+			}
+			assert.Equal(t, testCase.expected, ids)
+			if testCase.expectedEnds != nil {
+				assert.Equal(t, testCase.expectedEnds, ends)
+			}
+		})
+	}
+}
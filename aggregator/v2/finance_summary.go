@@ -0,0 +1,216 @@
+package aggregator
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+	"github.com/pkg/errors"
+)
+
+type FinanceSummary struct {
+	Groups  []FinanceEntry
+	Schools []FinanceEntry
+	Days    []FinanceEntry
+	Total   FinanceEntry
+	// UnpricedActivityTypes are the codes of the activity types without a price
+	UnpricedActivityTypes []string
+}
+
+func (s FinanceSummary) IsEmpty() bool {
+	return len(s.Groups) == 0
+}
+
+// FinanceEntry holds the amounts of a group, a school, a day or of the whole period.
+type FinanceEntry struct {
+	// Code is the code of the group or of the school, empty for days and for the total
+	Code string
+	// Day is set only for the days
+	Day       time.Time
+	NumPaying int
+	Expected  parser.Money
+	// Received are the advances already paid
+	Received parser.Money
+	// PendingAdvances are the advances declared but not paid yet, or with an unknown status
+	PendingAdvances parser.Money
+	Outstanding     parser.Money
+}
+
+func (e *FinanceEntry) add(other FinanceEntry) {
+	e.NumPaying += other.NumPaying
+	e.Expected += other.Expected
+	e.Received += other.Received
+	e.PendingAdvances += other.PendingAdvances
+	e.Outstanding = e.Expected - e.Received
+}
+
+type priceList struct {
+	byActivityType map[string]parser.Money
+	defaultPrice   parser.Money
+}
+
+func newPriceList(cfg config.PriceListConfig) (priceList, error) {
+	out := priceList{
+		byActivityType: make(map[string]parser.Money),
+		defaultPrice:   parser.MoneyFromEuros(cfg.Default),
+	}
+	if cfg.Default < 0 {
+		return priceList{}, errors.Errorf("il prezzo di default non può essere negativo: %v", cfg.Default)
+	}
+	for name, price := range cfg.ActivityTypes {
+		if price < 0 {
+			return priceList{}, errors.Errorf("il prezzo della tipologia '%s' non può essere negativo: %v", name, price)
+		}
+		out.byActivityType[strings.ToLower(strings.TrimSpace(name))] = parser.MoneyFromEuros(price)
+	}
+	return out, nil
+}
+
+func (l priceList) priceOf(activityType parser.ActivityType) (parser.Money, bool) {
+	if price, ok := l.byActivityType[strings.ToLower(strings.TrimSpace(activityType.Name))]; ok {
+		return price, true
+	}
+	if price, ok := l.byActivityType[strings.ToLower(activityType.Code)]; ok {
+		return price, true
+	}
+	return l.defaultPrice, l.defaultPrice > 0
+}
+
+// BuildFinanceSummary computes the expected revenue, the received advances and the outstanding balance
+// per group, per school and per day. Each activity is priced by its type for every paying participant of the group,
+// once per booking and day even when it is split over several rows.
+// The advance of a group is booked on the first day of the group.
+func BuildFinanceSummary(
+	ctx config.WorkflowContext,
+	rows []Row,
+	anagraphicsRef *parser.OutputAnagraphics,
+) (FinanceSummary, error) {
+
+	prices, err := newPriceList(ctx.Config.PriceList)
+	if err != nil {
+		return FinanceSummary{}, errors.Wrap(err, "listino prezzi non valido")
+	}
+
+	sortedRows := make([]Row, len(rows))
+	copy(sortedRows, rows)
+	sort.Slice(sortedRows, func(i, j int) bool {
+		if !sortedRows[i].CompetenceDate.Equal(sortedRows[j].CompetenceDate) {
+			return sortedRows[i].CompetenceDate.Before(sortedRows[j].CompetenceDate)
+		}
+		return sortedRows[i].InputRow.ID < sortedRows[j].InputRow.ID
+	})
+
+	groups := make(map[string]*FinanceEntry)
+	groupCodes := make([]string, 0)
+	groupFirstDay := make(map[string]time.Time)
+	advances := make(map[string]parser.PaymentStatus)
+	days := make(map[string]*FinanceEntry)
+	unpriced := make(map[string]bool)
+	priced := make(map[string]bool)
+
+	dayEntry := func(day time.Time) *FinanceEntry {
+		key := day.Format("2006-01-02")
+		if _, ok := days[key]; !ok {
+			days[key] = &FinanceEntry{Day: day}
+		}
+		return days[key]
+	}
+
+	for _, row := range sortedRows {
+		input := row.InputRow
+		if input.IsPlaceholderNumeroAttivita || input.VisitingGroupCode == "" {
+			continue
+		}
+		groupRef := anagraphicsRef.VisitingGroups[input.VisitingGroupCode]
+
+		group, ok := groups[input.VisitingGroupCode]
+		if !ok {
+			group = &FinanceEntry{Code: input.VisitingGroupCode, NumPaying: groupRef.Composition.NumPaying}
+			groups[input.VisitingGroupCode] = group
+			groupCodes = append(groupCodes, input.VisitingGroupCode)
+			groupFirstDay[input.VisitingGroupCode] = row.CompetenceDate
+		}
+
+		// the same advance is usually repeated on every row of the booking, the biggest one is kept
+		if current, ok := advances[input.VisitingGroupCode]; !ok || input.Payment.AdvanceAmount > current.AdvanceAmount {
+			advances[input.VisitingGroupCode] = input.Payment
+		}
+
+		activityKey := strings.Join([]string{input.VisitingGroupCode, strings.ToLower(strings.TrimSpace(input.BookingCode)),
+			input.ActivityCode, row.CompetenceDate.Format("2006-01-02")}, "|")
+		if priced[activityKey] {
+			continue
+		}
+		priced[activityKey] = true
+
+		activityType := anagraphicsRef.ActivityTypes[anagraphicsRef.Activities[input.ActivityCode].TypeCode]
+		price, hasPrice := prices.priceOf(activityType)
+		if !hasPrice {
+			if activityType.Code != "" && !unpriced[activityType.Code] {
+				unpriced[activityType.Code] = true
+				ctx.Logger.Debugf("no price for activity type %s", activityType.Name)
+			}
+			continue
+		}
+
+		expected := price * parser.Money(groupRef.Composition.NumPaying)
+		group.Expected += expected
+		dayEntry(row.CompetenceDate).Expected += expected
+	}
+
+	out := FinanceSummary{}
+	schools := make(map[string]*FinanceEntry)
+
+	sort.Strings(groupCodes)
+	for _, code := range groupCodes {
+		group := groups[code]
+
+		advance := advances[code]
+		switch advance.AdvanceState {
+		case parser.PaymentStatePaid:
+			group.Received = advance.AdvanceAmount
+		case parser.PaymentStateNotDue:
+		default:
+			group.PendingAdvances = advance.AdvanceAmount
+		}
+		group.Outstanding = group.Expected - group.Received
+
+		day := dayEntry(groupFirstDay[code])
+		day.NumPaying += group.NumPaying
+		day.Received += group.Received
+		day.PendingAdvances += group.PendingAdvances
+
+		schoolCode := anagraphicsRef.VisitingGroups[code].SchoolCode
+		if _, ok := schools[schoolCode]; !ok {
+			schools[schoolCode] = &FinanceEntry{Code: schoolCode}
+		}
+		schools[schoolCode].add(*group)
+		out.Total.add(*group)
+
+		out.Groups = append(out.Groups, *group)
+	}
+
+	for _, school := range schools {
+		out.Schools = append(out.Schools, *school)
+	}
+	sort.Slice(out.Schools, func(i, j int) bool {
+		return out.Schools[i].Code < out.Schools[j].Code
+	})
+
+	for _, day := range days {
+		day.Outstanding = day.Expected - day.Received
+		out.Days = append(out.Days, *day)
+	}
+	sort.Slice(out.Days, func(i, j int) bool {
+		return out.Days[i].Day.Before(out.Days[j].Day)
+	})
+
+	for code := range unpriced {
+		out.UnpricedActivityTypes = append(out.UnpricedActivityTypes, code)
+	}
+	sort.Strings(out.UnpricedActivityTypes)
+
+	return out, nil
+}
@@ -0,0 +1,114 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestBuildFinanceSummary(t *testing.T) {
+	day1 := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+
+	row := func(day time.Time, group, activity string, payment parser.PaymentStatus) Row {
+		return Row{
+			InputRow: InputRow{
+				VisitingGroupCode: group,
+				ActivityCode:      activity,
+				Payment:           payment,
+			},
+			CompetenceDate: day,
+		}
+	}
+
+	anagraphics := &parser.OutputAnagraphics{
+		VisitingGroups: map[string]parser.VisitingGroup{
+			"a": {Code: "a", SchoolCode: "s1", Composition: parser.GroupComposition{NumPaying: 20, NumFree: 2, NumAccompanying: 2}},
+			"b": {Code: "b", SchoolCode: "s1", Composition: parser.GroupComposition{NumPaying: 10}},
+			"c": {Code: "c", SchoolCode: "s2", Composition: parser.GroupComposition{NumPaying: 5}},
+		},
+		Activities: map[string]parser.Activity{
+			"visita": {Code: "visita", TypeCode: "visita"},
+			"lab":    {Code: "lab", TypeCode: "laboratorio"},
+			"serata": {Code: "serata", TypeCode: "evento"},
+		},
+		ActivityTypes: map[string]parser.ActivityType{
+			"visita":      {Code: "visita", Name: "Visita"},
+			"laboratorio": {Code: "laboratorio", Name: "Laboratorio"},
+			"evento":      {Code: "evento", Name: "Evento"},
+		},
+	}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+		Config: config.WorkflowContextConfig{
+			PriceList: config.PriceListConfig{
+				ActivityTypes: map[string]float64{"visita": 8.5, "LABORATORIO": 10},
+			},
+		},
+	}
+
+	paid := parser.NewPaymentStatus("100", "pagato")
+	pending := parser.NewPaymentStatus("50", "in attesa")
+
+	rows := []Row{
+		row(day1, "a", "visita", paid),
+		// an activity split over several rows is priced once
+		row(day1, "a", "visita", paid),
+		row(day1, "a", "lab", paid),
+		row(day2, "b", "visita", pending),
+		row(day2, "c", "serata", parser.NewPaymentStatus("-", "")),
+		{InputRow: InputRow{IsPlaceholderNumeroAttivita: true}, CompetenceDate: day1},
+	}
+
+	summary, err := BuildFinanceSummary(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+
+	if assert.Len(t, summary.Groups, 3) {
+		a := summary.Groups[0]
+		assert.Equal(t, "a", a.Code)
+		assert.Equal(t, 20, a.NumPaying)
+		assert.Equal(t, parser.Money(37000), a.Expected)
+		assert.Equal(t, parser.Money(10000), a.Received)
+		assert.Equal(t, parser.Money(27000), a.Outstanding)
+
+		b := summary.Groups[1]
+		assert.Equal(t, parser.Money(8500), b.Expected)
+		assert.Equal(t, parser.Money(0), b.Received)
+		assert.Equal(t, parser.Money(5000), b.PendingAdvances)
+
+		c := summary.Groups[2]
+		assert.Equal(t, parser.Money(0), c.Expected)
+		assert.Equal(t, parser.Money(0), c.PendingAdvances)
+	}
+
+	if assert.Len(t, summary.Schools, 2) {
+		assert.Equal(t, "s1", summary.Schools[0].Code)
+		assert.Equal(t, 30, summary.Schools[0].NumPaying)
+		assert.Equal(t, parser.Money(45500), summary.Schools[0].Expected)
+		assert.Equal(t, parser.Money(35500), summary.Schools[0].Outstanding)
+	}
+
+	if assert.Len(t, summary.Days, 2) {
+		assert.Equal(t, day1, summary.Days[0].Day)
+		assert.Equal(t, parser.Money(37000), summary.Days[0].Expected)
+		assert.Equal(t, parser.Money(10000), summary.Days[0].Received)
+		assert.Equal(t, 15, summary.Days[1].NumPaying)
+		assert.Equal(t, parser.Money(5000), summary.Days[1].PendingAdvances)
+	}
+
+	assert.Equal(t, parser.Money(45500), summary.Total.Expected)
+	assert.Equal(t, parser.Money(35500), summary.Total.Outstanding)
+	assert.Equal(t, []string{"evento"}, summary.UnpricedActivityTypes)
+
+	ctx.Config.PriceList.Default = -1
+	_, err = BuildFinanceSummary(ctx, rows, anagraphics)
+	assert.Error(t, err)
+}
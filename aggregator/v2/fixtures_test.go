@@ -0,0 +1,29 @@
+package aggregator
+
+import (
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+// testDay is the day of the test fixtures
+var testDay = time.Date(2025, 3, 10, 0, 0, 0, 0, config.TimeZone())
+
+// at returns the given time of testDay
+func at(hour, minute int) time.Time {
+	return testDay.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+// testActivity returns an activity of the room "aula" with a row for each visiting group, booked with the code
+// of the group. Without groups the activity has a single row with no group.
+func testActivity(id int, start, end time.Time, operator string, groups ...string) GroupedActivity {
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	act := GroupedActivity{ID: id, StartTime: start, EndTime: end}
+	for i, group := range groups {
+		act.Rows = append(act.Rows, OutputRow{ID: id*10 + i, StartTime: start, EndTime: end, RoomCode: "aula",
+			ActivityCode: "lab", OperatorCode: operator, VisitingGroupCode: group, BookingCode: group})
+	}
+	return act
+}
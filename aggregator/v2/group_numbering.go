@@ -0,0 +1,185 @@
+package aggregator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+// groupNumbering holds the codes assigned in a scope, so that a group seen again in the same scope keeps its code.
+type groupNumbering struct {
+	schoolNumbers          map[string]int
+	schoolClassNumbers     map[string]int
+	schoolClassProgressive map[string]int
+	schoolAbbreviations    map[string]string
+	usedAbbreviations      map[string]string
+	bookingCodes           map[string]string
+	usedBookingCodes       map[string]int
+}
+
+func newGroupNumbering() *groupNumbering {
+	return &groupNumbering{
+		schoolNumbers:          make(map[string]int),
+		schoolClassNumbers:     make(map[string]int),
+		schoolClassProgressive: make(map[string]int),
+		schoolAbbreviations:    make(map[string]string),
+		usedAbbreviations:      make(map[string]string),
+		bookingCodes:           make(map[string]string),
+		usedBookingCodes:       make(map[string]int),
+	}
+}
+
+func groupNumberingScopeKey(scope config.GroupNumberingScope, day scheduleForSingleDay) string {
+	switch scope {
+	case config.GroupNumberingPerPeriod:
+		return ""
+	case config.GroupNumberingPerWeek:
+		year, week := day.Day.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	default:
+		return day.Day.Format(layoutDateOrderable)
+	}
+}
+
+// assignDisplayCodes numbers the schools and their classes in the order the groups are met, day by day.
+// The numbering starts again at each day, week or for the whole period depending on the configured scope.
+func assignDisplayCodes(ctx config.WorkflowContext, days []*scheduleForSingleDay, anagraphicsRef *parser.OutputAnagraphics) {
+	scopes := make(map[string]*groupNumbering)
+
+	for _, day := range days {
+		scopeKey := groupNumberingScopeKey(ctx.Config.GroupNumbering.Scope, *day)
+		numbering, ok := scopes[scopeKey]
+		if !ok {
+			numbering = newGroupNumbering()
+			scopes[scopeKey] = numbering
+		}
+
+		bookingCodes := make(map[string]string)
+		for _, row := range day.Rows {
+			code := strings.TrimSpace(row.InputRow.BookingCode)
+			if code == "" || row.InputRow.VisitingGroupCode == "" {
+				continue
+			}
+			if already, ok := bookingCodes[row.InputRow.VisitingGroupCode]; !ok || code < already {
+				bookingCodes[row.InputRow.VisitingGroupCode] = code
+			}
+		}
+
+		for i, visitingGroup := range day.VisitingGroups {
+			groupRef := anagraphicsRef.VisitingGroups[visitingGroup.VisitingGroupCode]
+			schoolRef := anagraphicsRef.Schools[groupRef.SchoolCode]
+			schoolClassRef := anagraphicsRef.SchoolClasses[groupRef.SchoolClassCode]
+
+			schoolNumAssigned, schoolGroupNumberAssigned := numbering.sequentialNumbers(schoolRef.Code, schoolClassRef.Code)
+			day.VisitingGroups[i].SequentialCode = fmt.Sprintf("%09d-%09d", schoolNumAssigned, schoolGroupNumberAssigned)
+
+			displayCode := fmt.Sprintf("%d-%s", schoolNumAssigned, numToChars(uint(schoolGroupNumberAssigned)))
+			switch ctx.Config.GroupNumbering.Scheme {
+			case config.GroupNumberingBookingCode:
+				if bookingCode := numbering.bookingCode(visitingGroup.VisitingGroupCode, bookingCodes[visitingGroup.VisitingGroupCode]); bookingCode != "" {
+					displayCode = bookingCode
+				}
+			case config.GroupNumberingSchoolLetter:
+				displayCode = numbering.schoolAbbreviation(schoolRef, schoolNumAssigned) + "-" + numToChars(uint(schoolGroupNumberAssigned))
+			}
+			day.VisitingGroups[i].DisplayCode = displayCode
+		}
+	}
+}
+
+func (n *groupNumbering) sequentialNumbers(schoolCode string, schoolClassCode string) (int, int) {
+	schoolNumAssigned, ok := n.schoolNumbers[schoolCode]
+	if !ok {
+		schoolNumAssigned = len(n.schoolNumbers) + 1
+		n.schoolNumbers[schoolCode] = schoolNumAssigned
+	}
+
+	schoolGroupNumberAssigned, ok := n.schoolClassNumbers[schoolClassCode]
+	if !ok {
+		schoolGroupNumberAssigned = n.schoolClassProgressive[schoolCode] + 1
+		n.schoolClassProgressive[schoolCode] = schoolGroupNumberAssigned
+		n.schoolClassNumbers[schoolClassCode] = schoolGroupNumberAssigned
+	}
+
+	return schoolNumAssigned, schoolGroupNumberAssigned
+}
+
+// bookingCode keeps the first booking code met for the group, the groups sharing a booking get a letter after the first one.
+func (n *groupNumbering) bookingCode(visitingGroupCode string, bookingCode string) string {
+	if assigned, ok := n.bookingCodes[visitingGroupCode]; ok {
+		return assigned
+	}
+	if bookingCode == "" {
+		return ""
+	}
+
+	key := strings.ToLower(bookingCode)
+	assigned := bookingCode
+	if previous := n.usedBookingCodes[key]; previous > 0 {
+		assigned = bookingCode + "-" + numToChars(uint(previous+1))
+	}
+	n.usedBookingCodes[key]++
+	n.bookingCodes[visitingGroupCode] = assigned
+	return assigned
+}
+
+// schoolAbbreviation keeps the abbreviation of each school, adding the number of the school when another school has the same one.
+func (n *groupNumbering) schoolAbbreviation(school parser.School, schoolNumber int) string {
+	if assigned, ok := n.schoolAbbreviations[school.Code]; ok {
+		return assigned
+	}
+
+	assigned := abbreviate(school.DisplayName())
+	if assigned == "" {
+		assigned = strconv.Itoa(schoolNumber)
+	} else if usedBy, ok := n.usedAbbreviations[assigned]; ok && usedBy != school.Code {
+		assigned += strconv.Itoa(schoolNumber)
+	}
+	n.usedAbbreviations[assigned] = school.Code
+	n.schoolAbbreviations[school.Code] = assigned
+	return assigned
+}
+
+var abbreviationStopWords = map[string]bool{
+	"di": true, "d": true, "del": true, "dell": true, "della": true, "dello": true, "dei": true, "degli": true, "delle": true,
+	"e": true, "ed": true, "a": true, "al": true, "alla": true, "il": true, "lo": true, "la": true, "l": true,
+	"i": true, "gli": true, "le": true,
+}
+
+// abbreviate takes the initials of the words of the name, or the first letters when it is a single word.
+func abbreviate(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	significant := make([]string, 0, len(words))
+	for _, word := range words {
+		if !abbreviationStopWords[strings.ToLower(word)] {
+			significant = append(significant, word)
+		}
+	}
+
+	if len(significant) == 0 {
+		return ""
+	}
+	if len(significant) == 1 {
+		runes := []rune(significant[0])
+		if len(runes) > 3 {
+			runes = runes[:3]
+		}
+		return strings.ToUpper(string(runes))
+	}
+
+	initials := make([]rune, 0, 4)
+	for _, word := range significant {
+		if len(initials) == 4 {
+			break
+		}
+		initials = append(initials, []rune(word)[0])
+	}
+	return strings.ToUpper(string(initials))
+}
@@ -0,0 +1,138 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestGroupNumbering(t *testing.T) {
+	onDay := func(day, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, config.TimeZone())
+	}
+	row := func(id int, day, hour int, group string, booking string) Row {
+		return Row{
+			InputRow: InputRow{ID: id, BookingCode: booking, StartTime: onDay(day, hour), EndTime: onDay(day, hour+1),
+				VisitingGroupCode: group},
+			CompetenceDate: onDay(day, 0),
+		}
+	}
+
+	anagraphics := &parser.OutputAnagraphics{
+		Schools: map[string]parser.School{
+			"s1": {Code: "s1", Name: "Istituto Comprensivo Gramsci"},
+			"s2": {Code: "s2", Name: "Liceo Galilei"},
+		},
+		SchoolClasses: map[string]parser.SchoolClass{
+			"c1": {Code: "c1", SchoolCode: "s1"},
+			"c2": {Code: "c2", SchoolCode: "s2"},
+			"c3": {Code: "c3", SchoolCode: "s1"},
+		},
+		VisitingGroups: map[string]parser.VisitingGroup{
+			"g1": {Code: "g1", SchoolCode: "s1", SchoolClassCode: "c1"},
+			"g2": {Code: "g2", SchoolCode: "s2", SchoolClassCode: "c2"},
+			"g3": {Code: "g3", SchoolCode: "s1", SchoolClassCode: "c3"},
+		},
+	}
+
+	rows := []Row{
+		// monday
+		row(1, 10, 9, "g2", "B2"),
+		row(2, 10, 10, "g1", "B1"),
+		// tuesday
+		row(3, 11, 9, "g1", "B4"),
+		row(4, 11, 10, "g3", "B3"),
+		// monday of the next week
+		row(5, 17, 9, "g1", "B5"),
+	}
+
+	type testCase struct {
+		numbering config.GroupNumberingConfig
+		expected  []map[string]string
+	}
+
+	testCases := []testCase{
+		{config.GroupNumberingConfig{}, []map[string]string{
+			{"g2": "1-a", "g1": "2-a"},
+			{"g1": "1-a", "g3": "1-b"},
+			{"g1": "1-a"},
+		}},
+		{config.GroupNumberingConfig{Scope: config.GroupNumberingPerWeek}, []map[string]string{
+			{"g2": "1-a", "g1": "2-a"},
+			{"g1": "2-a", "g3": "2-b"},
+			{"g1": "1-a"},
+		}},
+		{config.GroupNumberingConfig{Scope: config.GroupNumberingPerPeriod}, []map[string]string{
+			{"g2": "1-a", "g1": "2-a"},
+			{"g1": "2-a", "g3": "2-b"},
+			{"g1": "2-a"},
+		}},
+		{config.GroupNumberingConfig{Scheme: config.GroupNumberingBookingCode}, []map[string]string{
+			{"g2": "B2", "g1": "B1"},
+			{"g1": "B4", "g3": "B3"},
+			{"g1": "B5"},
+		}},
+		{config.GroupNumberingConfig{Scope: config.GroupNumberingPerPeriod, Scheme: config.GroupNumberingBookingCode}, []map[string]string{
+			{"g2": "B2", "g1": "B1"},
+			{"g1": "B1", "g3": "B3"},
+			{"g1": "B1"},
+		}},
+		{config.GroupNumberingConfig{Scope: config.GroupNumberingPerPeriod, Scheme: config.GroupNumberingSchoolLetter}, []map[string]string{
+			{"g2": "LG-a", "g1": "ICG-a"},
+			{"g1": "ICG-a", "g3": "ICG-b"},
+			{"g1": "ICG-a"},
+		}},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			ctx := config.WorkflowContext{
+				Context: context.Background(),
+				Logger:  logrus.NewEntry(logrus.New()),
+				Config:  config.WorkflowContextConfig{GroupNumbering: testCase.numbering},
+			}
+
+			days := AggregateByCompetenceDay(ctx, rows, anagraphics)
+
+			actual := make([]map[string]string, 0, len(days))
+			for _, day := range days {
+				codes := make(map[string]string)
+				for _, group := range day.VisitingGroups {
+					codes[group.VisitingGroupCode] = group.DisplayCode
+				}
+				actual = append(actual, codes)
+			}
+			assert.Equal(t, testCase.expected, actual)
+		})
+	}
+}
+
+func TestAbbreviate(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+	}
+
+	testCases := []testCase{
+		{"Istituto Comprensivo Gramsci", "ICG"},
+		{"Liceo Scientifico Galileo Galilei di Torino", "LSGG"},
+		{"Scuola dell'Infanzia", "SI"},
+		{"Rodari", "ROD"},
+		{"  ", ""},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, testCase.output, abbreviate(testCase.input))
+		})
+	}
+}
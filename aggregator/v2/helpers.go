@@ -1,5 +1,7 @@
 package aggregator
 
+import "time"
+
 func numToChars(columnNumber uint) string {
 
 	// To store result (Excel column name)
@@ -33,3 +35,9 @@ func reverse(s string) string {
 	}
 	return string(runes)
 }
+
+func isSameDate(a, b time.Time) bool {
+	ya, ma, da := a.Date()
+	yb, mb, db := b.Date()
+	return ya == yb && ma == mb && da == db
+}
@@ -23,11 +23,15 @@ type InputRow struct {
 	OperatorCode      string
 	VisitingGroupCode string
 	ActivityCode      string
+	OperatorSuggested bool
+	LunchTurnProposed bool
 
 	Confirmed                   *bool
 	IsPlaceholderNumeroAttivita bool
 
 	Warnings []parser.Warning
+
+	Change *parser.BookingChange
 }
 
 type Input struct {
@@ -43,26 +47,26 @@ func ToInput(input parser.Output) Input {
 
 	for _, r := range input.Rows {
 		i.Rows = append(i.Rows, InputRow{
-			ID:           r.ID,
-			BookingCode:  r.BookingCode,
-			Date:         r.Date,
-			StartTime:    r.StartTime,
-			EndTime:      r.EndTime,
-			Duration:     r.Duration,
-			BookingNote:  r.BookingNote,
-			OperatorNote: r.OperatorNote,
-			Payment: parser.PaymentStatus{
-				PaymentAdvance:       r.Payment.PaymentAdvance,
-				PaymentAdvanceStatus: r.Payment.PaymentAdvanceStatus,
-			},
+			ID:                          r.ID,
+			BookingCode:                 r.BookingCode,
+			Date:                        r.Date,
+			StartTime:                   r.StartTime,
+			EndTime:                     r.EndTime,
+			Duration:                    r.Duration,
+			BookingNote:                 r.BookingNote,
+			OperatorNote:                r.OperatorNote,
+			Payment:                     r.Payment,
 			RoomCode:                    r.RoomCode,
 			OperatorCode:                r.OperatorCode,
 			VisitingGroupCode:           r.VisitingGroupCode,
 			ActivityCode:                r.ActivityCode,
+			OperatorSuggested:           r.OperatorSuggested,
+			LunchTurnProposed:           r.LunchTurnProposed,
 			Bus:                         r.Bus,
 			Warnings:                    r.Warnings,
 			IsPlaceholderNumeroAttivita: r.IsPlaceholderNumeroAttivita,
 			Confirmed:                   r.Confirmed,
+			Change:                      r.Change,
 		})
 	}
 
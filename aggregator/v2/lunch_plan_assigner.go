@@ -0,0 +1,26 @@
+package aggregator
+
+import (
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+// AssignLunchPlans attaches to each day the lunch plan computed by the parser for the same date.
+func AssignLunchPlans(
+	_ config.WorkflowContext,
+	days []ScheduleForSingleDayWithRoomsAndGroupSlots,
+	plans []parser.LunchPlan,
+) []ScheduleForSingleDayWithRoomsAndGroupSlots {
+
+	out := make([]ScheduleForSingleDayWithRoomsAndGroupSlots, 0, len(days))
+	for _, day := range days {
+		for _, plan := range plans {
+			if isSameDate(plan.Date, day.Day) {
+				day.Lunch = plan
+				break
+			}
+		}
+		out = append(out, day)
+	}
+	return out
+}
@@ -21,6 +21,7 @@ type CommonTimespan struct {
 type CommonData struct {
 	CommonTimespan          CommonTimespan
 	MaxVisitingGroupsPerDay int
+	MaxLunchRowsPerDay      int
 }
 
 type TimeOfDay struct {
@@ -37,6 +38,17 @@ func (tod TimeOfDay) IsBefore(other TimeOfDay) bool {
 	return tod.Minute < other.Minute
 }
 
+// RelativeToDay returns the time of day of t counted from the midnight of the competence day,
+// so that the times after the following midnight are after 24:00.
+func RelativeToDay(t time.Time, competenceDate time.Time, location *time.Location) TimeOfDay {
+	t = t.In(location)
+	competenceDate = competenceDate.In(location)
+	dayOfTime := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	competenceDay := time.Date(competenceDate.Year(), competenceDate.Month(), competenceDate.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(dayOfTime.Sub(competenceDay).Hours() / 24)
+	return TimeOfDay{Hour: t.Hour() + 24*days, Minute: t.Minute()}
+}
+
 func (tod TimeOfDay) IsAfter(other TimeOfDay) bool {
 	if tod.Hour > other.Hour {
 		return true
@@ -104,6 +116,16 @@ type GroupedActivity struct {
 	Rows              []OutputRow
 	FitComputationLog []string
 	AnyConfirmed      bool
+	// PlacementCandidates and PlacementNote are filled only when the placement explanation is enabled
+	PlacementCandidates []SlotCandidate `json:",omitempty"`
+	PlacementNote       string          `json:",omitempty"`
+	// PreviousSlotIndex is the slot of the activity in the previous layout, nil if it was not there
+	PreviousSlotIndex *int `json:",omitempty"`
+}
+
+// MovedFromPreviousLayout tells whether the activity was in another slot in the previous layout.
+func (g GroupedActivity) MovedFromPreviousLayout() bool {
+	return g.PreviousSlotIndex != nil && *g.PreviousSlotIndex != g.StartingSlotIndex
 }
 
 func (g *GroupedActivity) distinct(extractor func(OutputRow) string) []string {
@@ -147,18 +169,48 @@ func (g *GroupedActivity) ActivityCodes() []string {
 		return row.ActivityCode
 	})
 }
-func (g *GroupedActivity) Warnings() []parser.Warning {
-	index := make(map[string]parser.Warning)
+func (g *GroupedActivity) AnyOperatorSuggested() bool {
+	for _, o := range g.Rows {
+		if o.OperatorSuggested {
+			return true
+		}
+	}
+	return false
+}
+
+// AnyChanged tells whether any of the rows was added or modified compared to the previous version of the bookings.
+func (g *GroupedActivity) AnyChanged() bool {
+	for _, o := range g.Rows {
+		if o.Change != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRelevantWarnings tells whether any of the warnings should be pointed out in the output.
+func (g *GroupedActivity) HasRelevantWarnings() bool {
 	for _, o := range g.Rows {
 		for _, w := range o.Warnings {
-			if _, ok := index[w.Code]; !ok {
-				index[w.Code] = w
+			if w.IsRelevant() {
+				return true
 			}
 		}
 	}
-	out := make([]parser.Warning, 0, len(index))
-	for _, v := range index {
-		out = append(out, v)
+	return false
+}
+
+// Warnings returns the distinct warnings of the rows, in the order they are first found.
+func (g *GroupedActivity) Warnings() []parser.Warning {
+	index := make(map[string]bool)
+	out := make([]parser.Warning, 0)
+	for _, o := range g.Rows {
+		for _, w := range o.Warnings {
+			if !index[w.Code] {
+				index[w.Code] = true
+				out = append(out, w)
+			}
+		}
 	}
 	return out
 }
@@ -195,6 +247,8 @@ type ScheduleForSingleDayWithRoomsAndGroupSlots struct {
 	NumeroAttivitaMarkers                 map[time.Time]int
 	NumeroAttivitaConfermateMarkers       map[time.Time]int
 	NumeroGruppiAttivitaConfermateMarkers map[time.Time]int
+	Shuttle                               ShuttleTimetable
+	Lunch                                 parser.LunchPlan
 }
 
 type ScheduleForSingleDayAndRoomWithGroupSlots struct {
@@ -23,6 +23,8 @@ type OutputRow struct {
 	OperatorCode      string
 	VisitingGroupCode string
 	ActivityCode      string
+	OperatorSuggested bool
+	LunchTurnProposed bool
 
 	Confirmed                   *bool
 	IsPlaceholderNumeroAttivita bool
@@ -30,35 +32,40 @@ type OutputRow struct {
 	Warnings []parser.Warning
 
 	CompetenceDate time.Time
+
+	Change *parser.BookingChange `json:",omitempty"`
 }
 
 type Output struct {
 	CommonData CommonData
 	Days       []ScheduleForSingleDayWithRoomsAndGroupSlots
+	Finance    FinanceSummary
+	// Changes compared to the previous version of the bookings, when given
+	Changes []parser.BookingChange
 }
 
 func ToOutputRow(input Row) OutputRow {
 	return OutputRow{
-		ID:           input.InputRow.ID,
-		BookingCode:  input.InputRow.BookingCode,
-		Date:         input.InputRow.Date,
-		StartTime:    input.InputRow.StartTime,
-		EndTime:      input.InputRow.EndTime,
-		Duration:     input.InputRow.Duration,
-		BookingNote:  input.InputRow.BookingNote,
-		OperatorNote: input.InputRow.OperatorNote,
-		Payment: parser.PaymentStatus{
-			PaymentAdvance:       input.InputRow.Payment.PaymentAdvance,
-			PaymentAdvanceStatus: input.InputRow.Payment.PaymentAdvanceStatus,
-		},
+		ID:                          input.InputRow.ID,
+		BookingCode:                 input.InputRow.BookingCode,
+		Date:                        input.InputRow.Date,
+		StartTime:                   input.InputRow.StartTime,
+		EndTime:                     input.InputRow.EndTime,
+		Duration:                    input.InputRow.Duration,
+		BookingNote:                 input.InputRow.BookingNote,
+		OperatorNote:                input.InputRow.OperatorNote,
+		Payment:                     input.InputRow.Payment,
 		RoomCode:                    input.InputRow.RoomCode,
 		OperatorCode:                input.InputRow.OperatorCode,
 		VisitingGroupCode:           input.InputRow.VisitingGroupCode,
 		ActivityCode:                input.InputRow.ActivityCode,
+		OperatorSuggested:           input.InputRow.OperatorSuggested,
+		LunchTurnProposed:           input.InputRow.LunchTurnProposed,
 		CompetenceDate:              input.CompetenceDate,
 		Bus:                         input.InputRow.Bus,
 		Warnings:                    input.InputRow.Warnings,
 		IsPlaceholderNumeroAttivita: input.InputRow.IsPlaceholderNumeroAttivita,
 		Confirmed:                   input.InputRow.Confirmed,
+		Change:                      input.InputRow.Change,
 	}
 }
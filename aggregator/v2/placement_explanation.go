@@ -0,0 +1,144 @@
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// the factors of the slot placement score, named after the fields of database.SlotPlacementPreferences
+const (
+	FactorFittingWithSmallClearance        = "PointsForFittingWithSmallClearance"
+	FactorFittingWithLotClearance          = "PointsForFittingWithLotClearance"
+	FactorActivityImmediatelyToTheRight    = "PenaltyForActivityImmediatelyToTheRight"
+	FactorActivity2ndToTheRight            = "PenaltyForActivity2ndToTheRight"
+	FactorActivityImmediatelyToTheLeft     = "PenaltyForActivityImmediatelyToTheLeft"
+	FactorOperatorHasOtherActivitiesInSlot = "PointsForOperatorHasOtherActivitiesInSlot"
+	FactorGroupHasOtherActivitiesInSlot    = "PointsForGroupHasOtherActivitiesInSlot"
+	FactorEachOtherActivityInSlot          = "PenaltyForEachOtherActivityInSlot"
+	FactorPreviousSlot                     = "PointsForPreviousSlot"
+	// FactorLeftmostPosition is not configurable, it breaks the ties in favour of the slots on the left
+	FactorLeftmostPosition = "LeftmostPosition"
+)
+
+// SlotScoreFactors lists the factors in the order they are applied.
+var SlotScoreFactors = []string{
+	FactorFittingWithSmallClearance,
+	FactorFittingWithLotClearance,
+	FactorActivityImmediatelyToTheRight,
+	FactorActivity2ndToTheRight,
+	FactorActivityImmediatelyToTheLeft,
+	FactorOperatorHasOtherActivitiesInSlot,
+	FactorGroupHasOtherActivitiesInSlot,
+	FactorEachOtherActivityInSlot,
+	FactorPreviousSlot,
+	FactorLeftmostPosition,
+}
+
+type SlotScoreFactor struct {
+	Factor string
+	Points int
+}
+
+// SlotCandidate is a slot evaluated for an activity, with the breakdown of its score.
+type SlotCandidate struct {
+	SlotIndex int
+	// Fits is false when the activity overlaps another one in the slot, the slot is then not scored
+	Fits    bool
+	Score   int
+	Chosen  bool
+	Factors []SlotScoreFactor
+}
+
+func (c SlotCandidate) PointsFor(factor string) int {
+	points := 0
+	for _, f := range c.Factors {
+		if f.Factor == factor {
+			points += f.Points
+		}
+	}
+	return points
+}
+
+// PlacementExplanation tells why an activity was placed in a slot of its room.
+type PlacementExplanation struct {
+	Day          time.Time
+	RoomCode     string
+	ActivityID   int
+	StartTime    time.Time
+	EndTime      time.Time
+	ActivityCode string
+	GroupCodes   []string
+	ChosenSlot   int
+	Note         string
+	Candidates   []SlotCandidate
+}
+
+// CollectPlacementExplanations lists the placement of every activity, by day, room and start time.
+// The explanations are recorded only when the placement explanation is enabled.
+func CollectPlacementExplanations(days []ScheduleForSingleDayWithRoomsAndGroupSlots) []PlacementExplanation {
+	out := make([]PlacementExplanation, 0)
+
+	for _, day := range days {
+		for _, roomSchedule := range day.RoomsSchedule {
+			explanations := make([]PlacementExplanation, 0)
+			for _, slot := range roomSchedule.Slots {
+				for _, act := range slot.GroupedActivities {
+					if act.StartingSlotIndex != slot.SlotIndex || (len(act.PlacementCandidates) == 0 && act.PlacementNote == "") {
+						continue
+					}
+					explanation := PlacementExplanation{
+						Day:        day.Day,
+						RoomCode:   roomSchedule.RoomCode,
+						ActivityID: act.ID,
+						StartTime:  act.StartTime,
+						EndTime:    act.EndTime,
+						ChosenSlot: act.StartingSlotIndex,
+						Note:       act.PlacementNote,
+						Candidates: act.PlacementCandidates,
+					}
+					for _, row := range act.Rows {
+						explanation.ActivityCode = row.ActivityCode
+						if row.VisitingGroupCode != "" {
+							explanation.GroupCodes = append(explanation.GroupCodes, row.VisitingGroupCode)
+						}
+					}
+					explanations = append(explanations, explanation)
+				}
+			}
+			sort.SliceStable(explanations, func(i, j int) bool {
+				if !explanations[i].StartTime.Equal(explanations[j].StartTime) {
+					return explanations[i].StartTime.Before(explanations[j].StartTime)
+				}
+				return explanations[i].ActivityID < explanations[j].ActivityID
+			})
+			out = append(out, explanations...)
+		}
+	}
+
+	return out
+}
+
+// describePlacement writes the lines of the placement explanation shown in the comment of the activity.
+func describePlacement(chosenSlot int, note string, candidates []SlotCandidate) []string {
+	out := make([]string, 0, len(candidates)+1)
+	if note != "" {
+		out = append(out, note)
+	}
+	for _, candidate := range candidates {
+		line := fmt.Sprintf("colonna %d: ", candidate.SlotIndex+1)
+		if !candidate.Fits {
+			out = append(out, line+"non disponibile")
+			continue
+		}
+		line += fmt.Sprintf("%d punti", candidate.Score)
+		for _, factor := range candidate.Factors {
+			line += fmt.Sprintf(", %s %+d", factor.Factor, factor.Points)
+		}
+		if candidate.SlotIndex == chosenSlot {
+			line += " (scelta)"
+		}
+		out = append(out, line)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestCollectPlacementExplanations(t *testing.T) {
+	days := []ScheduleForSingleDayWithRoomsAndGroupedActivities{{
+		Day: at(12, 0),
+		RoomsSchedule: []ScheduleForSingleDayAndRoomWithGroupedActivities{{
+			RoomCode: "aula",
+			GroupedActivities: []GroupedActivity{
+				testActivity(1, at(9, 0), at(10, 0), "op1", "a"),
+				testActivity(2, at(9, 30), at(11, 0), "op2", "b"),
+			},
+		}},
+	}}
+	anagraphics := &parser.OutputAnagraphics{Rooms: map[string]parser.Room{"aula": {Code: "aula", Name: "Aula", Slots: 2}}}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	// disabled by default
+	assert.Empty(t, CollectPlacementExplanations(AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)))
+
+	ctx.Config.ExplainPlacement = true
+	explanations := CollectPlacementExplanations(AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics))
+	if !assert.Len(t, explanations, 2) {
+		return
+	}
+
+	first := explanations[0]
+	assert.Equal(t, 1, first.ActivityID)
+	assert.Equal(t, []string{"a"}, first.GroupCodes)
+	assert.Equal(t, 0, first.ChosenSlot)
+	if assert.Len(t, first.Candidates, 2) {
+		assert.True(t, first.Candidates[0].Chosen)
+		assert.False(t, first.Candidates[1].Chosen)
+		assert.Equal(t, -1, first.Candidates[1].PointsFor(FactorLeftmostPosition))
+		total := 0
+		for _, factor := range first.Candidates[1].Factors {
+			total += factor.Points
+		}
+		assert.Equal(t, first.Candidates[1].Score, total)
+	}
+
+	second := explanations[1]
+	assert.Equal(t, 1, second.ChosenSlot)
+	if assert.Len(t, second.Candidates, 2) {
+		// the first slot is taken by the overlapping activity
+		assert.False(t, second.Candidates[0].Fits)
+		assert.Empty(t, second.Candidates[0].Factors)
+		assert.True(t, second.Candidates[1].Chosen)
+		assert.Equal(t, database.DefaultSlotPlacementPreferences.PointsForFittingWithSmallClearance,
+			second.Candidates[1].PointsFor(FactorFittingWithSmallClearance))
+	}
+
+	// the same explanation is shown in the comment of the activity
+	assert.Equal(t, []string{
+		"colonna 1: non disponibile",
+		"colonna 2: 59 punti, PointsForFittingWithSmallClearance +50, PointsForFittingWithLotClearance +10, LeftmostPosition -1 (scelta)",
+	}, describePlacement(second.ChosenSlot, second.Note, second.Candidates))
+}
@@ -0,0 +1,114 @@
+package aggregator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+// LayoutChange is an activity placed in another slot than in the previous layout.
+type LayoutChange struct {
+	Day          time.Time
+	RoomCode     string
+	ActivityCode string
+	BookingCodes []string
+	StartTime    time.Time
+	EndTime      time.Time
+	PreviousSlot int
+	Slot         int
+}
+
+// withPreviousSlots sets the slot of the previous layout on the activities of the room, matching them by booking code,
+// room and start time. The activities found in the previous layout are placed first, so that the new ones
+// do not take their slots.
+func withPreviousSlots(layout *database.Layout, roomSchedule ScheduleForSingleDayAndRoomWithGroupedActivities) []GroupedActivity {
+	if layout == nil {
+		return roomSchedule.GroupedActivities
+	}
+
+	out := make([]GroupedActivity, len(roomSchedule.GroupedActivities))
+	copy(out, roomSchedule.GroupedActivities)
+
+	for i, act := range out {
+		for _, row := range act.Rows {
+			if slot, ok := layout.SlotOf(roomSchedule.RoomCode, row.BookingCode, act.StartTime); ok {
+				previous := slot
+				out[i].PreviousSlotIndex = &previous
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].PreviousSlotIndex != nil && out[j].PreviousSlotIndex == nil
+	})
+	return out
+}
+
+// BuildLayout stores the slot of every activity, to be kept in the next runs.
+func BuildLayout(days []ScheduleForSingleDayWithRoomsAndGroupSlots) *database.Layout {
+	placements := make([]database.LayoutPlacement, 0)
+
+	for _, day := range days {
+		for _, roomSchedule := range day.RoomsSchedule {
+			for _, slot := range roomSchedule.Slots {
+				for _, act := range slot.GroupedActivities {
+					if act.StartingSlotIndex != slot.SlotIndex {
+						continue
+					}
+					bookingCodes := act.BookingCodes()
+					if len(bookingCodes) == 0 {
+						continue
+					}
+					placements = append(placements, database.LayoutPlacement{
+						RoomCode:     roomSchedule.RoomCode,
+						BookingCodes: bookingCodes,
+						StartTime:    act.StartTime,
+						EndTime:      act.EndTime,
+						SlotIndex:    act.StartingSlotIndex,
+					})
+				}
+			}
+		}
+	}
+
+	return database.NewLayout(placements)
+}
+
+// CollectLayoutChanges lists the activities moved from their slot in the previous layout, by day, room and start time.
+func CollectLayoutChanges(days []ScheduleForSingleDayWithRoomsAndGroupSlots) []LayoutChange {
+	out := make([]LayoutChange, 0)
+
+	for _, day := range days {
+		for _, roomSchedule := range day.RoomsSchedule {
+			changes := make([]LayoutChange, 0)
+			for _, slot := range roomSchedule.Slots {
+				for _, act := range slot.GroupedActivities {
+					if act.StartingSlotIndex != slot.SlotIndex || !act.MovedFromPreviousLayout() {
+						continue
+					}
+					change := LayoutChange{
+						Day:          day.Day,
+						RoomCode:     roomSchedule.RoomCode,
+						BookingCodes: act.BookingCodes(),
+						StartTime:    act.StartTime,
+						EndTime:      act.EndTime,
+						PreviousSlot: *act.PreviousSlotIndex,
+						Slot:         act.StartingSlotIndex,
+					}
+					if len(act.Rows) > 0 {
+						change.ActivityCode = act.Rows[0].ActivityCode
+					}
+					changes = append(changes, change)
+				}
+			}
+			sort.SliceStable(changes, func(i, j int) bool {
+				return changes[i].StartTime.Before(changes[j].StartTime)
+			})
+			out = append(out, changes...)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,74 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestPreviousLayoutIsKept(t *testing.T) {
+	days := []ScheduleForSingleDayWithRoomsAndGroupedActivities{{
+		Day: at(12, 0),
+		RoomsSchedule: []ScheduleForSingleDayAndRoomWithGroupedActivities{{
+			RoomCode: "aula",
+			GroupedActivities: []GroupedActivity{
+				// a new booking, processed first without a previous layout
+				testActivity(1, at(9, 0), at(10, 0), "", "B003"),
+				testActivity(2, at(9, 0), at(10, 0), "", "B001"),
+				testActivity(3, at(11, 0), at(12, 0), "", "B002"),
+			},
+		}},
+	}}
+	anagraphics := &parser.OutputAnagraphics{Rooms: map[string]parser.Room{"aula": {Code: "aula", Name: "Aula"}}}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	slotOf := func(days []ScheduleForSingleDayWithRoomsAndGroupSlots) map[int]int {
+		out := make(map[int]int)
+		for _, slot := range days[0].RoomsSchedule[0].Slots {
+			for _, act := range slot.GroupedActivities {
+				out[act.ID] = act.StartingSlotIndex
+			}
+		}
+		return out
+	}
+
+	assert.Equal(t, map[int]int{1: 0, 2: 1, 3: 0}, slotOf(AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)))
+
+	ctx.Config.PreviousLayout = database.NewLayout([]database.LayoutPlacement{
+		{RoomCode: "aula", BookingCodes: []string{"B001"}, StartTime: at(9, 0), SlotIndex: 0},
+		// a slot beyond the ones of the room is created
+		{RoomCode: "aula", BookingCodes: []string{"B002"}, StartTime: at(11, 0), SlotIndex: 2},
+	})
+	placed := AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)
+	assert.Equal(t, map[int]int{1: 1, 2: 0, 3: 2}, slotOf(placed))
+	assert.Empty(t, CollectLayoutChanges(placed))
+
+	// two activities claiming the same slot: the first one keeps it
+	ctx.Config.PreviousLayout = database.NewLayout([]database.LayoutPlacement{
+		{RoomCode: "aula", BookingCodes: []string{"B001"}, StartTime: at(9, 0), SlotIndex: 0},
+		{RoomCode: "aula", BookingCodes: []string{"B003"}, StartTime: at(9, 0), SlotIndex: 0},
+	})
+	placed = AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)
+	changes := CollectLayoutChanges(placed)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, []string{"B001"}, changes[0].BookingCodes)
+		assert.Equal(t, 0, changes[0].PreviousSlot)
+		assert.Equal(t, 1, changes[0].Slot)
+	}
+
+	layout := BuildLayout(placed)
+	assert.Len(t, layout.Placements, 3)
+	slot, ok := layout.SlotOf("aula", "B001", at(9, 0))
+	assert.True(t, ok)
+	assert.Equal(t, 1, slot)
+}
@@ -0,0 +1,34 @@
+package aggregator
+
+import (
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+	"github.com/fabiofenoglio/excelconv/rules"
+	"github.com/pkg/errors"
+)
+
+// PostAggregationRule is a rule applied to the aggregated schedule, before writing it.
+type PostAggregationRule interface {
+	rules.Rule
+	Apply(ctx config.WorkflowContext, output Output, anagraphicsRef *parser.OutputAnagraphics) (Output, error)
+}
+
+var postAggregationRuleRegistry = rules.NewRegistry[PostAggregationRule](rules.StagePostAggregation)
+
+func RegisteredPostAggregationRules() []PostAggregationRule {
+	return postAggregationRuleRegistry.All()
+}
+
+func ApplyPostAggregationRules(ctx config.WorkflowContext, output Output, anagraphicsRef *parser.OutputAnagraphics) (Output, error) {
+	for _, rule := range postAggregationRuleRegistry.Enabled(ctx) {
+		ctx.Logger.Debugf("applying rule %s", rule.Code())
+
+		var err error
+		output, err = rule.Apply(ctx, output, anagraphicsRef)
+		if err != nil {
+			return Output{}, errors.Wrapf(err, "errore nella regola %s", rule.Code())
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,234 @@
+package aggregator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+const shuttleTimetableWindowMinutes = 30
+
+type ShuttleTimetable struct {
+	Buses      []BusInDay
+	Arrivals   []ShuttleWindow
+	Departures []ShuttleWindow
+	// ParkingCapacity is the number of buses the parking can host, 0 if not limited
+	ParkingCapacity int
+	MaxParked       int
+	MaxParkedAt     time.Time
+	FirstArrival    time.Time
+	LastDeparture   time.Time
+}
+
+func (t ShuttleTimetable) IsEmpty() bool {
+	return len(t.Buses) == 0
+}
+
+func (t ShuttleTimetable) ExceedsParkingCapacity() bool {
+	return t.ParkingCapacity > 0 && t.MaxParked > t.ParkingCapacity
+}
+
+type BusInDay struct {
+	VisitingGroupCode string
+	DisplayCode       string
+	ArrivalTime       time.Time
+	DepartureTime     time.Time
+	// EstimatedArrival and EstimatedDeparture are set when the time is not in the bus field
+	// and is taken from the activities of the group instead
+	EstimatedArrival   bool
+	EstimatedDeparture bool
+	Company            string
+	Vehicles           int
+}
+
+type ShuttleWindow struct {
+	Start              time.Time
+	End                time.Time
+	Vehicles           int
+	VisitingGroupCodes []string
+}
+
+// BuildShuttleTimetables computes for each day the arrivals and departures of the buses
+// and how many of them are parked at the same time.
+func BuildShuttleTimetables(
+	ctx config.WorkflowContext,
+	days []ScheduleForSingleDayWithRoomsAndGroupSlots,
+	anagraphicsRef *parser.OutputAnagraphics,
+) []ScheduleForSingleDayWithRoomsAndGroupSlots {
+
+	parkingCapacity := ctx.Config.ParkingCapacity
+
+	out := make([]ScheduleForSingleDayWithRoomsAndGroupSlots, 0, len(days))
+	for _, day := range days {
+		day.Shuttle = buildShuttleTimetableForDay(day, anagraphicsRef)
+		day.Shuttle.ParkingCapacity = parkingCapacity
+
+		if day.Shuttle.ExceedsParkingCapacity() {
+			ctx.Logger.Warnf("il %s alle %s sono previsti %d bus nel parcheggio, la capienza è di %d",
+				day.Day.Format("02/01"), day.Shuttle.MaxParkedAt.Format("15:04"), day.Shuttle.MaxParked, parkingCapacity)
+		}
+		out = append(out, day)
+	}
+	return out
+}
+
+func buildShuttleTimetableForDay(day ScheduleForSingleDayWithRoomsAndGroupSlots, anagraphicsRef *parser.OutputAnagraphics) ShuttleTimetable {
+	out := ShuttleTimetable{}
+
+	// the span of the activities of each group is used when the bus times are not known
+	firstStart := make(map[string]time.Time)
+	lastEnd := make(map[string]time.Time)
+	for _, room := range day.RoomsSchedule {
+		for _, slot := range room.Slots {
+			for _, groupedActivity := range slot.GroupedActivities {
+				for _, row := range groupedActivity.Rows {
+					if row.IsPlaceholderNumeroAttivita || row.VisitingGroupCode == "" {
+						continue
+					}
+					if !row.StartTime.IsZero() {
+						if v, ok := firstStart[row.VisitingGroupCode]; !ok || row.StartTime.Before(v) {
+							firstStart[row.VisitingGroupCode] = row.StartTime
+						}
+					}
+					if !row.EndTime.IsZero() {
+						if v, ok := lastEnd[row.VisitingGroupCode]; !ok || row.EndTime.After(v) {
+							lastEnd[row.VisitingGroupCode] = row.EndTime
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, visitingGroup := range day.VisitingGroups {
+		bus := anagraphicsRef.VisitingGroups[visitingGroup.VisitingGroupCode].Bus
+		if !bus.IsPresent() {
+			continue
+		}
+
+		entry := BusInDay{
+			VisitingGroupCode: visitingGroup.VisitingGroupCode,
+			DisplayCode:       visitingGroup.DisplayCode,
+			ArrivalTime:       atSameTimeOfDay(bus.ArrivalTime, day.Day),
+			DepartureTime:     atSameTimeOfDay(bus.DepartureTime, day.Day),
+			Company:           bus.Company,
+			Vehicles:          bus.Vehicles,
+		}
+		if entry.ArrivalTime.IsZero() {
+			entry.ArrivalTime = firstStart[visitingGroup.VisitingGroupCode]
+			entry.EstimatedArrival = !entry.ArrivalTime.IsZero()
+		}
+		if entry.DepartureTime.IsZero() {
+			entry.DepartureTime = lastEnd[visitingGroup.VisitingGroupCode]
+			entry.EstimatedDeparture = !entry.DepartureTime.IsZero()
+		}
+
+		out.Buses = append(out.Buses, entry)
+	}
+
+	sort.SliceStable(out.Buses, func(i, j int) bool {
+		bi, bj := out.Buses[i], out.Buses[j]
+		if bi.ArrivalTime.IsZero() != bj.ArrivalTime.IsZero() {
+			return !bi.ArrivalTime.IsZero()
+		}
+		if !bi.ArrivalTime.Equal(bj.ArrivalTime) {
+			return bi.ArrivalTime.Before(bj.ArrivalTime)
+		}
+		if bi.DisplayCode != bj.DisplayCode {
+			return bi.DisplayCode < bj.DisplayCode
+		}
+		return bi.VisitingGroupCode < bj.VisitingGroupCode
+	})
+
+	out.Arrivals = groupBusesByWindow(out.Buses, func(b BusInDay) time.Time { return b.ArrivalTime })
+	out.Departures = groupBusesByWindow(out.Buses, func(b BusInDay) time.Time { return b.DepartureTime })
+
+	for _, bus := range out.Buses {
+		if !bus.ArrivalTime.IsZero() && (out.FirstArrival.IsZero() || bus.ArrivalTime.Before(out.FirstArrival)) {
+			out.FirstArrival = bus.ArrivalTime
+		}
+		if !bus.DepartureTime.IsZero() && bus.DepartureTime.After(out.LastDeparture) {
+			out.LastDeparture = bus.DepartureTime
+		}
+	}
+
+	out.MaxParked, out.MaxParkedAt = computeMaxParked(out.Buses)
+	return out
+}
+
+func groupBusesByWindow(buses []BusInDay, extractor func(BusInDay) time.Time) []ShuttleWindow {
+	index := make(map[time.Time]*ShuttleWindow)
+	out := make([]*ShuttleWindow, 0)
+
+	for _, bus := range buses {
+		t := extractor(bus)
+		if t.IsZero() {
+			continue
+		}
+		start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()-t.Minute()%shuttleTimetableWindowMinutes, 0, 0, t.Location())
+		window, ok := index[start]
+		if !ok {
+			window = &ShuttleWindow{
+				Start: start,
+				End:   start.Add(shuttleTimetableWindowMinutes * time.Minute),
+			}
+			index[start] = window
+			out = append(out, window)
+		}
+		window.Vehicles += bus.Vehicles
+		window.VisitingGroupCodes = append(window.VisitingGroupCodes, bus.VisitingGroupCode)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+
+	res := make([]ShuttleWindow, 0, len(out))
+	for _, w := range out {
+		res = append(res, *w)
+	}
+	return res
+}
+
+// computeMaxParked finds the highest number of buses parked at the same time and when it happens.
+// A bus leaving at the same time another one arrives frees its place first.
+func computeMaxParked(buses []BusInDay) (int, time.Time) {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, len(buses)*2)
+	for _, bus := range buses {
+		if bus.ArrivalTime.IsZero() || bus.DepartureTime.IsZero() || !bus.DepartureTime.After(bus.ArrivalTime) {
+			continue
+		}
+		events = append(events, event{at: bus.ArrivalTime, delta: bus.Vehicles}, event{at: bus.DepartureTime, delta: -bus.Vehicles})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	current, maxParked := 0, 0
+	var maxParkedAt time.Time
+	for _, e := range events {
+		current += e.delta
+		if current > maxParked {
+			maxParked = current
+			maxParkedAt = e.at
+		}
+	}
+	return maxParked, maxParkedAt
+}
+
+func atSameTimeOfDay(t time.Time, day time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+}
@@ -0,0 +1,91 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestBuildShuttleTimetables(t *testing.T) {
+	// bus times are parsed on the date of the booking, which can differ from the day
+	otherDay := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 1, hour, minute, 0, 0, config.TimeZone())
+	}
+
+	anagraphics := &parser.OutputAnagraphics{
+		VisitingGroups: map[string]parser.VisitingGroup{
+			"a": {Code: "a", Bus: parser.BusInfo{ArrivalTime: otherDay(9, 0), DepartureTime: otherDay(13, 0), Company: "Rossi", Vehicles: 2}},
+			"b": {Code: "b", Bus: parser.BusInfo{ArrivalTime: otherDay(9, 20), Vehicles: 1}},
+			"c": {Code: "c", Bus: parser.BusInfo{ArrivalTime: otherDay(13, 0), DepartureTime: otherDay(15, 0), Vehicles: 1}},
+			"d": {Code: "d"},
+		},
+	}
+
+	days := []ScheduleForSingleDayWithRoomsAndGroupSlots{{
+		Day: testDay,
+		VisitingGroups: []VisitingGroupInDay{
+			{VisitingGroupCode: "c", DisplayCode: "3-a"},
+			{VisitingGroupCode: "a", DisplayCode: "1-a"},
+			{VisitingGroupCode: "b", DisplayCode: "2-a"},
+			{VisitingGroupCode: "d", DisplayCode: "4-a"},
+		},
+		RoomsSchedule: []ScheduleForSingleDayAndRoomWithGroupSlots{{
+			RoomCode: "museo",
+			Slots: []ScheduleForSingleDayAndRoomGroupSlot{{
+				GroupedActivities: []GroupedActivity{{
+					Rows: []OutputRow{
+						{VisitingGroupCode: "b", StartTime: at(9, 30), EndTime: at(10, 30)},
+						{VisitingGroupCode: "b", StartTime: at(11, 0), EndTime: at(12, 0)},
+						{VisitingGroupCode: "d", StartTime: at(9, 0), EndTime: at(10, 0)},
+					},
+				}},
+			}},
+		}},
+	}}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+		Config: config.WorkflowContextConfig{
+			ParkingCapacity: 2,
+		},
+	}
+
+	out := BuildShuttleTimetables(ctx, days, anagraphics)
+	shuttle := out[0].Shuttle
+
+	assert.Equal(t, []BusInDay{
+		{VisitingGroupCode: "a", DisplayCode: "1-a", ArrivalTime: at(9, 0), DepartureTime: at(13, 0), Company: "Rossi", Vehicles: 2},
+		{VisitingGroupCode: "b", DisplayCode: "2-a", ArrivalTime: at(9, 20), DepartureTime: at(12, 0), EstimatedDeparture: true, Vehicles: 1},
+		{VisitingGroupCode: "c", DisplayCode: "3-a", ArrivalTime: at(13, 0), DepartureTime: at(15, 0), Vehicles: 1},
+	}, shuttle.Buses)
+
+	assert.Equal(t, []ShuttleWindow{
+		{Start: at(9, 0), End: at(9, 30), Vehicles: 3, VisitingGroupCodes: []string{"a", "b"}},
+		{Start: at(13, 0), End: at(13, 30), Vehicles: 1, VisitingGroupCodes: []string{"c"}},
+	}, shuttle.Arrivals)
+	assert.Len(t, shuttle.Departures, 3)
+
+	assert.Equal(t, at(9, 0), shuttle.FirstArrival)
+	assert.Equal(t, at(15, 0), shuttle.LastDeparture)
+	assert.Equal(t, 3, shuttle.MaxParked)
+	assert.Equal(t, at(9, 20), shuttle.MaxParkedAt)
+	assert.Equal(t, 2, shuttle.ParkingCapacity)
+	assert.True(t, shuttle.ExceedsParkingCapacity())
+
+	ctx.Config.ParkingCapacity = 5
+	out = BuildShuttleTimetables(ctx, days, anagraphics)
+	assert.False(t, out[0].Shuttle.ExceedsParkingCapacity())
+
+	// without a configured capacity the parking is not limited
+	ctx.Config.ParkingCapacity = 0
+	out = BuildShuttleTimetables(ctx, days, anagraphics)
+	assert.Equal(t, 0, out[0].Shuttle.ParkingCapacity)
+	assert.False(t, out[0].Shuttle.ExceedsParkingCapacity())
+}
@@ -0,0 +1,335 @@
+package aggregator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+const (
+	placementWeightSlot           = 10
+	placementWeightOperatorChange = 3
+	placementWeightGroupChange    = 2
+	placementWeightMove           = 5
+	// placementMaxRounds bounds the search, so that the result does not depend on the speed of the machine
+	// when the time budget is large enough
+	placementMaxRounds = 300
+)
+
+// PlacementScore measures a layout of a room in a day, the lower the better.
+type PlacementScore struct {
+	Slots int
+	// OperatorChanges counts how many times an operator moves to another column between two activities
+	OperatorChanges int
+	// GroupChanges counts how many times a visiting group moves to another column between two activities
+	GroupChanges int
+	// Moves counts the activities placed in another slot than in the previous layout
+	Moves int
+}
+
+func (s PlacementScore) Total() int {
+	return s.Slots*placementWeightSlot + s.OperatorChanges*placementWeightOperatorChange + s.GroupChanges*placementWeightGroupChange + s.Moves*placementWeightMove
+}
+
+// placementProblem holds the activities of a room in a day, in the order the greedy placer processed them.
+type placementProblem struct {
+	activities []GroupedActivity
+	// fixed are the activities spanning all the slots of the room, never moved
+	fixed    []bool
+	minSlots int
+	gridStep int
+}
+
+// OptimizeSlotPlacement improves the layout of each room and day found by the greedy placer, minimizing the number
+// of slots and the column changes of the operators and of the groups. It does nothing when no time budget is configured.
+func OptimizeSlotPlacement(
+	ctx config.WorkflowContext,
+	days []ScheduleForSingleDayWithRoomsAndGroupSlots,
+	anagraphicsRef *parser.OutputAnagraphics,
+) []ScheduleForSingleDayWithRoomsAndGroupSlots {
+
+	budget := ctx.Config.PlacementOptimizationBudget
+	if budget <= 0 {
+		return days
+	}
+
+	totalBefore, totalAfter, improvedRooms := 0, 0, 0
+
+	for dayIndex, day := range days {
+		for roomIndex, roomSchedule := range day.RoomsSchedule {
+			room := anagraphicsRef.Rooms[roomSchedule.RoomCode]
+			problem := newPlacementProblem(roomSchedule, room, ctx.Config.GridStepMinutes())
+			if len(problem.activities) < 2 {
+				continue
+			}
+
+			rng := rand.New(rand.NewSource(ctx.Config.Seed))
+			optimized, before, after := problem.optimize(budget, rng)
+			totalBefore += before.Total()
+			totalAfter += after.Total()
+			if after.Total() >= before.Total() {
+				continue
+			}
+
+			improvedRooms++
+			ctx.Logger.Infof("slot placement of room %s on %s improved from %d to %d "+
+				"(slots %d -> %d, operator column changes %d -> %d, group column changes %d -> %d, moves %d -> %d)",
+				room.Name, day.Day.Format("02/01"), before.Total(), after.Total(),
+				before.Slots, after.Slots, before.OperatorChanges, after.OperatorChanges, before.GroupChanges, after.GroupChanges, before.Moves, after.Moves)
+
+			if ctx.Config.ExplainPlacement {
+				noteMoves(optimized, problem.activities)
+			}
+			days[dayIndex].RoomsSchedule[roomIndex].Slots = buildSlots(optimized, after.Slots)
+		}
+	}
+
+	if improvedRooms > 0 {
+		ctx.Logger.Infof("slot placement optimizer improved %d rooms, total score from %d to %d", improvedRooms, totalBefore, totalAfter)
+	} else {
+		ctx.Logger.Info("slot placement optimizer found no improvements")
+	}
+
+	return days
+}
+
+func newPlacementProblem(roomSchedule ScheduleForSingleDayAndRoomWithGroupSlots, room parser.Room, gridStep int) placementProblem {
+	problem := placementProblem{
+		minSlots: 1,
+		gridStep: gridStep,
+	}
+	if room.Slots > 0 {
+		problem.minSlots = int(room.Slots)
+	}
+
+	for _, slot := range roomSchedule.Slots {
+		for _, act := range slot.GroupedActivities {
+			if act.StartingSlotIndex != slot.SlotIndex {
+				continue
+			}
+			problem.activities = append(problem.activities, act)
+		}
+	}
+
+	// a stable order, the same of the greedy placer when there is no previous layout
+	sort.SliceStable(problem.activities, func(i, j int) bool {
+		return problem.activities[i].ID < problem.activities[j].ID
+	})
+	problem.fixed = make([]bool, len(problem.activities))
+	for i, act := range problem.activities {
+		problem.fixed[i] = act.NumOccupiedSlots != len(act.Rows)
+	}
+
+	return problem
+}
+
+// optimize runs a local search starting from the current placement: each round rebuilds the layout placing the
+// activities in a random order on the first slot where they fit, then moves single activities while the score improves.
+func (p placementProblem) optimize(budget time.Duration, rng *rand.Rand) ([]GroupedActivity, PlacementScore, PlacementScore) {
+	deadline := time.Now().Add(budget)
+
+	initialScore := p.score(p.activities)
+	best := p.descend(copyActivities(p.activities), deadline)
+	bestScore := p.score(best)
+
+	order := make([]int, 0, len(p.activities))
+	for i := range p.activities {
+		if !p.fixed[i] {
+			order = append(order, i)
+		}
+	}
+
+	for round := 0; round < placementMaxRounds && time.Now().Before(deadline); round++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		candidate := p.descend(p.firstFit(order), deadline)
+		if candidateScore := p.score(candidate); candidateScore.Total() < bestScore.Total() {
+			best, bestScore = candidate, candidateScore
+		}
+	}
+
+	return best, initialScore, bestScore
+}
+
+// firstFit places the movable activities in the given order on the leftmost slot where they fit.
+func (p placementProblem) firstFit(order []int) []GroupedActivity {
+	acts := copyActivities(p.activities)
+	placed := make([]bool, len(acts))
+	for i := range acts {
+		placed[i] = p.fixed[i]
+	}
+
+	for _, i := range order {
+		for start := 0; ; start++ {
+			if p.fits(acts, placed, i, start) {
+				acts[i].StartingSlotIndex = start
+				placed[i] = true
+				break
+			}
+		}
+	}
+	return acts
+}
+
+// descend moves one activity at a time to the slot that improves the score the most, until no move helps.
+func (p placementProblem) descend(acts []GroupedActivity, deadline time.Time) []GroupedActivity {
+	placed := make([]bool, len(acts))
+	for i := range placed {
+		placed[i] = true
+	}
+
+	currentScore := p.score(acts).Total()
+	for time.Now().Before(deadline) {
+		bestIndex, bestStart, bestScore := -1, 0, currentScore
+		numSlots := p.numSlots(acts)
+
+		for i := range acts {
+			if p.fixed[i] {
+				continue
+			}
+			original := acts[i].StartingSlotIndex
+			for start := 0; start+acts[i].NumOccupiedSlots <= numSlots; start++ {
+				if start == original || !p.fits(acts, placed, i, start) {
+					continue
+				}
+				acts[i].StartingSlotIndex = start
+				if score := p.score(acts).Total(); score < bestScore {
+					bestIndex, bestStart, bestScore = i, start, score
+				}
+			}
+			acts[i].StartingSlotIndex = original
+		}
+
+		if bestIndex < 0 {
+			break
+		}
+		acts[bestIndex].StartingSlotIndex = bestStart
+		currentScore = bestScore
+	}
+	return acts
+}
+
+// fits tells whether the activity i can start at the given slot without overlapping the other placed activities
+// on the rows of the time grid.
+func (p placementProblem) fits(acts []GroupedActivity, placed []bool, i int, start int) bool {
+	act := acts[i]
+	if act.StartTime.IsZero() || act.EndTime.IsZero() {
+		return true
+	}
+	actStart, actEnd := SnapToGrid(act.StartTime, act.EndTime, p.gridStep)
+
+	for j, other := range acts {
+		if j == i || !placed[j] || other.StartTime.IsZero() || other.EndTime.IsZero() {
+			continue
+		}
+		if start >= other.StartingSlotIndex+other.NumOccupiedSlots || other.StartingSlotIndex >= start+act.NumOccupiedSlots {
+			continue
+		}
+		otherStart, otherEnd := SnapToGrid(other.StartTime, other.EndTime, p.gridStep)
+		if actStart.Before(otherEnd) && otherStart.Before(actEnd) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p placementProblem) numSlots(acts []GroupedActivity) int {
+	out := p.minSlots
+	for _, act := range acts {
+		if end := act.StartingSlotIndex + act.NumOccupiedSlots; end > out {
+			out = end
+		}
+	}
+	return out
+}
+
+func (p placementProblem) score(acts []GroupedActivity) PlacementScore {
+	type visit struct {
+		at     time.Time
+		id     int
+		column int
+	}
+	operatorVisits := make(map[string][]visit)
+	groupVisits := make(map[string][]visit)
+
+	moves := 0
+	for _, act := range acts {
+		if act.MovedFromPreviousLayout() {
+			moves++
+		}
+		for _, operatorCode := range act.OperatorCodes() {
+			operatorVisits[operatorCode] = append(operatorVisits[operatorCode], visit{act.StartTime, act.ID, act.StartingSlotIndex})
+		}
+		for rowIndex, row := range act.Rows {
+			if row.VisitingGroupCode == "" {
+				continue
+			}
+			groupVisits[row.VisitingGroupCode] = append(groupVisits[row.VisitingGroupCode], visit{act.StartTime, act.ID, act.StartingSlotIndex + rowIndex})
+		}
+	}
+
+	countChanges := func(index map[string][]visit) int {
+		changes := 0
+		for _, visits := range index {
+			sort.Slice(visits, func(i, j int) bool {
+				if !visits[i].at.Equal(visits[j].at) {
+					return visits[i].at.Before(visits[j].at)
+				}
+				return visits[i].id < visits[j].id
+			})
+			for i := 1; i < len(visits); i++ {
+				if visits[i].column != visits[i-1].column {
+					changes++
+				}
+			}
+		}
+		return changes
+	}
+
+	return PlacementScore{
+		Slots:           p.numSlots(acts),
+		OperatorChanges: countChanges(operatorVisits),
+		GroupChanges:    countChanges(groupVisits),
+		Moves:           moves,
+	}
+}
+
+// noteMoves adds to the explanation of the activities moved by the optimizer the slot chosen by the greedy placer.
+func noteMoves(optimized []GroupedActivity, original []GroupedActivity) {
+	for i := range optimized {
+		from, to := original[i].StartingSlotIndex, optimized[i].StartingSlotIndex
+		if from == to {
+			continue
+		}
+		note := fmt.Sprintf("spostata dall'ottimizzatore dalla colonna %d alla %d", from+1, to+1)
+		if optimized[i].PlacementNote != "" {
+			note = optimized[i].PlacementNote + "; " + note
+		}
+		optimized[i].PlacementNote = note
+		optimized[i].FitComputationLog = describePlacement(to, note, optimized[i].PlacementCandidates)
+	}
+}
+
+func copyActivities(acts []GroupedActivity) []GroupedActivity {
+	out := make([]GroupedActivity, len(acts))
+	copy(out, acts)
+	return out
+}
+
+// buildSlots fills the slots of the room with the placed activities, keeping their order.
+func buildSlots(acts []GroupedActivity, numSlots int) []ScheduleForSingleDayAndRoomGroupSlot {
+	slots := make([]ScheduleForSingleDayAndRoomGroupSlot, numSlots)
+	for i := range slots {
+		slots[i].SlotIndex = i
+	}
+	for _, act := range acts {
+		for cnt := 0; cnt < act.NumOccupiedSlots; cnt++ {
+			slots[act.StartingSlotIndex+cnt].GroupedActivities = append(slots[act.StartingSlotIndex+cnt].GroupedActivities, act)
+		}
+	}
+	return slots
+}
@@ -0,0 +1,75 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestOptimizeSlotPlacement(t *testing.T) {
+	days := []ScheduleForSingleDayWithRoomsAndGroupedActivities{{
+		Day: at(12, 0),
+		RoomsSchedule: []ScheduleForSingleDayAndRoomWithGroupedActivities{{
+			RoomCode: "aula",
+			GroupedActivities: []GroupedActivity{
+				testActivity(1, at(9, 0), at(10, 0), "op1", "a"),
+				testActivity(2, at(9, 30), at(11, 0), "op2", "b"),
+				// needs two adjacent slots: the greedy placer can only add two new ones on the right
+				testActivity(3, at(10, 0), at(12, 0), "op1", "c", "d"),
+			},
+		}},
+	}}
+	anagraphics := &parser.OutputAnagraphics{Rooms: map[string]parser.Room{"aula": {Code: "aula", Name: "Aula"}}}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	greedy := AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)
+	assert.Len(t, greedy[0].RoomsSchedule[0].Slots, 4)
+
+	// disabled by default
+	assert.Len(t, OptimizeSlotPlacement(ctx, greedy, anagraphics)[0].RoomsSchedule[0].Slots, 4)
+
+	ctx.Config.PlacementOptimizationBudget = time.Second
+	optimized := OptimizeSlotPlacement(ctx, AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics), anagraphics)
+	slots := optimized[0].RoomsSchedule[0].Slots
+	if assert.Len(t, slots, 3) {
+		// every activity is placed once, on the slots it starts from
+		placed := make(map[int]GroupedActivity)
+		for _, slot := range slots {
+			for _, act := range slot.GroupedActivities {
+				placed[act.ID] = act
+			}
+		}
+		assert.Len(t, placed, 3)
+		assert.Equal(t, 2, placed[3].NumOccupiedSlots)
+		// the operator of the first and the third activity does not change column
+		assert.Equal(t, placed[1].StartingSlotIndex, placed[3].StartingSlotIndex)
+		assert.NotEqual(t, placed[1].StartingSlotIndex, placed[2].StartingSlotIndex)
+	}
+}
+
+func TestPlacementScore(t *testing.T) {
+	act := func(id, slot int, start time.Time, operator, group string) GroupedActivity {
+		return GroupedActivity{ID: id, StartingSlotIndex: slot, NumOccupiedSlots: 1, StartTime: start, EndTime: start.Add(time.Hour),
+			Rows: []OutputRow{{ID: id, OperatorCode: operator, VisitingGroupCode: group}}}
+	}
+
+	problem := placementProblem{minSlots: 2, gridStep: 15}
+	score := problem.score([]GroupedActivity{
+		act(1, 0, at(9, 0), "op1", "a"),
+		act(2, 1, at(10, 0), "op1", "a"),
+		act(3, 0, at(11, 0), "op1", "b"),
+		act(4, 2, at(9, 0), "op2", "c"),
+	})
+	assert.Equal(t, PlacementScore{Slots: 3, OperatorChanges: 2, GroupChanges: 1}, score)
+	assert.Equal(t, 3*placementWeightSlot+2*placementWeightOperatorChange+placementWeightGroupChange, score.Total())
+}
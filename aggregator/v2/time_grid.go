@@ -0,0 +1,33 @@
+package aggregator
+
+import (
+	"time"
+)
+
+// FloorToGrid returns the row of a time grid with the given step that contains t.
+func FloorToGrid(t time.Time, stepMinutes int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()-t.Minute()%stepMinutes, 0, 0, t.Location())
+}
+
+// CeilToGrid returns the first row of a time grid with the given step that starts at t or after it.
+func CeilToGrid(t time.Time, stepMinutes int) time.Time {
+	floor := FloorToGrid(t, stepMinutes)
+	if floor.Before(t) {
+		return floor.Add(time.Duration(stepMinutes) * time.Minute)
+	}
+	return floor
+}
+
+// IsAlignedToGrid tells whether t falls exactly at the start of a row of the time grid.
+func IsAlignedToGrid(t time.Time, stepMinutes int) bool {
+	return t.IsZero() || FloorToGrid(t, stepMinutes).Equal(t)
+}
+
+// SnapToGrid widens a time range to the rows of the time grid it is drawn on,
+// ex. 11:05 - 11:50 is drawn from the 11:00 row to the 11:45 one on a grid of 15 minutes.
+func SnapToGrid(start, end time.Time, stepMinutes int) (time.Time, time.Time) {
+	if start.IsZero() || end.IsZero() {
+		return start, end
+	}
+	return FloorToGrid(start, stepMinutes), CeilToGrid(end, stepMinutes)
+}
@@ -0,0 +1,64 @@
+package aggregator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestSnapToGrid(t *testing.T) {
+
+	type testCase struct {
+		start, end                 time.Time
+		step                       int
+		expectedStart, expectedEnd time.Time
+		aligned                    bool
+	}
+
+	testCases := []testCase{
+		{at(11, 0), at(12, 0), 15, at(11, 0), at(12, 0), true},
+		{at(11, 5), at(11, 50), 15, at(11, 0), at(12, 0), false},
+		{at(11, 5), at(11, 50), 5, at(11, 5), at(11, 50), true},
+		{at(11, 5), at(11, 50), 10, at(11, 0), at(11, 50), false},
+		{at(11, 5), at(11, 50), 30, at(11, 0), at(12, 0), false},
+		{at(23, 50), at(24, 5), 15, at(23, 45), at(24, 15), false},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			start, end := SnapToGrid(testCase.start, testCase.end, testCase.step)
+			assert.True(t, testCase.expectedStart.Equal(start), "expected start %s, got %s", testCase.expectedStart, start)
+			assert.True(t, testCase.expectedEnd.Equal(end), "expected end %s, got %s", testCase.expectedEnd, end)
+			assert.Equal(t, testCase.aligned,
+				IsAlignedToGrid(testCase.start, testCase.step) && IsAlignedToGrid(testCase.end, testCase.step))
+		})
+	}
+}
+
+func TestSlotPlacementOnGridRows(t *testing.T) {
+	days := []ScheduleForSingleDayWithRoomsAndGroupedActivities{{
+		Day: at(12, 0),
+		RoomsSchedule: []ScheduleForSingleDayAndRoomWithGroupedActivities{{
+			RoomCode: "aula",
+			GroupedActivities: []GroupedActivity{
+				testActivity(1, at(10, 0), at(11, 5), ""),
+				// shares the 11:00 row with the first one on a grid of 15 minutes, not on a grid of 5
+				testActivity(2, at(11, 10), at(12, 0), ""),
+			},
+		}},
+	}}
+	anagraphics := &parser.OutputAnagraphics{Rooms: map[string]parser.Room{"aula": {Code: "aula"}}}
+
+	slotsPerStep := map[int]int{15: 2, 5: 1}
+	for step, expectedSlots := range slotsPerStep {
+		ctx := config.WorkflowContext{Config: config.WorkflowContextConfig{GridStep: step}}
+		out := AggregateByRooomGroupSlotInRoom(ctx, days, anagraphics)
+		assert.Len(t, out[0].RoomsSchedule[0].Slots, expectedSlots, "grid of %d minutes", step)
+	}
+}
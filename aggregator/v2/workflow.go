@@ -3,6 +3,7 @@ package aggregator
 import (
 	"github.com/fabiofenoglio/excelconv/config"
 	"github.com/fabiofenoglio/excelconv/parser/v2"
+	"github.com/pkg/errors"
 )
 
 func Execute(ctx config.WorkflowContext, rawInput parser.Output) (Output, error) {
@@ -20,10 +21,28 @@ func Execute(ctx config.WorkflowContext, rawInput parser.Output) (Output, error)
 
 	daysWithRoomsAndGroupingSlots := AggregateByRooomGroupSlotInRoom(ctx, daysWithRoomsAndGrouping, rawInput.Anagraphics)
 
+	daysWithRoomsAndGroupingSlots = OptimizeSlotPlacement(ctx, daysWithRoomsAndGroupingSlots, rawInput.Anagraphics)
+
+	daysWithRoomsAndGroupingSlots = BuildShuttleTimetables(ctx, daysWithRoomsAndGroupingSlots, rawInput.Anagraphics)
+
+	daysWithRoomsAndGroupingSlots = AssignLunchPlans(ctx, daysWithRoomsAndGroupingSlots, rawInput.LunchPlans)
+
 	commonData = ExtractCommonDataFinal(ctx, commonData, daysWithRoomsAndGroupingSlots)
 
-	return Output{
+	finance, err := BuildFinanceSummary(ctx, rowsWithCompetenceDate, rawInput.Anagraphics)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nel calcolo degli incassi")
+	}
+
+	out, err := ApplyPostAggregationRules(ctx, Output{
 		CommonData: commonData,
 		Days:       daysWithRoomsAndGroupingSlots,
-	}, nil
+		Finance:    finance,
+		Changes:    rawInput.Changes,
+	}, rawInput.Anagraphics)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nell'applicazione delle regole post aggregazione")
+	}
+
+	return out, nil
 }
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Args struct {
 	//nolint:staticcheck
 	Format string `short:"f" long:"format" description:"The desired output format" choice:"excel" choice:"json" default:"excel"`
@@ -12,8 +14,71 @@ type Args struct {
 
 	Debug bool `long:"debug" description:"Enable debug mode"`
 
+	Config string `long:"config" description:"Optional JSON configuration file"`
+
+	EnableRules []string `long:"enable-rule" description:"Code of a rule to enable (can be repeated)"`
+
+	DisableRules []string `long:"disable-rule" description:"Code of a rule to disable (can be repeated)"`
+
+	SuppressWarnings []string `long:"suppress-warning" description:"Code of a warning to suppress (can be repeated)"`
+
+	//nolint:staticcheck
+	FailOn string `long:"fail-on" description:"Exit with an error when there are warnings of at least the given severity" choice:"info" choice:"warning" choice:"error"`
+
+	Availability string `long:"availability" description:"Optional file with the operators' absences and working hours"`
+
+	ActivityCatalog string `long:"activity-catalog" description:"Optional JSON file with the catalog of the known activities"`
+
+	SchoolRegistry string `long:"school-registry" description:"Optional JSON file with the registry of the known schools"`
+
+	OperatorColors string `long:"operator-colors" description:"Optional JSON file where the colours of the operators not in the registry are kept from run to run"`
+
+	SchoolDeduplicationThreshold float64 `long:"school-dedup-threshold" description:"Minimum similarity (0 to 1) for two school names to be merged, 0 to disable" default:"0.95"`
+
+	ParkingCapacity int `long:"parking-capacity" description:"Number of buses the parking can host at the same time (not limited when not set)"`
+
+	LunchSeats int `long:"lunch-seats" description:"Number of seats available in each lunch turn, the turns are not checked for overbooking when not set"`
+
+	NormalizeActivityNames bool `long:"normalize-activity-names" description:"Merge activity names that look like typos of each other"`
+
+	NormalizationThreshold float64 `long:"normalize-threshold" description:"Minimum similarity (0 to 1) for two activity names to be merged" default:"0.9"`
+
+	ProtectedActivityNames []string `long:"protect-activity-name" description:"Activity name that must never be rewritten (can be repeated)"`
+
+	SuggestOperators bool `long:"suggest-operators" description:"Propose an operator for the activities that have none"`
+
+	TimeZone string `long:"time-zone" description:"Time zone of the times in the input file, ex. Europe/Rome (overrides the default)"`
+
+	CompetenceDayCutoff *int `long:"competence-day-cutoff" description:"Hour the day starts at, the activities starting earlier belong to the day before (overrides the default 6)"`
+
+	//nolint:staticcheck
+	GridStep int `long:"grid-step" description:"Minutes of each row of the time grid (overrides the default 15)" choice:"5" choice:"10" choice:"15" choice:"30"`
+
+	//nolint:staticcheck
+	GroupNumberingScope string `long:"group-numbering-scope" description:"Assign the display codes of the visiting groups every day, once per week or once for the whole period, so that a group keeps its code (overrides the default day)" choice:"day" choice:"week" choice:"period"`
+
+	//nolint:staticcheck
+	GroupNumberingScheme string `long:"group-numbering-scheme" description:"Display codes of the visiting groups: school number and class letter, booking code or school abbreviation and class letter (overrides the default sequential)" choice:"sequential" choice:"booking_code" choice:"school_letter"`
+
+	OptimizePlacement bool `long:"optimize-placement" description:"Improve the placement of the activities in the columns of each room, reducing the columns and the moves of operators and groups"`
+
+	OptimizePlacementBudget time.Duration `long:"optimize-placement-budget" description:"Maximum time spent optimizing each room and day" default:"500ms"`
+
+	Previous string `long:"previous" description:"JSON output or layout file of a previous run: the activities are kept in their previous columns where possible"`
+
+	SaveLayout string `long:"save-layout" description:"Save the layout of the activities to the given file, to be used with --previous in the next runs"`
+
+	CompareWith string `long:"compare-with" description:"Previous input file or JSON output: the changed activities are highlighted and listed in a dedicated sheet"`
+
+	ExplainPlacement bool `long:"explain-placement" description:"Explain the placement of each activity in the comments and in dedicated CSV and JSON reports"`
+
+	Seed int64 `long:"seed" description:"Seed for the shuffle of the input rows, to reproduce a previous run (random when not set)"`
+
+	//nolint:staticcheck
+	ExportContacts []string `long:"export-contacts" description:"Export the de-duplicated contacts of the class referents in the given format (can be repeated)" choice:"csv" choice:"vcard"`
+
 	PositionalArgs struct {
-		InputFile string `positional-arg-name:"input-file" required:"yes"`
+		InputFile string `positional-arg-name:"input-file" description:"The file to convert, or diff followed by the previous and the new version to compare" required:"yes"`
 		Rest      []string
 	} `positional-args:"yes"`
 }
@@ -0,0 +1,22 @@
+package config
+
+const (
+	ComboKeepsFull   = "full"
+	ComboKeepsFirst  = "first"
+	ComboKeepsSecond = "second"
+)
+
+// ComboRule splits the activities booked as a combo ("X + Y") on two rows of the same group,
+// one in each of the two rooms.
+type ComboRule struct {
+	// Code identifies the rule, a rule with the same code of a default one replaces it
+	Code string `json:"code"`
+	// Pattern must have two capture groups, for the first and the second part of the name
+	Pattern    string `json:"pattern"`
+	FirstRoom  string `json:"first_room"`
+	SecondRoom string `json:"second_room"`
+	// FirstRoomKeeps and SecondRoomKeeps tell which part of the name each room keeps: "full", "first" or "second"
+	FirstRoomKeeps  string `json:"first_room_keeps"`
+	SecondRoomKeeps string `json:"second_room_keeps"`
+	Disabled        bool   `json:"disabled"`
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// FileConfig is the optional configuration file, in JSON format.
+type FileConfig struct {
+	// Rules enables or disables the rules, by rule code
+	Rules map[string]bool `json:"rules"`
+	// KeywordRules declares additional highlights and warnings
+	KeywordRules []KeywordRule `json:"keyword_rules"`
+	// ComboRules declares additional combo activities to split
+	ComboRules []ComboRule `json:"combo_rules"`
+	// Warnings configures the severity and the suppression of the warnings
+	Warnings WarningsConfig `json:"warnings"`
+	// Lunch configures the seats and the turns of the lunch planning
+	Lunch LunchConfig `json:"lunch"`
+	// PriceList configures the prices of the activities for the revenue summary
+	PriceList PriceListConfig `json:"price_list"`
+	// Calendar configures the time zone of the input and the start of the competence days
+	Calendar CalendarConfig `json:"calendar"`
+	// GridStepMinutes is the number of minutes of each row of the time grid (5, 10, 15 or 30)
+	GridStepMinutes int `json:"grid_step_minutes"`
+	// RoomGrouping overrides the grouping of the activities, by room name or code
+	RoomGrouping map[string]RoomGroupingConfig `json:"room_grouping"`
+	// GroupNumbering configures the display codes of the visiting groups
+	GroupNumbering GroupNumberingConfig `json:"group_numbering"`
+	// OperatorSkills restricts the rooms, activity types and languages of the operators, by operator name or code
+	OperatorSkills map[string]OperatorSkillsConfig `json:"operator_skills"`
+}
+
+func LoadFileConfig(path string) (FileConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return FileConfig{}, errors.Wrapf(err, "impossibile leggere il file di configurazione %s", path)
+	}
+
+	var out FileConfig
+	if err := json.Unmarshal(content, &out); err != nil {
+		return FileConfig{}, errors.Wrapf(err, "il file di configurazione %s non è valido", path)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DefaultGridStepMinutes is the step of the time grid when not configured
+const DefaultGridStepMinutes = 15
+
+var allowedGridSteps = []int{5, 10, 15, 30}
+
+// ValidateGridStep checks that the step divides the hour in rows the output can show.
+func ValidateGridStep(minutes int) error {
+	for _, allowed := range allowedGridSteps {
+		if minutes == allowed {
+			return nil
+		}
+	}
+	return errors.Errorf("il passo della griglia oraria deve essere di 5, 10, 15 o 30 minuti e non %d", minutes)
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+// GroupNumberingScope is the span of days the display codes of the visiting groups are assigned for.
+type GroupNumberingScope string
+
+const (
+	GroupNumberingPerDay    GroupNumberingScope = "day"
+	GroupNumberingPerWeek   GroupNumberingScope = "week"
+	GroupNumberingPerPeriod GroupNumberingScope = "period"
+)
+
+// GroupNumberingScheme is the format of the display codes of the visiting groups.
+type GroupNumberingScheme string
+
+const (
+	// GroupNumberingSequential numbers the schools and gives a letter to each of their classes, ex. 2-b
+	GroupNumberingSequential GroupNumberingScheme = "sequential"
+	// GroupNumberingBookingCode shows the booking code of the group
+	GroupNumberingBookingCode GroupNumberingScheme = "booking_code"
+	// GroupNumberingSchoolLetter shows an abbreviation of the school and a letter for each class, ex. ICG-b
+	GroupNumberingSchoolLetter GroupNumberingScheme = "school_letter"
+)
+
+// GroupNumberingConfig configures the display codes of the visiting groups.
+type GroupNumberingConfig struct {
+	// Scope is day, week or period, the codes are assigned every day when empty
+	Scope GroupNumberingScope `json:"scope"`
+	// Scheme is sequential, booking_code or school_letter, sequential when empty
+	Scheme GroupNumberingScheme `json:"scheme"`
+}
+
+func (c GroupNumberingConfig) Validate() error {
+	switch c.Scope {
+	case "", GroupNumberingPerDay, GroupNumberingPerWeek, GroupNumberingPerPeriod:
+	default:
+		return errors.Errorf("l'ambito della numerazione dei gruppi '%s' non è valido, usare day, week o period", c.Scope)
+	}
+	switch c.Scheme {
+	case "", GroupNumberingSequential, GroupNumberingBookingCode, GroupNumberingSchoolLetter:
+	default:
+		return errors.Errorf("lo schema della numerazione dei gruppi '%s' non è valido, usare sequential, booking_code o school_letter", c.Scheme)
+	}
+	return nil
+}
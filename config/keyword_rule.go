@@ -0,0 +1,27 @@
+package config
+
+const (
+	KeywordRuleKindHighlight = "highlight"
+	KeywordRuleKindWarning   = "warning"
+)
+
+// KeywordRule is a highlight or warning rule declared in the configuration file.
+// A row matches when one of the fields matches the regex or contains one of the keywords,
+// and does not match the exclude regex.
+type KeywordRule struct {
+	// Code is the code of the resulting highlight or warning, a rule with the same code of a default one replaces it
+	Code string `json:"code"`
+	// Kind is either "highlight" or "warning"
+	Kind string `json:"kind"`
+	// Fields are the names of the input columns to look at
+	Fields   []string `json:"fields"`
+	Regex    string   `json:"regex"`
+	Keywords []string `json:"keywords"`
+	Exclude  string   `json:"exclude"`
+	// Message may contain the {valore} placeholder, replaced with the matching value
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	// Color is the border colour of the highlighted cells
+	Color    string `json:"color"`
+	Disabled bool   `json:"disabled"`
+}
@@ -0,0 +1,9 @@
+package config
+
+// LunchConfig configures the lunch planning.
+type LunchConfig struct {
+	// Seats is the number of seats available in each turn, 0 to not check the overbooking.
+	Seats int `json:"seats"`
+	// Turns are the lunch turns in the "HH:MM-HH:MM" format, no turn is proposed for the lunches without a time when empty
+	Turns []string `json:"turns"`
+}
@@ -0,0 +1,11 @@
+package config
+
+// OperatorSkillsConfig restricts what an operator is qualified for. An empty list means no restriction.
+type OperatorSkillsConfig struct {
+	// Rooms are the names or codes of the rooms the operator can work in
+	Rooms []string `json:"rooms"`
+	// ActivityTypes are the names of the activity types the operator can run
+	ActivityTypes []string `json:"activity_types"`
+	// Languages are the languages spoken besides italian (ex. "en" or "inglese")
+	Languages []string `json:"languages"`
+}
@@ -0,0 +1,14 @@
+package config
+
+// PriceListConfig configures the prices used to compute the expected revenue.
+type PriceListConfig struct {
+	// ActivityTypes are the prices in euro for each paying participant, by activity type name
+	ActivityTypes map[string]float64 `json:"activity_types"`
+	// Default is the price in euro of the activity types not in the list, 0 if they are not priced
+	Default float64 `json:"default"`
+}
+
+// IsConfigured tells whether any price is set, the revenue is not reported otherwise.
+func (c PriceListConfig) IsConfigured() bool {
+	return len(c.ActivityTypes) > 0 || c.Default > 0
+}
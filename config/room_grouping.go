@@ -0,0 +1,38 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+// RoomGroupingConfig overrides the grouping of the activities of a room.
+type RoomGroupingConfig struct {
+	// Policy is none, same_time, same_time_activity or overlap
+	Policy string `json:"policy"`
+	// ToleranceMinutes is the maximum difference of the start and end times for the overlap policy,
+	// the default one when not set
+	ToleranceMinutes *int `json:"tolerance_minutes"`
+}
+
+// Resolve validates the policy and the tolerance.
+func (c RoomGroupingConfig) Resolve() (database.GroupingPolicy, time.Duration, error) {
+	policy, err := database.ParseGroupingPolicy(c.Policy)
+	if err != nil {
+		return "", 0, err
+	}
+
+	tolerance := time.Duration(0)
+	if policy == database.GroupingOverlap {
+		tolerance = database.DefaultGroupingTolerance
+		if c.ToleranceMinutes != nil {
+			if *c.ToleranceMinutes < 0 || *c.ToleranceMinutes > 60 {
+				return "", 0, errors.Errorf("la tolleranza del raggruppamento deve essere compresa tra 0 e 60 minuti e non %d", *c.ToleranceMinutes)
+			}
+			tolerance = time.Duration(*c.ToleranceMinutes) * time.Minute
+		}
+	}
+	return policy, tolerance, nil
+}
@@ -3,10 +3,15 @@ package config
 import (
 	"time"
 	_ "time/tzdata"
+
+	"github.com/pkg/errors"
 )
 
 const (
 	fixedTimeZoneName = "Europe/Rome"
+
+	// DefaultCompetenceDayCutoffHour is the hour a competence day starts at when not configured
+	DefaultCompetenceDayCutoffHour = 6
 )
 
 var (
@@ -21,6 +26,34 @@ func init() {
 	}
 }
 
+// TimeZone is the default time zone of the input.
 func TimeZone() *time.Location {
 	return timeZone
 }
+
+// CalendarConfig configures the time zone of the input and the start of the competence days.
+type CalendarConfig struct {
+	// TimeZone is the IANA name of the time zone of the input times, ex. "Europe/Rome", the default one when empty
+	TimeZone string `json:"time_zone"`
+	// CompetenceDayCutoffHour is the hour a competence day starts at: the activities starting earlier belong to
+	// the day before. The default one is used when not set
+	CompetenceDayCutoffHour *int `json:"competence_day_cutoff_hour"`
+}
+
+// Resolve loads the time zone and validates the cutoff hour.
+func (c CalendarConfig) Resolve() (*time.Location, *int, error) {
+	var location *time.Location
+	if c.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "il fuso orario '%s' non è valido", c.TimeZone)
+		}
+	}
+
+	if c.CompetenceDayCutoffHour != nil && (*c.CompetenceDayCutoffHour < 0 || *c.CompetenceDayCutoffHour > 23) {
+		return nil, nil, errors.Errorf("l'ora di inizio della giornata deve essere compresa tra 0 e 23 e non %d", *c.CompetenceDayCutoffHour)
+	}
+
+	return location, c.CompetenceDayCutoffHour, nil
+}
@@ -0,0 +1,10 @@
+package config
+
+type WarningsConfig struct {
+	// Suppress lists the codes of the warnings never to be emitted
+	Suppress []string `json:"suppress"`
+	// SuppressByBookingCode lists the codes of the warnings not to be emitted for a given booking code
+	SuppressByBookingCode map[string][]string `json:"suppress_by_booking_code"`
+	// Severities overrides the severity (info, warning or error) of the warnings, by warning code
+	Severities map[string]string `json:"severities"`
+}
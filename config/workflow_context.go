@@ -2,8 +2,11 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 type WorkflowContext struct {
@@ -15,6 +18,81 @@ type WorkflowContext struct {
 type WorkflowContextConfig struct {
 	EnableMissingOperatorsWarning bool
 	EnableUnconfirmedHighlight    bool
+	EnableOperatorSuggestions     bool
+	ActivityNameNormalization     ActivityNameNormalizationConfig
+	// SchoolDeduplicationThreshold is the minimum similarity (0 to 1) for two schools to be merged, 0 to disable
+	SchoolDeduplicationThreshold float64
+	// ParkingCapacity is the number of buses the parking can host, 0 if not limited
+	ParkingCapacity int
+	Lunch           LunchConfig
+	PriceList       PriceListConfig
+	// OperatorColors are the colours persisted for the operators not in the registry, by operator code
+	OperatorColors map[string]string
+	// Rules overrides the default enablement of the rules, by rule code
+	Rules map[string]bool
+	// KeywordRules are the highlight and warning rules declared in the configuration file
+	KeywordRules []KeywordRule
+	// ComboRules are the combo activities declared in the configuration file
+	ComboRules []ComboRule
+	Warnings   WarningsConfig
+	// TimeZone is the time zone of the input times, nil to use the default one
+	TimeZone *time.Location
+	// CompetenceDayCutoffHour is the hour a competence day starts at, nil to use the default one
+	CompetenceDayCutoffHour *int
+	// GridStep is the number of minutes of each row of the time grid, 0 to use the default
+	GridStep int
+	// RoomGrouping overrides the grouping of the activities, by room name or code
+	RoomGrouping map[string]RoomGroupingConfig
+	// GroupNumbering configures the display codes of the visiting groups
+	GroupNumbering GroupNumberingConfig
+	// OperatorSkills restricts the rooms, activity types and languages of the operators, by operator name or code
+	OperatorSkills map[string]OperatorSkillsConfig
+	// PlacementOptimizationBudget is the time the slot placement optimizer can spend on each room and day, 0 to disable it
+	PlacementOptimizationBudget time.Duration
+	// PreviousLayout is the layout of a previous run to keep the activities in their slots, nil if not given
+	PreviousLayout *database.Layout
+	// ExplainPlacement records the score of each candidate slot of the activities
+	ExplainPlacement bool
+	// Seed initializes the shuffle of the input rows, so that a run can be reproduced
+	Seed int64
+}
+
+type ActivityNameNormalizationConfig struct {
+	// Threshold is the minimum similarity (0 to 1) for two names to be merged
+	Threshold float64
+	// ProtectedNames are never rewritten
+	ProtectedNames []string
+}
+
+func (c WorkflowContextConfig) IsRuleEnabled(code string, enabledByDefault bool) bool {
+	if enabled, overridden := c.Rules[code]; overridden {
+		return enabled
+	}
+	return enabledByDefault
+}
+
+// Location is the time zone of the input times.
+func (c WorkflowContextConfig) Location() *time.Location {
+	if c.TimeZone != nil {
+		return c.TimeZone
+	}
+	return TimeZone()
+}
+
+// CompetenceDayCutoff is the hour a competence day starts at: the activities starting earlier belong to the day before.
+func (c WorkflowContextConfig) CompetenceDayCutoff() int {
+	if c.CompetenceDayCutoffHour != nil {
+		return *c.CompetenceDayCutoffHour
+	}
+	return DefaultCompetenceDayCutoffHour
+}
+
+// GridStepMinutes is the number of minutes of each row of the time grid.
+func (c WorkflowContextConfig) GridStepMinutes() int {
+	if c.GridStep > 0 {
+		return c.GridStep
+	}
+	return DefaultGridStepMinutes
 }
 
 func (c *WorkflowContext) ForContext(ctx context.Context) WorkflowContext {
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	knownActivityMap      map[string]KnownActivity
+	knownActivityAliasMap map[string]string
+)
+
+func init() {
+	ResetKnownActivities()
+}
+
+// ResetKnownActivities empties the activity catalog.
+func ResetKnownActivities() {
+	knownActivityMap = make(map[string]KnownActivity)
+	knownActivityAliasMap = make(map[string]string)
+}
+
+type activityCatalogEntry struct {
+	Code            string   `json:"code"`
+	Name            string   `json:"name"`
+	Aliases         []string `json:"aliases"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Rooms           []string `json:"rooms"`
+	SchoolLevels    []string `json:"school_levels"`
+}
+
+// LoadKnownActivities reads the activity catalog from a JSON file containing a list of entries.
+func LoadKnownActivities(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "impossibile leggere il catalogo delle attività %s", path)
+	}
+
+	var entries []activityCatalogEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return errors.Wrapf(err, "il catalogo delle attività %s non è valido", path)
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" {
+			return errors.Errorf("il catalogo delle attività contiene una voce senza nome in posizione %d", i+1)
+		}
+		code := entry.Code
+		if code == "" {
+			code = entry.Name
+		}
+		registerKnownActivities(KnownActivity{
+			Code:             lookupKey(code),
+			Name:             strings.TrimSpace(entry.Name),
+			Aliases:          entry.Aliases,
+			ExpectedDuration: time.Duration(entry.DurationMinutes) * time.Minute,
+			Rooms:            entry.Rooms,
+			SchoolLevels:     entry.SchoolLevels,
+		})
+	}
+
+	return nil
+}
+
+func registerKnownActivities(o ...KnownActivity) {
+	for _, obj := range o {
+		if obj.Code == "" {
+			panic("known activity must have a code")
+		}
+		knownActivityMap[obj.Code] = obj
+
+		// the canonical name is always a valid alias
+		knownActivityAliasMap[lookupKey(obj.Name)] = obj.Code
+		for _, alias := range obj.Aliases {
+			knownActivityAliasMap[lookupKey(alias)] = obj.Code
+		}
+	}
+}
+
+// GetKnownActivity looks up an activity in the catalog by code, name or alias.
+// Case, spaces and punctuation are ignored.
+func GetKnownActivity(name string) (KnownActivity, bool) {
+	key := lookupKey(name)
+	if res, ok := knownActivityMap[key]; ok {
+		return res, true
+	}
+	if aliasOf, isAlias := knownActivityAliasMap[key]; isAlias {
+		res, ok := knownActivityMap[aliasOf]
+		return res, ok
+	}
+	return KnownActivity{}, false
+}
+
+func HasKnownActivities() bool {
+	return len(knownActivityMap) > 0
+}
+
+func lookupKey(raw string) string {
+	var b strings.Builder
+	b.Grow(len(raw))
+	for _, ch := range strings.ToLower(raw) {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
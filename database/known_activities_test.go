@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKnownActivity(t *testing.T) {
+	registerKnownActivities(KnownActivity{
+		Code:    "sistemasolare",
+		Name:    "Sistema Solare",
+		Aliases: []string{"Il sistema solare"},
+	})
+
+	type testCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := []testCase{
+		{"Sistema Solare", "sistemasolare"},
+		{"sistema solare ", "sistemasolare"},
+		{"SISTEMA-SOLARE", "sistemasolare"},
+		{"il sistema solare", "sistemasolare"},
+		{"sistemasolare", "sistemasolare"},
+		{"sistema lunare", ""},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			found, ok := GetKnownActivity(tc.input)
+			assert.Equal(t, tc.expected != "", ok)
+			assert.Equal(t, tc.expected, found.Code)
+		})
+	}
+}
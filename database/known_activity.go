@@ -0,0 +1,17 @@
+package database
+
+import "time"
+
+type KnownActivity struct {
+	Code string
+	Name string
+
+	Aliases []string
+
+	// ExpectedDuration is the usual duration of the activity, zero if not known
+	ExpectedDuration time.Duration
+	// Rooms are the codes of the rooms where the activity can take place, empty if any room is fine
+	Rooms []string
+	// SchoolLevels are the school levels the activity is meant for, empty if suitable for any
+	SchoolLevels []string
+}
@@ -7,4 +7,17 @@ type KnownOperator struct {
 	BackgroundColor string
 
 	Aliases []string
+
+	Skills OperatorSkills
+}
+
+// OperatorSkills lists what an operator is qualified for.
+// An empty list means no restriction on that dimension.
+type OperatorSkills struct {
+	// Rooms are the codes of the rooms the operator can work in
+	Rooms []string
+	// ActivityTypes are the codes of the activity types the operator can run
+	ActivityTypes []string
+	// Languages are the codes of the languages spoken besides italian (ex. "en")
+	Languages []string
 }
@@ -1,5 +1,7 @@
 package database
 
+import "time"
+
 type KnownRoom struct {
 	Code                 string
 	Name                 string
@@ -16,4 +18,9 @@ type KnownRoom struct {
 	GroupActivities                bool
 	AlwaysShow                     bool
 	DoesNotRequireOperator         bool
+
+	// GroupingPolicy is the grouping of the activities when GroupActivities is set, same time when empty
+	GroupingPolicy GroupingPolicy
+	// GroupingTolerance is the maximum difference of the start and end times for the overlap policy
+	GroupingTolerance time.Duration
 }
@@ -2,6 +2,9 @@ package database
 
 import "strings"
 
+// LunchRoomCode is the room of the lunches, the only activities that can be booked without a time
+const LunchRoomCode = "pranzo"
+
 var (
 	knownRoomMap      map[string]KnownRoom
 	knownRoomAliasMap map[string]string
@@ -17,7 +20,6 @@ func init() {
 		BackgroundColor:        "#D9E9FA",
 		Slots:                  5,
 		PreferredOrder:         -9,
-		Hide:                   true,
 		DoesNotRequireOperator: true,
 	}, KnownRoom{
 		Code:                   "parcheggio",
@@ -33,7 +35,7 @@ func init() {
 		PreferredOrder:  -8,
 		AlwaysShow:      true,
 	}, KnownRoom{
-		Code:                 "pranzo",
+		Code:                 LunchRoomCode,
 		Name:                 "Pranzo",
 		BackgroundColor:      "#F1D3F5",
 		Slots:                4,
@@ -50,6 +52,8 @@ func init() {
 		ShowActivityNamesAsAnnotations: false,
 		ShowActivityNamesInside:        true,
 		GroupActivities:                true,
+		GroupingPolicy:                 GroupingOverlap,
+		GroupingTolerance:              DefaultGroupingTolerance,
 		AlwaysShow:                     true,
 	}, KnownRoom{
 		Code:                           "aula1",
@@ -61,6 +65,7 @@ func init() {
 		ShowActivityNamesAsAnnotations: false,
 		ShowActivityNamesInside:        true,
 		GroupActivities:                true,
+		GroupingPolicy:                 GroupingSameTimeAndActivity,
 		AlwaysShow:                     true,
 	}, KnownRoom{
 		Code:                           "aula2",
@@ -72,6 +77,7 @@ func init() {
 		ShowActivityNamesAsAnnotations: false,
 		ShowActivityNamesInside:        true,
 		GroupActivities:                true,
+		GroupingPolicy:                 GroupingSameTimeAndActivity,
 		AlwaysShow:                     true,
 	}, KnownRoom{
 		Code:                           "terrazza",
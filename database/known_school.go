@@ -0,0 +1,10 @@
+package database
+
+type KnownSchool struct {
+	Code      string
+	Name      string
+	ShortName string
+	City      string
+
+	Aliases []string
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	knownSchoolMap      map[string]KnownSchool
+	knownSchoolAliasMap map[string]string
+)
+
+func init() {
+	ResetKnownSchools()
+}
+
+// ResetKnownSchools empties the school registry.
+func ResetKnownSchools() {
+	knownSchoolMap = make(map[string]KnownSchool)
+	knownSchoolAliasMap = make(map[string]string)
+}
+
+type schoolRegistryEntry struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	ShortName string   `json:"short_name"`
+	City      string   `json:"city"`
+	Aliases   []string `json:"aliases"`
+}
+
+// LoadKnownSchools reads the school registry from a JSON file containing a list of entries.
+func LoadKnownSchools(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "impossibile leggere l'anagrafica delle scuole %s", path)
+	}
+
+	var entries []schoolRegistryEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return errors.Wrapf(err, "l'anagrafica delle scuole %s non è valida", path)
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" {
+			return errors.Errorf("l'anagrafica delle scuole contiene una voce senza nome in posizione %d", i+1)
+		}
+		code := entry.Code
+		if code == "" {
+			code = entry.Name
+		}
+		registerKnownSchools(KnownSchool{
+			Code:      lookupKey(code),
+			Name:      strings.TrimSpace(entry.Name),
+			ShortName: strings.TrimSpace(entry.ShortName),
+			City:      strings.TrimSpace(entry.City),
+			Aliases:   entry.Aliases,
+		})
+	}
+
+	return nil
+}
+
+func registerKnownSchools(o ...KnownSchool) {
+	for _, obj := range o {
+		if obj.Code == "" {
+			panic("known school must have a code")
+		}
+		knownSchoolMap[obj.Code] = obj
+
+		knownSchoolAliasMap[lookupKey(obj.Name)] = obj.Code
+		if obj.ShortName != "" {
+			knownSchoolAliasMap[lookupKey(obj.ShortName)] = obj.Code
+		}
+		for _, alias := range obj.Aliases {
+			knownSchoolAliasMap[lookupKey(alias)] = obj.Code
+		}
+	}
+}
+
+// GetKnownSchool looks up a school in the registry by code, name, short name or alias.
+// Case, spaces and punctuation are ignored.
+func GetKnownSchool(name string) (KnownSchool, bool) {
+	key := lookupKey(name)
+	if key == "" {
+		return KnownSchool{}, false
+	}
+	if res, ok := knownSchoolMap[key]; ok {
+		return res, true
+	}
+	if aliasOf, isAlias := knownSchoolAliasMap[key]; isAlias {
+		res, ok := knownSchoolMap[aliasOf]
+		return res, ok
+	}
+	return KnownSchool{}, false
+}
+
+// GetKnownSchools returns all the schools in the registry, sorted by code.
+func GetKnownSchools() []KnownSchool {
+	out := make([]KnownSchool, 0, len(knownSchoolMap))
+	for _, s := range knownSchoolMap {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKnownSchool(t *testing.T) {
+	registerKnownSchools(KnownSchool{
+		Code:      "icrivoli",
+		Name:      "I.C. Rivoli",
+		ShortName: "Rivoli",
+		City:      "Rivoli",
+		Aliases:   []string{"Istituto Comprensivo di Rivoli"},
+	})
+
+	type testCase struct {
+		input    string
+		expected string
+	}
+
+	testCases := []testCase{
+		{"I.C. Rivoli", "icrivoli"},
+		{"IC RIVOLI", "icrivoli"},
+		{"ic-rivoli ", "icrivoli"},
+		{"Rivoli", "icrivoli"},
+		{"istituto comprensivo di rivoli", "icrivoli"},
+		{"I.C. Rivalta", ""},
+		{"", ""},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			found, ok := GetKnownSchool(tc.input)
+			assert.Equal(t, tc.expected != "", ok)
+			assert.Equal(t, tc.expected, found.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Layout stores the slot of each activity of a previous run, so that a new run can keep the activities where they were.
+type Layout struct {
+	Placements []LayoutPlacement `json:"placements"`
+
+	index map[string]int
+}
+
+// LayoutPlacement is an activity placed in a slot of a room, identified by its booking codes, room and start time.
+type LayoutPlacement struct {
+	RoomCode     string    `json:"room_code"`
+	BookingCodes []string  `json:"booking_codes"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	SlotIndex    int       `json:"slot_index"`
+}
+
+func NewLayout(placements []LayoutPlacement) *Layout {
+	l := &Layout{Placements: placements}
+	l.index = make(map[string]int)
+	for _, placement := range placements {
+		for _, bookingCode := range placement.BookingCodes {
+			key := layoutKey(placement.RoomCode, bookingCode, placement.StartTime)
+			if _, ok := l.index[key]; !ok {
+				l.index[key] = placement.SlotIndex
+			}
+		}
+	}
+	return l
+}
+
+// SlotOf returns the slot where the booking was placed in the room at the given start time.
+func (l *Layout) SlotOf(roomCode string, bookingCode string, startTime time.Time) (int, bool) {
+	if l == nil || bookingCode == "" {
+		return 0, false
+	}
+	slot, ok := l.index[layoutKey(roomCode, bookingCode, startTime)]
+	return slot, ok
+}
+
+func layoutKey(roomCode string, bookingCode string, startTime time.Time) string {
+	return roomCode + "|" + strings.ToLower(strings.TrimSpace(bookingCode)) + "|" + startTime.UTC().Format(time.RFC3339)
+}
+
+// previousJSONOutput holds the few fields of the JSON output needed to rebuild its layout.
+type previousJSONOutput struct {
+	Days []struct {
+		RoomsSchedule []struct {
+			RoomCode string
+			Slots    []struct {
+				SlotIndex         int
+				GroupedActivities []struct {
+					StartingSlotIndex int
+					StartTime         time.Time
+					EndTime           time.Time
+					Rows              []struct {
+						BookingCode string
+					}
+				}
+			}
+		}
+	}
+}
+
+// LoadLayout reads a layout saved with SaveLayout or rebuilds it from the JSON output of a previous run.
+func LoadLayout(path string) (*Layout, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "impossibile leggere il file della disposizione precedente %s", path)
+	}
+
+	stored := Layout{}
+	if err := json.Unmarshal(content, &stored); err != nil {
+		return nil, errors.Wrapf(err, "il file della disposizione precedente %s non è valido", path)
+	}
+	if len(stored.Placements) > 0 {
+		return NewLayout(stored.Placements), nil
+	}
+
+	output := previousJSONOutput{}
+	if err := json.Unmarshal(content, &output); err != nil {
+		return nil, errors.Wrapf(err, "il file della disposizione precedente %s non è valido", path)
+	}
+
+	placements := make([]LayoutPlacement, 0)
+	for _, day := range output.Days {
+		for _, room := range day.RoomsSchedule {
+			for _, slot := range room.Slots {
+				for _, act := range slot.GroupedActivities {
+					// an activity is repeated in every slot it spans
+					if act.StartingSlotIndex != slot.SlotIndex {
+						continue
+					}
+					placement := LayoutPlacement{
+						RoomCode:  room.RoomCode,
+						StartTime: act.StartTime,
+						EndTime:   act.EndTime,
+						SlotIndex: act.StartingSlotIndex,
+					}
+					for _, row := range act.Rows {
+						if row.BookingCode != "" {
+							placement.BookingCodes = append(placement.BookingCodes, row.BookingCode)
+						}
+					}
+					placements = append(placements, placement)
+				}
+			}
+		}
+	}
+
+	if len(placements) == 0 {
+		return nil, errors.Errorf("il file della disposizione precedente %s non contiene nessuna attività", path)
+	}
+	return NewLayout(placements), nil
+}
+
+func SaveLayout(path string, layout *Layout) error {
+	content, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "errore nella serializzazione della disposizione")
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return errors.Wrapf(err, "impossibile salvare il file della disposizione %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutPersistence(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 3, 10, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	_, err := LoadLayout(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "layout.json")
+	assert.NoError(t, SaveLayout(path, NewLayout([]LayoutPlacement{
+		{RoomCode: "museo", BookingCodes: []string{"B001", "B002"}, StartTime: start, EndTime: start.Add(time.Hour), SlotIndex: 2},
+	})))
+
+	layout, err := LoadLayout(path)
+	if assert.NoError(t, err) {
+		slot, ok := layout.SlotOf("museo", "b002", start.UTC())
+		assert.True(t, ok)
+		assert.Equal(t, 2, slot)
+
+		_, ok = layout.SlotOf("museo", "B001", start.Add(time.Hour))
+		assert.False(t, ok)
+		_, ok = layout.SlotOf("planetario", "B001", start)
+		assert.False(t, ok)
+	}
+
+	// the JSON output of a previous run, where an activity is repeated in every slot it spans
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Days": [{"RoomsSchedule": [{"RoomCode": "museo", "Slots": [
+		{"SlotIndex": 0, "GroupedActivities": null},
+		{"SlotIndex": 1, "GroupedActivities": [{"StartingSlotIndex": 1, "StartTime": "2025-03-10T09:30:00+01:00",
+			"EndTime": "2025-03-10T10:30:00+01:00", "Rows": [{"BookingCode": "B001"}, {"BookingCode": "B002"}]}]},
+		{"SlotIndex": 2, "GroupedActivities": [{"StartingSlotIndex": 1, "StartTime": "2025-03-10T09:30:00+01:00",
+			"EndTime": "2025-03-10T10:30:00+01:00", "Rows": [{"BookingCode": "B001"}, {"BookingCode": "B002"}]}]}
+	]}]}]}`), 0644))
+
+	layout, err = LoadLayout(path)
+	if assert.NoError(t, err) {
+		assert.Len(t, layout.Placements, 1)
+		slot, ok := layout.SlotOf("museo", "B002", start)
+		assert.True(t, ok)
+		assert.Equal(t, 1, slot)
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Days": []}`), 0644))
+	_, err = LoadLayout(path)
+	assert.Error(t, err)
+}
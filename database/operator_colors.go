@@ -0,0 +1,47 @@
+package database
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// LoadOperatorColors reads the colours assigned in the previous runs to the operators not in the registry,
+// from a JSON object mapping the operator code to the colour. A missing file is not an error.
+func LoadOperatorColors(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]string), nil
+		}
+		return nil, errors.Wrapf(err, "impossibile leggere il file dei colori degli educatori %s", path)
+	}
+
+	out := make(map[string]string)
+	if err := json.Unmarshal(content, &out); err != nil {
+		return nil, errors.Wrapf(err, "il file dei colori degli educatori %s non è valido", path)
+	}
+
+	for code, color := range out {
+		if !hexColorRegex.MatchString(color) {
+			return nil, errors.Errorf("il colore '%s' dell'educatore %s non è valido, atteso #RRGGBB", color, code)
+		}
+	}
+
+	return out, nil
+}
+
+func SaveOperatorColors(path string, colors map[string]string) error {
+	content, err := json.MarshalIndent(colors, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "errore nella serializzazione dei colori degli educatori")
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return errors.Wrapf(err, "impossibile salvare il file dei colori degli educatori %s", path)
+	}
+	return nil
+}
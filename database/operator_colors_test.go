@@ -0,0 +1,27 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperatorColorsPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "colors.json")
+
+	colors, err := LoadOperatorColors(path)
+	assert.NoError(t, err)
+	assert.Empty(t, colors)
+
+	assert.NoError(t, SaveOperatorColors(path, map[string]string{"mario": "#123456", "anna": "#ABCDEF"}))
+
+	colors, err = LoadOperatorColors(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"mario": "#123456", "anna": "#ABCDEF"}, colors)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"mario": "red"}`), 0644))
+	_, err = LoadOperatorColors(path)
+	assert.Error(t, err)
+}
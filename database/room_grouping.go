@@ -0,0 +1,32 @@
+package database
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// GroupingPolicy tells which activities of a room are grouped together and shown as one.
+type GroupingPolicy string
+
+const (
+	// GroupingNone never groups the activities
+	GroupingNone GroupingPolicy = "none"
+	// GroupingSameTime groups the activities with the same start and end time
+	GroupingSameTime GroupingPolicy = "same_time"
+	// GroupingSameTimeAndActivity groups the activities with the same start time, end time and activity
+	GroupingSameTimeAndActivity GroupingPolicy = "same_time_activity"
+	// GroupingOverlap groups the activities whose start and end times differ at most by the tolerance
+	GroupingOverlap GroupingPolicy = "overlap"
+)
+
+// DefaultGroupingTolerance is the tolerance of the overlap policy when not configured
+const DefaultGroupingTolerance = 10 * time.Minute
+
+func ParseGroupingPolicy(raw string) (GroupingPolicy, error) {
+	switch policy := GroupingPolicy(raw); policy {
+	case GroupingNone, GroupingSameTime, GroupingSameTimeAndActivity, GroupingOverlap:
+		return policy, nil
+	}
+	return "", errors.Errorf("la modalità di raggruppamento '%s' non è valida, usare none, same_time, same_time_activity o overlap", raw)
+}
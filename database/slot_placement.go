@@ -9,6 +9,8 @@ type SlotPlacementPreferences struct {
 	PointsForOperatorHasOtherActivitiesInSlot int
 	PointsForGroupHasOtherActivitiesInSlot    int
 	PenaltyForEachOtherActivityInSlot         int
+	// PointsForPreviousSlot is given to the slot of the activity in the previous layout, if any
+	PointsForPreviousSlot int
 }
 
 var (
@@ -21,6 +23,7 @@ var (
 		PointsForOperatorHasOtherActivitiesInSlot: 15,
 		PointsForGroupHasOtherActivitiesInSlot:    40,
 		PenaltyForEachOtherActivityInSlot:         5,
+		PointsForPreviousSlot:                     150,
 	}
 
 	planetarioSlotPlacementPreferences = SlotPlacementPreferences{
@@ -32,5 +35,6 @@ var (
 		PointsForOperatorHasOtherActivitiesInSlot: 15,
 		PointsForGroupHasOtherActivitiesInSlot:    40,
 		PenaltyForEachOtherActivityInSlot:         5,
+		PointsForPreviousSlot:                     150,
 	}
 )
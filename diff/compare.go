@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+type fieldExtractor struct {
+	name  string
+	value func(r Record) string
+}
+
+// comparedFields are the fields listed in the differences, the booking code and the date identify the activity.
+var comparedFields = []fieldExtractor{
+	{"orario", func(r Record) string { return r.StartTime.Format("15:04") + " - " + r.EndTime.Format("15:04") }},
+	{"aula", func(r Record) string { return r.Room }},
+	{"evento", func(r Record) string { return r.Activity }},
+	{"educatore", func(r Record) string { return r.Operator }},
+	{"gruppo", func(r Record) string { return r.Group }},
+	{"paganti", func(r Record) string { return strconv.Itoa(r.NumPaying) }},
+	{"gratuiti", func(r Record) string { return strconv.Itoa(r.NumFree) }},
+	{"accompagnatori", func(r Record) string { return strconv.Itoa(r.NumAccompanying) }},
+	{"confermata", func(r Record) string { return confirmedLabel(r.Confirmed) }},
+	{"nota prenotazione", func(r Record) string { return r.BookingNote }},
+	{"nota operatore", func(r Record) string { return r.OperatorNote }},
+}
+
+func confirmedLabel(confirmed *bool) string {
+	if confirmed == nil {
+		return ""
+	}
+	if *confirmed {
+		return "sì"
+	}
+	return "no"
+}
+
+func bookingDayKey(r Record) string {
+	return strings.ToLower(strings.TrimSpace(r.BookingCode)) + "|" + r.Date.Format("2006-01-02")
+}
+
+func activityKey(r Record) string {
+	return bookingDayKey(r) + "|" + r.StartTime.Format("15:04") + "|" + r.RoomCode
+}
+
+// Compare lists the activities added, removed and modified in the new version of the bookings.
+// The activities are matched by booking code, date, start time and room; the ones left are then matched by booking code
+// and date, preferring the same activity, so that a moved activity is reported as modified.
+func Compare(previous []Record, current []Record) []parser.BookingChange {
+	previousByKey := make(map[string][]int)
+	for i, r := range previous {
+		previousByKey[activityKey(r)] = append(previousByKey[activityKey(r)], i)
+	}
+
+	matched := make([]bool, len(previous))
+	pairs := make(map[int]int)
+	unmatchedCurrent := make([]int, 0)
+
+	for i, r := range current {
+		candidates := previousByKey[activityKey(r)]
+		if len(candidates) == 0 {
+			unmatchedCurrent = append(unmatchedCurrent, i)
+			continue
+		}
+		pairs[i] = candidates[0]
+		matched[candidates[0]] = true
+		previousByKey[activityKey(r)] = candidates[1:]
+	}
+
+	// second pass on the same booking and day
+	leftByBookingDay := make(map[string][]int)
+	for i, r := range previous {
+		if !matched[i] {
+			leftByBookingDay[bookingDayKey(r)] = append(leftByBookingDay[bookingDayKey(r)], i)
+		}
+	}
+	take := func(i int, accept func(Record) bool) bool {
+		candidates := leftByBookingDay[bookingDayKey(current[i])]
+		for n, candidate := range candidates {
+			if accept(previous[candidate]) {
+				pairs[i] = candidate
+				matched[candidate] = true
+				leftByBookingDay[bookingDayKey(current[i])] = append(candidates[:n:n], candidates[n+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	added := make([]int, 0)
+	for _, i := range unmatchedCurrent {
+		if !take(i, func(r Record) bool { return r.ActivityCode == current[i].ActivityCode }) {
+			added = append(added, i)
+		}
+	}
+	stillAdded := make([]int, 0)
+	for _, i := range added {
+		if !take(i, func(Record) bool { return true }) {
+			stillAdded = append(stillAdded, i)
+		}
+	}
+
+	out := make([]parser.BookingChange, 0)
+	for i, r := range current {
+		previousIndex, ok := pairs[i]
+		if !ok {
+			continue
+		}
+		fields := compareFields(previous[previousIndex], r)
+		if len(fields) == 0 {
+			continue
+		}
+		change := changeOf(parser.ChangeModified, r)
+		change.Fields = fields
+		out = append(out, change)
+	}
+	for _, i := range stillAdded {
+		out = append(out, changeOf(parser.ChangeAdded, current[i]))
+	}
+	for i, r := range previous {
+		if !matched[i] {
+			change := changeOf(parser.ChangeRemoved, r)
+			change.RowID = 0
+			out = append(out, change)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.Before(out[j].Date)
+		}
+		if !out[i].StartTime.Equal(out[j].StartTime) {
+			return out[i].StartTime.Before(out[j].StartTime)
+		}
+		return out[i].BookingCode < out[j].BookingCode
+	})
+	return out
+}
+
+func compareFields(previous Record, current Record) []parser.FieldChange {
+	out := make([]parser.FieldChange, 0)
+	for _, field := range comparedFields {
+		oldValue, newValue := field.value(previous), field.value(current)
+		if strings.TrimSpace(oldValue) != strings.TrimSpace(newValue) {
+			out = append(out, parser.FieldChange{Field: field.name, Old: oldValue, New: newValue})
+		}
+	}
+	return out
+}
+
+func changeOf(kind parser.ChangeKind, r Record) parser.BookingChange {
+	return parser.BookingChange{
+		Kind:        kind,
+		BookingCode: r.BookingCode,
+		Date:        r.Date,
+		StartTime:   r.StartTime,
+		EndTime:     r.EndTime,
+		Room:        r.Room,
+		Activity:    r.Activity,
+		Group:       r.Group,
+		RowNumber:   r.RowNumber,
+		RowID:       r.RowID,
+	}
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestCompare(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	record := func(booking string, day, hour int, room, activity, operator string, numPaying int) Record {
+		return Record{
+			BookingCode: booking, Date: at(day, 12), StartTime: at(day, hour), EndTime: at(day, hour+1),
+			RoomCode: room, Room: room, ActivityCode: activity, Activity: activity, Operator: operator, NumPaying: numPaying,
+		}
+	}
+
+	cases := []struct {
+		previous []Record
+		current  []Record
+		expected []parser.BookingChange
+	}{
+		{
+			previous: []Record{record("B001", 10, 9, "museo", "visita", "Mario", 20)},
+			current:  []Record{record("B001", 10, 9, "museo", "visita", "Mario", 20)},
+			expected: []parser.BookingChange{},
+		},
+		{
+			// operator and group size changed
+			previous: []Record{record("B001", 10, 9, "museo", "visita", "Mario", 20)},
+			current:  []Record{record("B001", 10, 9, "museo", "visita", "Anna", 22)},
+			expected: []parser.BookingChange{{
+				Kind: parser.ChangeModified, BookingCode: "B001", Date: at(10, 12), StartTime: at(10, 9), EndTime: at(10, 10),
+				Room: "museo", Activity: "visita",
+				Fields: []parser.FieldChange{
+					{Field: "educatore", Old: "Mario", New: "Anna"},
+					{Field: "paganti", Old: "20", New: "22"},
+				},
+			}},
+		},
+		{
+			// moved to another time and room, matched on the same booking and activity
+			previous: []Record{
+				record("B001", 10, 9, "museo", "visita", "Mario", 20),
+				record("B001", 10, 11, "aula1", "laboratorio", "Mario", 20),
+			},
+			current: []Record{
+				record("B001", 10, 9, "museo", "visita", "Mario", 20),
+				record("B001", 10, 14, "aula2", "laboratorio", "Mario", 20),
+			},
+			expected: []parser.BookingChange{{
+				Kind: parser.ChangeModified, BookingCode: "B001", Date: at(10, 12), StartTime: at(10, 14), EndTime: at(10, 15),
+				Room: "aula2", Activity: "laboratorio",
+				Fields: []parser.FieldChange{
+					{Field: "orario", Old: "11:00 - 12:00", New: "14:00 - 15:00"},
+					{Field: "aula", Old: "aula1", New: "aula2"},
+				},
+			}},
+		},
+		{
+			// another day is not the same activity
+			previous: []Record{record("B001", 10, 9, "museo", "visita", "Mario", 20)},
+			current:  []Record{record("B001", 11, 9, "museo", "visita", "Mario", 20), record("B002", 11, 10, "museo", "visita", "", 10)},
+			expected: []parser.BookingChange{
+				{Kind: parser.ChangeRemoved, BookingCode: "B001", Date: at(10, 12), StartTime: at(10, 9), EndTime: at(10, 10), Room: "museo", Activity: "visita"},
+				{Kind: parser.ChangeAdded, BookingCode: "B001", Date: at(11, 12), StartTime: at(11, 9), EndTime: at(11, 10), Room: "museo", Activity: "visita"},
+				{Kind: parser.ChangeAdded, BookingCode: "B002", Date: at(11, 12), StartTime: at(11, 10), EndTime: at(11, 11), Room: "museo", Activity: "visita"},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, c.expected, Compare(c.previous, c.current))
+		})
+	}
+}
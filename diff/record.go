@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/parser/v2"
+	"github.com/fabiofenoglio/excelconv/reader/v2"
+)
+
+// Record is an activity of a version of the bookings, with the values to compare resolved to their display names
+// so that two runs can be compared even if the codes differ.
+type Record struct {
+	BookingCode     string
+	Date            time.Time
+	StartTime       time.Time
+	EndTime         time.Time
+	RoomCode        string
+	Room            string
+	ActivityCode    string
+	Activity        string
+	Operator        string
+	Group           string
+	NumPaying       int
+	NumFree         int
+	NumAccompanying int
+	Confirmed       *bool
+	BookingNote     string
+	OperatorNote    string
+	RowNumber       uint
+	RowID           int
+}
+
+// FromParserOutput builds the records of the rows of the parser, skipping the placeholders.
+func FromParserOutput(out parser.Output) []Record {
+	records := make([]Record, 0, len(out.Rows))
+	for _, row := range out.Rows {
+		if row.IsPlaceholderNumeroAttivita {
+			continue
+		}
+		records = append(records, recordOf(row.BookingCode, row.Date, row.StartTime, row.EndTime, row.RoomCode,
+			row.ActivityCode, row.OperatorCode, row.VisitingGroupCode, row.Confirmed, row.BookingNote, row.OperatorNote,
+			out.Anagraphics, row.RowNumber, row.ID))
+	}
+	return records
+}
+
+func recordOf(
+	bookingCode string, date, startTime, endTime time.Time,
+	roomCode, activityCode, operatorCode, groupCode string,
+	confirmed *bool, bookingNote, operatorNote string,
+	anagraphics *parser.OutputAnagraphics,
+	rowNumber uint, rowID int,
+) Record {
+	group := anagraphics.VisitingGroups[groupCode]
+	groupLabel := anagraphics.Schools[group.SchoolCode].DisplayName()
+	if class := anagraphics.SchoolClasses[group.SchoolClassCode].FullDescription(); class != "" {
+		groupLabel = strings.TrimSpace(groupLabel + " " + class)
+	}
+
+	activity := anagraphics.Activities[activityCode]
+	activityName := activity.Name
+	if activity.Language != "" && strings.ToLower(activity.Language) != "it" {
+		activityName += " (" + strings.ToUpper(activity.Language) + ")"
+	}
+
+	return Record{
+		BookingCode:     bookingCode,
+		Date:            date,
+		StartTime:       startTime,
+		EndTime:         endTime,
+		RoomCode:        roomCode,
+		Room:            anagraphics.Rooms[roomCode].Name,
+		ActivityCode:    activityCode,
+		Activity:        activityName,
+		Operator:        anagraphics.Operators[operatorCode].Name,
+		Group:           groupLabel,
+		NumPaying:       group.Composition.NumPaying,
+		NumFree:         group.Composition.NumFree,
+		NumAccompanying: group.Composition.NumAccompanying,
+		Confirmed:       confirmed,
+		BookingNote:     bookingNote,
+		OperatorNote:    operatorNote,
+		RowNumber:       rowNumber,
+		RowID:           rowID,
+	}
+}
+
+// previousJSONOutput holds the fields of the JSON output needed to rebuild its records.
+type previousJSONOutput struct {
+	Days []struct {
+		RoomsSchedule []struct {
+			Slots []struct {
+				SlotIndex         int
+				GroupedActivities []struct {
+					StartingSlotIndex int
+					Rows              []struct {
+						BookingCode                 string
+						Date                        time.Time
+						StartTime                   time.Time
+						EndTime                     time.Time
+						BookingNote                 string
+						OperatorNote                string
+						RoomCode                    string
+						OperatorCode                string
+						VisitingGroupCode           string
+						ActivityCode                string
+						Confirmed                   *bool
+						IsPlaceholderNumeroAttivita bool
+					}
+				}
+			}
+		}
+	}
+	Anagraphics *parser.OutputAnagraphics
+}
+
+// FromJSONOutput builds the records of the JSON output of a previous run.
+func FromJSONOutput(content []byte) ([]Record, error) {
+	output := previousJSONOutput{}
+	if err := json.Unmarshal(content, &output); err != nil {
+		return nil, err
+	}
+	if output.Anagraphics == nil {
+		return nil, errors.New("il file non è un output JSON del convertitore")
+	}
+
+	records := make([]Record, 0)
+	for _, day := range output.Days {
+		for _, room := range day.RoomsSchedule {
+			for _, slot := range room.Slots {
+				for _, act := range slot.GroupedActivities {
+					// an activity is repeated in every slot it spans
+					if act.StartingSlotIndex != slot.SlotIndex {
+						continue
+					}
+					for _, row := range act.Rows {
+						if row.IsPlaceholderNumeroAttivita {
+							continue
+						}
+						records = append(records, recordOf(row.BookingCode, row.Date, row.StartTime, row.EndTime, row.RoomCode,
+							row.ActivityCode, row.OperatorCode, row.VisitingGroupCode, row.Confirmed, row.BookingNote, row.OperatorNote,
+							output.Anagraphics, 0, 0))
+					}
+				}
+			}
+		}
+	}
+	return records, nil
+}
+
+// Load reads the records of an input file or of the JSON output of a previous run.
+// The warnings of the parser on the file are not logged, as they refer to another version of the bookings.
+func Load(ctx config.WorkflowContext, path string) ([]Record, parser.Output, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, parser.Output{}, errors.Wrapf(err, "impossibile leggere il file %s", path)
+		}
+		records, err := FromJSONOutput(content)
+		if err != nil {
+			return nil, parser.Output{}, errors.Wrapf(err, "il file %s non è valido", path)
+		}
+		return records, parser.Output{}, nil
+	}
+
+	quiet := logrus.New()
+	quiet.SetOutput(ctx.Logger.Logger.Out)
+	quiet.SetLevel(logrus.ErrorLevel)
+	quietCtx := ctx
+	quietCtx.Logger = quiet.WithContext(ctx.Context)
+
+	readerOutput, err := reader.Execute(quietCtx, reader.Input{FilePath: path})
+	if err != nil {
+		return nil, parser.Output{}, errors.Wrapf(err, "impossibile leggere il file %s", path)
+	}
+	parserOutput, err := parser.Execute(quietCtx, readerOutput)
+	if err != nil {
+		return nil, parser.Output{}, errors.Wrapf(err, "impossibile interpretare il file %s", path)
+	}
+	return FromParserOutput(parserOutput), parserOutput, nil
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
@@ -19,11 +21,30 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/diff"
 	"github.com/fabiofenoglio/excelconv/logger"
+	"github.com/fabiofenoglio/excelconv/rules"
 	"github.com/fabiofenoglio/excelconv/writer"
+	csvwriter2 "github.com/fabiofenoglio/excelconv/writer/csv/v2"
 	jsonwriter2 "github.com/fabiofenoglio/excelconv/writer/json/v2"
+	vcardwriter2 "github.com/fabiofenoglio/excelconv/writer/vcard/v2"
 )
 
+const maxRowReferencesInSummary = 20
+
+// diffCommand is given instead of the input file to compare two versions of the bookings: excelconv diff <previous> <new>
+const diffCommand = "diff"
+
+type failOnWarningsError struct {
+	count    int
+	severity parser2.WarningSeverity
+}
+
+func (e failOnWarningsError) Error() string {
+	return fmt.Sprintf("trovati %d avvisi di gravità '%s' o superiore", e.count, e.severity)
+}
+
 func logErrorWaitAndExit(err error) {
 	logger.GetLogger().Error(err.Error())
 	//time.Sleep(time.Second * 20)
@@ -202,6 +223,12 @@ func runWithEnv(envConfig config.EnvConfig) {
 	defer span.Finish()
 
 	err = runWithPanicProtection(span.Context(), args, envConfig, log)
+	var failOnWarnings failOnWarningsError
+	if errors.As(err, &failOnWarnings) {
+		// expected failure, requested with --fail-on: no need to report it or to wait for the user
+		log.Error(failOnWarnings.Error())
+		os.Exit(2)
+	}
 	if err != nil {
 		fail(err)
 		return
@@ -235,18 +262,21 @@ func run(ctx context.Context, args config.Args, _ config.EnvConfig, log *logrus.
 		return errors.New("missing input file")
 	}
 
-	workflowContext := config.WorkflowContext{
-		Context: ctx,
-		Logger:  log.WithContext(ctx),
-		Config: config.WorkflowContextConfig{
-			EnableMissingOperatorsWarning: args.EnableMissingOperatorsWarning,
-			EnableUnconfirmedHighlight:    args.Debug,
-		},
+	workflowContext, operatorColors, err := buildWorkflowContext(ctx, args, log)
+	if err != nil {
+		return err
 	}
 
+	if input == diffCommand {
+		return runDiff(workflowContext, args, log)
+	}
+
+	logRules(workflowContext)
+
 	span := sentry.StartSpan(ctx, "read")
 	readerOutput, err := reader.Execute(workflowContext.ForContext(span.Context()), reader.Input{
-		FilePath: input,
+		FilePath:             input,
+		AvailabilityFilePath: args.Availability,
 	})
 	span.Finish()
 	if err != nil {
@@ -260,6 +290,22 @@ func run(ctx context.Context, args config.Args, _ config.EnvConfig, log *logrus.
 		return err
 	}
 
+	if args.OperatorColors != "" {
+		if err := saveOperatorColors(args.OperatorColors, operatorColors, parserOutput.Anagraphics, log); err != nil {
+			return err
+		}
+	}
+
+	if args.CompareWith != "" {
+		previous, _, err := diff.Load(workflowContext, args.CompareWith)
+		if err != nil {
+			return err
+		}
+		parserOutput.Changes = diff.Compare(previous, diff.FromParserOutput(parserOutput))
+		parser2.MarkChangedRows(parserOutput.Rows, parserOutput.Changes)
+		log.Infof("found %d changes compared to %s", len(parserOutput.Changes), args.CompareWith)
+	}
+
 	span = sentry.StartSpan(ctx, "aggregate")
 	aggregatorOutput, err := aggregator2.Execute(workflowContext.ForContext(span.Context()), parserOutput)
 	span.Finish()
@@ -267,6 +313,13 @@ func run(ctx context.Context, args config.Args, _ config.EnvConfig, log *logrus.
 		return err
 	}
 
+	if args.SaveLayout != "" {
+		if err := database.SaveLayout(args.SaveLayout, aggregator2.BuildLayout(aggregatorOutput.Days)); err != nil {
+			return err
+		}
+		log.Infof("saved the layout to %s", args.SaveLayout)
+	}
+
 	writer, err := pickWriter(args)
 	if err != nil {
 		return err
@@ -304,9 +357,409 @@ func run(ctx context.Context, args config.Args, _ config.EnvConfig, log *logrus.
 		return err
 	}
 
+	if err := writeReports(workflowContext, args, parserOutput, aggregatorOutput, log); err != nil {
+		return err
+	}
+
+	summaries := parser2.SummarizeWarnings(parserOutput.Rows)
+	logWarningsSummary(summaries, log)
+
+	if args.FailOn != "" {
+		failOn, _ := parser2.ParseWarningSeverity(args.FailOn)
+		if count := parser2.CountWarningsAtLeast(summaries, failOn); count > 0 {
+			return failOnWarningsError{count: count, severity: failOn}
+		}
+	}
+
+	return nil
+}
+
+// buildWorkflowContext loads the registries and the configuration files given in the arguments.
+func buildWorkflowContext(ctx context.Context, args config.Args, log *logrus.Logger) (config.WorkflowContext, map[string]string, error) {
+	if args.ActivityCatalog != "" {
+		if err := database.LoadKnownActivities(args.ActivityCatalog); err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+	}
+
+	if args.SchoolRegistry != "" {
+		if err := database.LoadKnownSchools(args.SchoolRegistry); err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+	}
+
+	operatorColors := make(map[string]string)
+	if args.OperatorColors != "" {
+		var err error
+		operatorColors, err = database.LoadOperatorColors(args.OperatorColors)
+		if err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+	}
+
+	var previousLayout *database.Layout
+	if args.Previous != "" {
+		var err error
+		previousLayout, err = database.LoadLayout(args.Previous)
+		if err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+		log.Infof("keeping the activities in the columns of the previous layout %s", args.Previous)
+	}
+
+	fileConfig := config.FileConfig{}
+	if args.Config != "" {
+		var err error
+		fileConfig, err = config.LoadFileConfig(args.Config)
+		if err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+	}
+
+	timeZone, competenceDayCutoffHour, err := buildCalendarConfig(args, fileConfig).Resolve()
+	if err != nil {
+		return config.WorkflowContext{}, nil, err
+	}
+
+	gridStep := fileConfig.GridStepMinutes
+	if args.GridStep > 0 {
+		gridStep = args.GridStep
+	}
+	if gridStep > 0 {
+		if err := config.ValidateGridStep(gridStep); err != nil {
+			return config.WorkflowContext{}, nil, err
+		}
+	}
+
+	for room, grouping := range fileConfig.RoomGrouping {
+		if _, _, err := grouping.Resolve(); err != nil {
+			return config.WorkflowContext{}, nil, errors.Wrapf(err, "configurazione del raggruppamento dell'aula %s", room)
+		}
+	}
+
+	groupNumbering := buildGroupNumberingConfig(args, fileConfig)
+	if err := groupNumbering.Validate(); err != nil {
+		return config.WorkflowContext{}, nil, err
+	}
+
+	placementOptimizationBudget := time.Duration(0)
+	if args.OptimizePlacement {
+		placementOptimizationBudget = args.OptimizePlacementBudget
+	}
+
+	seed := args.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Infof("using seed %d, run again with --seed %d to reproduce the same output", seed, seed)
+
+	workflowContext := config.WorkflowContext{
+		Context: ctx,
+		Logger:  log.WithContext(ctx),
+		Config: config.WorkflowContextConfig{
+			EnableMissingOperatorsWarning: args.EnableMissingOperatorsWarning,
+			EnableUnconfirmedHighlight:    args.Debug,
+			EnableOperatorSuggestions:     args.SuggestOperators,
+			ActivityNameNormalization: config.ActivityNameNormalizationConfig{
+				Threshold:      args.NormalizationThreshold,
+				ProtectedNames: args.ProtectedActivityNames,
+			},
+			SchoolDeduplicationThreshold: args.SchoolDeduplicationThreshold,
+			ParkingCapacity:              args.ParkingCapacity,
+			Lunch:                        buildLunchConfig(args, fileConfig),
+			PriceList:                    fileConfig.PriceList,
+			OperatorColors:               operatorColors,
+			Rules:                        buildRulesConfig(args, fileConfig),
+			KeywordRules:                 fileConfig.KeywordRules,
+			ComboRules:                   fileConfig.ComboRules,
+			Warnings:                     buildWarningsConfig(args, fileConfig),
+			TimeZone:                     timeZone,
+			CompetenceDayCutoffHour:      competenceDayCutoffHour,
+			GridStep:                     gridStep,
+			RoomGrouping:                 fileConfig.RoomGrouping,
+			GroupNumbering:               groupNumbering,
+			OperatorSkills:               fileConfig.OperatorSkills,
+			PlacementOptimizationBudget:  placementOptimizationBudget,
+			ExplainPlacement:             args.ExplainPlacement,
+			PreviousLayout:               previousLayout,
+			Seed:                         seed,
+		},
+	}
+
+	return workflowContext, operatorColors, nil
+}
+
+// runDiff compares two versions of the bookings, given as input files or JSON outputs, and lists the changes.
+func runDiff(workflowContext config.WorkflowContext, args config.Args, log *logrus.Logger) error {
+	if len(args.PositionalArgs.Rest) != 2 {
+		return errors.New("il comando diff richiede due file: la versione precedente e quella nuova")
+	}
+	previousFile, currentFile := args.PositionalArgs.Rest[0], args.PositionalArgs.Rest[1]
+
+	previous, _, err := diff.Load(workflowContext, previousFile)
+	if err != nil {
+		return err
+	}
+	current, _, err := diff.Load(workflowContext, currentFile)
+	if err != nil {
+		return err
+	}
+
+	changes := diff.Compare(previous, current)
+	logChanges(changes, log)
+
+	changesBytes, err := csvwriter2.WriteChanges(workflowContext, changes)
+	if err != nil {
+		return errors.Wrap(err, "error writing changes")
+	}
+	if args.StdOut {
+		_, err := os.Stdout.Write(changesBytes)
+		return err
+	}
+	return saveReport(csvwriter2.ComputeChangesOutputFile(currentFile), changesBytes, log)
+}
+
+func logChanges(changes []parser2.BookingChange, log *logrus.Logger) {
+	if len(changes) == 0 {
+		log.Info("nessuna modifica rispetto alla versione precedente")
+		return
+	}
+
+	log.Infof("%d modifiche rispetto alla versione precedente:", len(changes))
+	for _, change := range changes {
+		line := fmt.Sprintf("  [%s] %s %s %s-%s %s, %s", change.KindLabel(), change.BookingCode, change.Date.Format("02/01"),
+			change.StartTime.Format("15:04"), change.EndTime.Format("15:04"), change.Room, change.Activity)
+		if len(change.Fields) > 0 {
+			line += ": " + change.DescribeFields()
+		}
+		log.Info(line)
+	}
+}
+
+func writeReports(workflowContext config.WorkflowContext, args config.Args, parserOutput parser2.Output, aggregatorOutput aggregator2.Output, log *logrus.Logger) error {
+	input := args.PositionalArgs.InputFile
+
+	if !args.StdOut && len(parserOutput.OperatorSuggestions) > 0 {
+		suggestionsBytes, err := csvwriter2.WriteOperatorSuggestions(workflowContext, parserOutput.OperatorSuggestions, parserOutput.Anagraphics)
+		if err != nil {
+			return errors.Wrap(err, "error writing operator suggestions")
+		}
+		if err := saveReport(csvwriter2.ComputeOperatorSuggestionsOutputFile(input), suggestionsBytes, log); err != nil {
+			return err
+		}
+	}
+
+	if !args.StdOut && len(args.ExportContacts) > 0 {
+		contacts := parser2.CollectReferentContacts(parserOutput.Rows, parserOutput.Anagraphics)
+		for _, format := range args.ExportContacts {
+			var (
+				contactsBytes []byte
+				outputFile    string
+				err           error
+			)
+			switch format {
+			case "vcard":
+				contactsBytes, err = vcardwriter2.WriteReferentContacts(workflowContext, contacts, parserOutput.Anagraphics)
+				outputFile = vcardwriter2.ComputeReferentContactsOutputFile(input)
+			default:
+				contactsBytes, err = csvwriter2.WriteReferentContacts(workflowContext, contacts, parserOutput.Anagraphics)
+				outputFile = csvwriter2.ComputeReferentContactsOutputFile(input)
+			}
+			if err != nil {
+				return errors.Wrap(err, "error writing referent contacts")
+			}
+			if err := saveReport(outputFile, contactsBytes, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !args.StdOut && parserOutput.Normalizations != nil {
+		normalizationsBytes, err := csvwriter2.WriteNormalizations(workflowContext, parserOutput.Normalizations)
+		if err != nil {
+			return errors.Wrap(err, "error writing activity name normalizations")
+		}
+		if err := saveReport(csvwriter2.ComputeNormalizationsOutputFile(input), normalizationsBytes, log); err != nil {
+			return err
+		}
+	}
+
+	if args.Previous != "" {
+		changes := aggregator2.CollectLayoutChanges(aggregatorOutput.Days)
+		logLayoutChanges(changes, parserOutput.Anagraphics, log)
+		if !args.StdOut && len(changes) > 0 {
+			changesBytes, err := csvwriter2.WriteLayoutChanges(workflowContext, changes, parserOutput.Anagraphics)
+			if err != nil {
+				return errors.Wrap(err, "error writing layout changes")
+			}
+			if err := saveReport(csvwriter2.ComputeLayoutChangesOutputFile(input), changesBytes, log); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !args.StdOut && args.ExplainPlacement {
+		explanations := aggregator2.CollectPlacementExplanations(aggregatorOutput.Days)
+		csvBytes, err := csvwriter2.WritePlacementExplanations(workflowContext, explanations, parserOutput.Anagraphics)
+		if err != nil {
+			return errors.Wrap(err, "error writing placement explanations")
+		}
+		if err := saveReport(csvwriter2.ComputePlacementExplanationsOutputFile(input), csvBytes, log); err != nil {
+			return err
+		}
+		jsonBytes, err := jsonwriter2.WritePlacementExplanations(workflowContext, explanations)
+		if err != nil {
+			return errors.Wrap(err, "error writing placement explanations")
+		}
+		if err := saveReport(jsonwriter2.ComputePlacementExplanationsOutputFile(input), jsonBytes, log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func logWarningsSummary(summaries []parser2.WarningSummary, log *logrus.Logger) {
+	if len(summaries) == 0 {
+		log.Info("nessun avviso")
+		return
+	}
+
+	log.Info("riepilogo degli avvisi:")
+	for _, summary := range summaries {
+		rowNumbers := make([]string, 0, len(summary.RowNumbers))
+		for i, rowNumber := range summary.RowNumbers {
+			if i >= maxRowReferencesInSummary {
+				rowNumbers = append(rowNumbers, "...")
+				break
+			}
+			rowNumbers = append(rowNumbers, fmt.Sprintf("%d", rowNumber))
+		}
+
+		description := summary.Code
+		if summary.Message != "" {
+			description += " (" + summary.Message + ")"
+		}
+		line := fmt.Sprintf("  [%s] %s: %d, righe %s", summary.Severity, description, summary.Count, strings.Join(rowNumbers, ", "))
+
+		switch summary.Severity {
+		case parser2.SeverityError:
+			log.Error(line)
+		case parser2.SeverityWarning:
+			log.Warn(line)
+		default:
+			log.Info(line)
+		}
+	}
+}
+
+func logLayoutChanges(changes []aggregator2.LayoutChange, anagraphics *parser2.OutputAnagraphics, log *logrus.Logger) {
+	if len(changes) == 0 {
+		log.Info("nessuna attività spostata rispetto alla disposizione precedente")
+		return
+	}
+
+	log.Warnf("%d attività spostate rispetto alla disposizione precedente:", len(changes))
+	for _, change := range changes {
+		log.Warnf("  %s %s-%s %s, %s: colonna %d -> %d (%s)",
+			change.Day.Format("02/01"), change.StartTime.Format("15:04"), change.EndTime.Format("15:04"),
+			anagraphics.Rooms[change.RoomCode].Name, anagraphics.Activities[change.ActivityCode].Name,
+			change.PreviousSlot+1, change.Slot+1, strings.Join(change.BookingCodes, ", "))
+	}
+}
+
+func saveReport(outputFile string, content []byte, log *logrus.Logger) error {
+	log.Debugf("writing to report file %s", outputFile)
+	if err := os.WriteFile(outputFile, content, 0755); err != nil {
+		return errors.Wrapf(err, "error saving to output file %s", outputFile)
+	}
+	log.Infof("saved report to output file %s", outputFile)
 	return nil
 }
 
+func buildRulesConfig(args config.Args, fileConfig config.FileConfig) map[string]bool {
+	out := make(map[string]bool)
+	for code, enabled := range fileConfig.Rules {
+		out[code] = enabled
+	}
+	if args.NormalizeActivityNames {
+		out["activity-name-normalization"] = true
+	}
+	for _, code := range args.EnableRules {
+		out[code] = true
+	}
+	for _, code := range args.DisableRules {
+		out[code] = false
+	}
+	return out
+}
+
+func buildWarningsConfig(args config.Args, fileConfig config.FileConfig) config.WarningsConfig {
+	out := fileConfig.Warnings
+	out.Suppress = append(append([]string{}, out.Suppress...), args.SuppressWarnings...)
+	return out
+}
+
+func buildLunchConfig(args config.Args, fileConfig config.FileConfig) config.LunchConfig {
+	out := fileConfig.Lunch
+	if args.LunchSeats > 0 {
+		out.Seats = args.LunchSeats
+	}
+	return out
+}
+
+func buildCalendarConfig(args config.Args, fileConfig config.FileConfig) config.CalendarConfig {
+	out := fileConfig.Calendar
+	if args.TimeZone != "" {
+		out.TimeZone = args.TimeZone
+	}
+	if args.CompetenceDayCutoff != nil {
+		out.CompetenceDayCutoffHour = args.CompetenceDayCutoff
+	}
+	return out
+}
+
+func buildGroupNumberingConfig(args config.Args, fileConfig config.FileConfig) config.GroupNumberingConfig {
+	out := fileConfig.GroupNumbering
+	if args.GroupNumberingScope != "" {
+		out.Scope = config.GroupNumberingScope(args.GroupNumberingScope)
+	}
+	if args.GroupNumberingScheme != "" {
+		out.Scheme = config.GroupNumberingScheme(args.GroupNumberingScheme)
+	}
+	return out
+}
+
+func logRules(ctx config.WorkflowContext) {
+	all := make([]rules.Rule, 0)
+	for _, rule := range reader.RegisteredRulesA0() {
+		all = append(all, rule)
+	}
+	for _, rule := range parser2.RegisteredRulesB0() {
+		all = append(all, rule)
+	}
+	for _, rule := range aggregator2.RegisteredPostAggregationRules() {
+		all = append(all, rule)
+	}
+
+	known := make(map[string]bool)
+	for _, rule := range all {
+		known[rule.Code()] = true
+		ctx.Logger.Debugf("rule %s [%s] %s: enabled=%v", rule.Code(), rule.Stage(), rule.Description(),
+			ctx.Config.IsRuleEnabled(rule.Code(), rule.EnabledByDefault()))
+	}
+	unknown := make([]string, 0)
+	for code := range ctx.Config.Rules {
+		if !known[code] {
+			unknown = append(unknown, code)
+		}
+	}
+	sort.Strings(unknown)
+	for _, code := range unknown {
+		ctx.Logger.Warnf("la regola %s non esiste", code)
+	}
+}
+
 func pickWriter(arg config.Args) (writer.Writer, error) {
 	switch arg.Format {
 	case "excel":
@@ -317,3 +770,31 @@ func pickWriter(arg config.Args) (writer.Writer, error) {
 
 	return nil, errors.Errorf("%s is not a valid output format (no writer available)", arg.Format)
 }
+
+// saveOperatorColors adds to the persisted colours the ones assigned in this run to the operators not in the registry.
+func saveOperatorColors(path string, persisted map[string]string, anagraphics *parser2.OutputAnagraphics, log *logrus.Logger) error {
+	out := make(map[string]string, len(persisted))
+	for code, color := range persisted {
+		out[code] = color
+	}
+
+	added := 0
+	for _, operator := range anagraphics.Operators {
+		if operator.Known || operator.BackgroundColor == "" {
+			continue
+		}
+		if _, ok := out[operator.Code]; !ok {
+			out[operator.Code] = operator.BackgroundColor
+			added++
+		}
+	}
+
+	if added == 0 {
+		return nil
+	}
+	if err := database.SaveOperatorColors(path, out); err != nil {
+		return err
+	}
+	log.Infof("saved the colours of %d new operators to %s", added, path)
+	return nil
+}
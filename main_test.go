@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+// run with -update to write again the expected outputs after an intended change
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+const goldenDir = "testdata/golden"
+
+// goldenSeeds shuffle the input rows in different ways: the output must not change whatever the seed
+var goldenSeeds = []int64{42, 1, 7, 123456}
+
+// TestGolden converts each fixture in testdata/golden/<case> to JSON and to excel with every seed and compares
+// the results with the expected ones. A case holds an input.xlsx and optionally a config.json passed with --config.
+func TestGolden(t *testing.T) {
+	cases, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(goldenDir, c.Name())
+
+		t.Run(c.Name(), func(t *testing.T) {
+			for i, seed := range goldenSeeds {
+				jsonOutput, err := os.ReadFile(runGoldenCase(t, caseDir, "json", seed))
+				if err != nil {
+					t.Fatal(err)
+				}
+				indented := bytes.Buffer{}
+				if assert.NoError(t, json.Indent(&indented, jsonOutput, "", "  ")) {
+					assertGolden(t, filepath.Join(caseDir, "expected.json"), indented.String()+"\n", i == 0, seed)
+				}
+
+				excelOutput := runGoldenCase(t, caseDir, "excel", seed)
+				cells, err := dumpExcelCells(excelOutput)
+				if assert.NoError(t, err) {
+					assertGolden(t, filepath.Join(caseDir, "expected-cells.txt"), cells, i == 0, seed)
+				}
+			}
+		})
+	}
+}
+
+// runGoldenCase converts a copy of the input of the case in a temporary folder and returns the output file.
+func runGoldenCase(t *testing.T, caseDir string, format string, seed int64) string {
+	workDir := t.TempDir()
+	input := filepath.Join(workDir, "input.xlsx")
+	content, err := os.ReadFile(filepath.Join(caseDir, "input.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(input, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := config.Args{
+		Format:                       format,
+		Seed:                         seed,
+		SchoolDeduplicationThreshold: 0.95,
+		NormalizationThreshold:       0.9,
+	}
+	args.PositionalArgs.InputFile = input
+	if _, err := os.Stat(filepath.Join(caseDir, "config.json")); err == nil {
+		args.Config = filepath.Join(caseDir, "config.json")
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	if err := run(context.Background(), args, config.EnvConfig{}, log); err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := pickWriter(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writer.ComputeDefaultOutputFile(input)
+}
+
+// assertGolden compares the output with the expected file. With -update only the first seed writes it, so the
+// other seeds are still compared with it and a seed-dependent output fails even while updating.
+func assertGolden(t *testing.T, expectedFile string, actual string, first bool, seed int64) {
+	if *updateGolden && first {
+		if err := os.WriteFile(expectedFile, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(expectedFile)
+	if err != nil {
+		t.Fatalf("missing golden file %s, run the tests with -update to create it", expectedFile)
+	}
+	assert.Equal(t, string(expected), actual, "output with seed %d differs from %s", seed, expectedFile)
+}
+
+// dumpExcelCells writes the non-empty cells of every sheet with their fill colour, the merged cells and
+// the comments, one per line, so that two workbooks can be compared regardless of how they are zipped.
+func dumpExcelCells(file string) (string, error) {
+	f, err := excelize.OpenFile(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	out := strings.Builder{}
+	for _, sheet := range f.GetSheetList() {
+		out.WriteString("## " + sheet + "\n")
+
+		rows, err := f.GetRows(sheet)
+		if err != nil {
+			return "", err
+		}
+		for r, row := range rows {
+			for c, value := range row {
+				if value == "" {
+					continue
+				}
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+1)
+				line := fmt.Sprintf("%s %q", cell, value)
+				if fill, err := cellFill(f, sheet, cell); err != nil {
+					return "", err
+				} else if fill != "" {
+					line += " fill=" + fill
+				}
+				out.WriteString(line + "\n")
+			}
+		}
+
+		mergeCells, err := f.GetMergeCells(sheet)
+		if err != nil {
+			return "", err
+		}
+		merged := make([]string, 0, len(mergeCells))
+		for _, m := range mergeCells {
+			merged = append(merged, "merge "+m.GetStartAxis()+":"+m.GetEndAxis())
+		}
+		sort.Strings(merged)
+		for _, m := range merged {
+			out.WriteString(m + "\n")
+		}
+
+		comments, err := f.GetComments(sheet)
+		if err != nil {
+			return "", err
+		}
+		sort.Slice(comments, func(i, j int) bool {
+			return comments[i].Cell < comments[j].Cell
+		})
+		for _, comment := range comments {
+			text := comment.Text
+			for _, run := range comment.Paragraph {
+				text += run.Text
+			}
+			out.WriteString(fmt.Sprintf("comment %s %q\n", comment.Cell, text))
+		}
+	}
+	return out.String(), nil
+}
+
+func cellFill(f *excelize.File, sheet, cell string) (string, error) {
+	styleID, err := f.GetCellStyle(sheet, cell)
+	if err != nil || styleID == 0 {
+		return "", err
+	}
+	style, err := f.GetStyle(styleID)
+	if err != nil {
+		return "", err
+	}
+	if len(style.Fill.Color) == 0 {
+		return "", nil
+	}
+	return strings.ToUpper(strings.Join(style.Fill.Color, ",")), nil
+}
+
+func TestDiffRequiresTwoFiles(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	for _, rest := range [][]string{nil, {"previous.xlsx"}} {
+		args := config.Args{Format: "excel"}
+		args.PositionalArgs.InputFile = diffCommand
+		args.PositionalArgs.Rest = rest
+
+		err := run(context.Background(), args, config.EnvConfig{}, log)
+		assert.EqualError(t, err, "il comando diff richiede due file: la versione precedente e quella nuova")
+	}
+}
@@ -2,10 +2,11 @@ package parser
 
 import (
 	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 func HydrateActivities(
-	_ config.WorkflowContext,
+	ctx config.WorkflowContext,
 	rows []Row,
 ) (
 	[]Row,
@@ -19,23 +20,38 @@ func HydrateActivities(
 
 	activitiesIndex := make(map[string]Activity)
 	activityTypesIndex := make(map[string]ActivityType)
+	notInCatalog := make(map[string]bool)
 
 	for _, row := range rows {
 		mappedRow := row
 
 		// no natural UUID for these fields, computing one on-the-fly based on attributes
 		activityTypeCode := nameToCode(row.activityTypeRawString)
-		activityCode := activityTypeCode + "/" + nameToCode(row.activityRawString) + "/?lang=" + nameToCode(row.activityLanguageRawString)
+		activityNameCode := nameToCode(row.activityRawString)
+		activityName := cleanStringForVisualization(row.activityRawString)
+		catalogCode := ""
+
+		if knownActivity, isKnown := database.GetKnownActivity(row.activityRawString); isKnown {
+			activityNameCode = knownActivity.Code
+			activityName = knownActivity.Name
+			catalogCode = knownActivity.Code
+		} else if database.HasKnownActivities() && activityNameCode != "" && !notInCatalog[activityNameCode] {
+			notInCatalog[activityNameCode] = true
+			ctx.Logger.Warnf("l'attività '%s' non è presente nel catalogo", activityName)
+		}
+
+		activityCode := activityTypeCode + "/" + activityNameCode + "/?lang=" + nameToCode(row.activityLanguageRawString)
 
 		if activityCode != "" {
 			mappedRow.ActivityCode = activityCode
 
 			if _, activityAlreadyMapped := activitiesIndex[activityCode]; !activityAlreadyMapped {
 				newActivity := Activity{
-					Code:     activityCode,
-					TypeCode: activityTypeCode,
-					Name:     cleanStringForVisualization(row.activityRawString),
-					Language: cleanStringForVisualization(row.activityLanguageRawString),
+					Code:        activityCode,
+					TypeCode:    activityTypeCode,
+					Name:        activityName,
+					Language:    cleanStringForVisualization(row.activityLanguageRawString),
+					CatalogCode: catalogCode,
 				}
 				activitiesIndex[activityCode] = newActivity
 				outActivities = append(outActivities, newActivity)
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type BusInfo struct {
+	Raw           string    `json:"raw,omitempty"`
+	ArrivalTime   time.Time `json:"arrival_time"`
+	DepartureTime time.Time `json:"departure_time"`
+	Company       string    `json:"company,omitempty"`
+	// Vehicles is the number of buses, 0 if the group does not come by bus
+	Vehicles int `json:"vehicles,omitempty"`
+}
+
+func (b BusInfo) IsPresent() bool {
+	return b.Vehicles > 0
+}
+
+var (
+	busNoneRegex  = regexp.MustCompile(`(?i)^\s*(-+|no|nessuno|no\s+bus|mezzi\s+propri|a\s+piedi|n\.?\s*d\.?)\s*$`)
+	busTimeRegex  = regexp.MustCompile(`(?i)(?:\b(arrivo|arr|andata|entrata|partenza|part|ritorno|rientro|uscita)\b\.?\s*(?::\s*)?(?:(?:alle|ore|h)\s*)?)?\b([01]?\d|2[0-3])[:.h]([0-5]\d)\b`)
+	busCountRegex = regexp.MustCompile(`(?i)\b(\d{1,2})\s*(?:x\s*)?(bus|pullman|autobus|pulmin[oi]|mezzi|veicoli|navette)\b`)
+	busNoiseRegex = regexp.MustCompile(`(?i)\b(arrivo|arr|andata|entrata|partenza|part|ritorno|rientro|uscita|bus|pullman|autobus|pulmin[oi]|ditta|compagnia|ore|alle|con|si|n|x|e)\b\.?`)
+	busSeparators = regexp.MustCompile(`[\-,;:/()|+]+`)
+)
+
+var busDepartureKeywords = map[string]bool{
+	"partenza": true,
+	"part":     true,
+	"ritorno":  true,
+	"rientro":  true,
+	"uscita":   true,
+}
+
+// ParseBus reads the free text of the bus column (ex. "arrivo 9:15 partenza 14.30 - 2 pullman Rossi"),
+// extracting the arrival and departure times on the given date, the company and the number of vehicles.
+// Times without a keyword are taken as arrival first and departure then.
+func ParseBus(raw string, date time.Time) BusInfo {
+	out := BusInfo{Raw: cleanStringForVisualization(raw)}
+	if out.Raw == "" || busNoneRegex.MatchString(out.Raw) {
+		return out
+	}
+
+	atTime := func(hourRaw, minuteRaw string) time.Time {
+		hour, _ := strconv.Atoi(hourRaw)
+		minute, _ := strconv.Atoi(minuteRaw)
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	}
+
+	for _, match := range busTimeRegex.FindAllStringSubmatch(out.Raw, -1) {
+		t := atTime(match[2], match[3])
+		keyword := strings.ToLower(match[1])
+		switch {
+		case busDepartureKeywords[keyword]:
+			out.DepartureTime = t
+		case keyword != "":
+			out.ArrivalTime = t
+		case out.ArrivalTime.IsZero():
+			out.ArrivalTime = t
+		case out.DepartureTime.IsZero():
+			out.DepartureTime = t
+		}
+	}
+	leftover := busTimeRegex.ReplaceAllString(out.Raw, " ")
+
+	out.Vehicles = 1
+	if match := busCountRegex.FindStringSubmatch(leftover); match != nil {
+		if count, err := strconv.Atoi(match[1]); err == nil && count > 0 {
+			out.Vehicles = count
+		}
+	}
+	leftover = busCountRegex.ReplaceAllString(leftover, " ")
+
+	leftover = busNoiseRegex.ReplaceAllString(leftover, " ")
+	leftover = busSeparators.ReplaceAllString(leftover, " ")
+	leftover = strings.Join(strings.Fields(leftover), " ")
+	if strings.IndexFunc(leftover, unicode.IsLetter) >= 0 {
+		out.Company = leftover
+	}
+
+	return out
+}
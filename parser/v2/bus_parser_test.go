@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBus(t *testing.T) {
+
+	type testCase struct {
+		input     string
+		arrival   time.Time
+		departure time.Time
+		company   string
+		vehicles  int
+	}
+
+	testCases := []testCase{
+		{"", time.Time{}, time.Time{}, "", 0},
+		{" - ", time.Time{}, time.Time{}, "", 0},
+		{"NO", time.Time{}, time.Time{}, "", 0},
+		{"mezzi propri", time.Time{}, time.Time{}, "", 0},
+		{"SI", time.Time{}, time.Time{}, "", 1},
+		{"bus", time.Time{}, time.Time{}, "", 1},
+		{"9:15 - 14:30", at(9, 15), at(14, 30), "", 1},
+		{"arrivo 9.15 partenza 14.30", at(9, 15), at(14, 30), "", 1},
+		{"partenza ore 15:00, arrivo alle 9:30", at(9, 30), at(15, 0), "", 1},
+		{"ritorno h 16:00", time.Time{}, at(16, 0), "", 1},
+		{"2 pullman Autolinee Rossi arr. 9:00 part. 13:45", at(9, 0), at(13, 45), "Autolinee Rossi", 2},
+		{"ditta Bianchi (3 bus) 10h00-15h00", at(10, 0), at(15, 0), "Bianchi", 3},
+		{"GTT", time.Time{}, time.Time{}, "GTT", 1},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			bus := ParseBus(tc.input, testDay)
+			assert.Equal(t, tc.arrival, bus.ArrivalTime)
+			assert.Equal(t, tc.departure, bus.DepartureTime)
+			assert.Equal(t, tc.company, bus.Company)
+			assert.Equal(t, tc.vehicles, bus.Vehicles)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"strings"
+	"time"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// BookingChange is an activity added, removed or modified compared to a previous version of the bookings.
+type BookingChange struct {
+	Kind        ChangeKind `json:"kind"`
+	BookingCode string     `json:"booking_code"`
+	Date        time.Time  `json:"date"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Room        string     `json:"room"`
+	Activity    string     `json:"activity"`
+	Group       string     `json:"group"`
+	// RowNumber is the row in the new file, or in the previous one for the removed activities, 0 if unknown
+	RowNumber uint `json:"row_number,omitempty"`
+	// RowID is the ID of the row in the new file, 0 for the removed activities
+	RowID  int           `json:"-"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a field with a different value in the new version.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+func (c BookingChange) KindLabel() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "aggiunta"
+	case ChangeRemoved:
+		return "rimossa"
+	default:
+		return "modificata"
+	}
+}
+
+// DescribeFields writes the field differences in a single line.
+func (c BookingChange) DescribeFields() string {
+	parts := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		oldValue, newValue := field.Old, field.New
+		if oldValue == "" {
+			oldValue = "-"
+		}
+		if newValue == "" {
+			newValue = "-"
+		}
+		parts = append(parts, field.Field+": "+oldValue+" → "+newValue)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// MarkChangedRows links the changes to the rows they refer to.
+func MarkChangedRows(rows []OutputRow, changes []BookingChange) {
+	byRowID := make(map[int]int)
+	for i, change := range changes {
+		if change.Kind != ChangeRemoved && change.RowID != 0 {
+			byRowID[change.RowID] = i
+		}
+	}
+	for i := range rows {
+		if index, ok := byRowID[rows[i].ID]; ok {
+			change := changes[index]
+			rows[i].Change = &change
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkChangedRows(t *testing.T) {
+	rows := []OutputRow{{ID: 1}, {ID: 2}}
+	MarkChangedRows(rows, []BookingChange{
+		{Kind: ChangeModified, RowID: 2},
+		{Kind: ChangeRemoved},
+	})
+	assert.Nil(t, rows[0].Change)
+	if assert.NotNil(t, rows[1].Change) {
+		assert.Equal(t, ChangeModified, rows[1].Change.Kind)
+	}
+}
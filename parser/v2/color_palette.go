@@ -1,5 +1,16 @@
 package parser
 
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
 var availableColors = []string{
 	"#D9E9FA",
 	"#C8C7F9",
@@ -15,28 +26,114 @@ var availableColors = []string{
 	"#c7b88f",
 	"#a15da0",
 	"#d9c7d0",
+	"#f4a261",
+	"#e9c46a",
+	"#90be6d",
+	"#43aa8b",
+	"#f28482",
+	"#84a59d",
+	"#b5838d",
+	"#6d9dc5",
+	"#ffb4a2",
+	"#cdb4db",
 }
 
-var (
-	registerColorRange  = 0
-	assignedColorsCache map[string]string
-)
+// minColorDistance is the distance under which two colours are considered too similar to tell apart.
+const minColorDistance = 80
+
+// assignOperatorColors gives a colour to the operators that are not in the registry.
+// The colour previously persisted for the operator is kept, otherwise it is picked from the palette
+// starting from a position given by the hash of the code, skipping the colours too similar to the ones of
+// the rooms and of the other operators. Operators are processed by code so that the result does not depend
+// on the order of the rows.
+func assignOperatorColors(ctx config.WorkflowContext, operators []Operator) []Operator {
+	used := make([]string, 0, len(operators))
+	for _, room := range database.GetKnownRooms() {
+		if room.BackgroundColor != "" {
+			used = append(used, room.BackgroundColor)
+		}
+	}
+	for _, operator := range operators {
+		if operator.Known && operator.BackgroundColor != "" {
+			used = append(used, operator.BackgroundColor)
+		}
+	}
 
-func init() {
-	assignedColorsCache = make(map[string]string)
+	toAssign := make([]int, 0)
+	for i, operator := range operators {
+		if operator.Known {
+			continue
+		}
+		if persisted, ok := ctx.Config.OperatorColors[operator.Code]; ok {
+			operators[i].BackgroundColor = persisted
+			used = append(used, persisted)
+			continue
+		}
+		toAssign = append(toAssign, i)
+	}
+
+	sort.Slice(toAssign, func(i, j int) bool {
+		return operators[toAssign[i]].Code < operators[toAssign[j]].Code
+	})
+	for _, i := range toAssign {
+		color := pickColor(operators[i].Code, used)
+		operators[i].BackgroundColor = color
+		used = append(used, color)
+	}
+
+	return operators
 }
 
-func pickColor(key string) string {
-	if v, assignedAlready := assignedColorsCache[key]; assignedAlready {
-		return v
+func pickColor(key string, used []string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	start := int(h.Sum32() % uint32(len(availableColors)))
+
+	best, bestDistance := "", -1.0
+	for i := range availableColors {
+		candidate := availableColors[(start+i)%len(availableColors)]
+		distance := minDistanceFrom(candidate, used)
+		if distance >= minColorDistance {
+			return candidate
+		}
+		if distance > bestDistance {
+			best, bestDistance = candidate, distance
+		}
 	}
+	return best
+}
 
-	v := availableColors[registerColorRange]
-	registerColorRange++
-	if registerColorRange >= len(availableColors) {
-		registerColorRange = 0
+func minDistanceFrom(color string, others []string) float64 {
+	out := math.MaxFloat64
+	for _, other := range others {
+		if d := colorDistance(color, other); d < out {
+			out = d
+		}
 	}
+	return out
+}
 
-	assignedColorsCache[key] = v
-	return v
+// colorDistance approximates how different two colours look, using the "redmean" weighted euclidean distance.
+// Colours that can not be parsed are considered very different.
+func colorDistance(a, b string) float64 {
+	r1, g1, b1, ok1 := parseHexColor(a)
+	r2, g2, b2, ok2 := parseHexColor(b)
+	if !ok1 || !ok2 {
+		return math.MaxFloat64
+	}
+	redMean := (r1 + r2) / 2
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return math.Sqrt((2+redMean/256)*dr*dr + 4*dg*dg + (2+(255-redMean)/256)*db*db)
+}
+
+func parseHexColor(color string) (float64, float64, float64, bool) {
+	color = strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(color) != 6 {
+		return 0, 0, 0, false
+	}
+	value, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return float64(value >> 16 & 0xFF), float64(value >> 8 & 0xFF), float64(value & 0xFF), true
 }
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/reader/v2"
+)
+
+func TestAssignOperatorColorsIsDeterministic(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{})
+
+	unknown := func(codes ...string) []Operator {
+		out := make([]Operator, 0, len(codes))
+		for _, code := range codes {
+			out = append(out, Operator{Code: code})
+		}
+		return out
+	}
+	colorsOf := func(operators []Operator) map[string]string {
+		out := make(map[string]string)
+		for _, operator := range operators {
+			out[operator.Code] = operator.BackgroundColor
+		}
+		return out
+	}
+
+	first := colorsOf(assignOperatorColors(ctx, unknown("mario", "anna", "luca", "giulia")))
+	second := colorsOf(assignOperatorColors(ctx, unknown("giulia", "luca", "anna", "mario")))
+	assert.Equal(t, first, second)
+
+	// colours are not reused and are far enough from the rooms' ones
+	seen := make(map[string]bool)
+	for _, color := range first {
+		assert.NotEmpty(t, color)
+		assert.False(t, seen[color])
+		seen[color] = true
+		for _, room := range database.GetKnownRooms() {
+			if room.BackgroundColor != "" {
+				assert.GreaterOrEqual(t, colorDistance(color, room.BackgroundColor), float64(minColorDistance))
+			}
+		}
+	}
+}
+
+func TestAssignOperatorColorsKeepsPersistedColors(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		OperatorColors: map[string]string{"mario": "#123456"},
+	})
+
+	operators := assignOperatorColors(ctx, []Operator{
+		{Code: "mario"},
+		{Code: "known", Known: true, BackgroundColor: "#A0FC4E"},
+	})
+	assert.Equal(t, "#123456", operators[0].BackgroundColor)
+	assert.Equal(t, "#A0FC4E", operators[1].BackgroundColor)
+}
+
+func TestColorDistance(t *testing.T) {
+	assert.Equal(t, float64(0), colorDistance("#D9E9FA", "#d9e9fa"))
+	assert.Greater(t, colorDistance("#000000", "#FFFFFF"), colorDistance("#000000", "#333333"))
+	assert.Greater(t, colorDistance("invalid", "#FFFFFF"), float64(minColorDistance))
+}
+
+func TestOperatorsOnlyInAvailabilityGetAColor(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{})
+
+	out, err := Execute(ctx, reader.Output{
+		Availability: []reader.OutputAvailabilityRow{{
+			Operator:  "Operatore Solo Disponibilità",
+			Date:      time.Date(2025, 3, 10, 0, 0, 0, 0, config.TimeZone()),
+			AllDay:    true,
+			IsAbsence: true,
+		}},
+	})
+	assert.NoError(t, err)
+
+	operator, ok := out.Anagraphics.Operators["operatoresolodisponibilità"]
+	if assert.True(t, ok) {
+		assert.False(t, operator.Known)
+		assert.NotEmpty(t, operator.BackgroundColor)
+	}
+}
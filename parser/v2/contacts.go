@@ -0,0 +1,287 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type EmailCorrection struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+}
+
+var (
+	emailRegex = regexp.MustCompile(`^[a-z0-9!#$%&'*+/=?^_{|}~-]+(\.[a-z0-9!#$%&'*+/=?^_{|}~-]+)*@[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*\.[a-z]{2,}$`)
+	// a comma typed instead of the dot of the domain, ex. "mario.rossi@gmail,com"
+	emailDomainCommaRegex = regexp.MustCompile(`(@[a-zA-Z0-9.\-]+),\s?([a-zA-Z]{2,4})\b`)
+	emailSeparatorsRegex  = regexp.MustCompile(`[\s,;|]+|\s/\s`)
+	teacherSeparatorRegex = regexp.MustCompile(`\s*(?:[,;/\n+&]|\s-\s|\s+e\s+)\s*`)
+)
+
+// emailDomainTypos maps the common misspellings of the email domains to the correct ones.
+var emailDomainTypos = map[string]string{
+	"gmial.com":    "gmail.com",
+	"gmai.com":     "gmail.com",
+	"gamil.com":    "gmail.com",
+	"gnail.com":    "gmail.com",
+	"gmal.com":     "gmail.com",
+	"gmaill.com":   "gmail.com",
+	"gmail.co":     "gmail.com",
+	"gmail.con":    "gmail.com",
+	"gmail.cm":     "gmail.com",
+	"gmail.om":     "gmail.com",
+	"hotmial.com":  "hotmail.com",
+	"hotmal.com":   "hotmail.com",
+	"hotmail.con":  "hotmail.com",
+	"hotmai.it":    "hotmail.it",
+	"hotmial.it":   "hotmail.it",
+	"libero.ti":    "libero.it",
+	"liberto.it":   "libero.it",
+	"lbero.it":     "libero.it",
+	"yaho.it":      "yahoo.it",
+	"yahho.it":     "yahoo.it",
+	"yahoo.con":    "yahoo.com",
+	"outlok.it":    "outlook.it",
+	"outlook.con":  "outlook.com",
+	"istruzione.i": "istruzione.it",
+	"istruzone.it": "istruzione.it",
+	"istrzione.it": "istruzione.it",
+}
+
+var emailTLDTypos = map[string]string{
+	"con":  "com",
+	"cmo":  "com",
+	"ocm":  "com",
+	"comm": "com",
+	"itt":  "it",
+	"ti":   "it",
+}
+
+// ParseEmails reads one or more email addresses from the raw value, fixing the common typos.
+// It returns the valid addresses, the corrections applied and the values that are not valid addresses.
+func ParseEmails(raw string) ([]string, []EmailCorrection, []string) {
+	valid := make([]string, 0)
+	corrections := make([]EmailCorrection, 0)
+	invalid := make([]string, 0)
+
+	// the commas in the domains are protected from the split, to be fixed later
+	raw = emailDomainCommaRegex.ReplaceAllString(strings.TrimSpace(raw), "$1\x00$2")
+
+	for _, token := range emailSeparatorsRegex.Split(raw, -1) {
+		token = strings.Trim(token, " <>()[]\"'.:")
+		original := strings.ReplaceAll(token, "\x00", ",")
+		if original == "" || original == "-" {
+			continue
+		}
+		if !strings.ContainsAny(original, "@.") {
+			// words near the addresses, ex. the name of the referent
+			continue
+		}
+
+		address := strings.ToLower(strings.ReplaceAll(token, "\x00", "."))
+		address = strings.TrimPrefix(address, "mailto:")
+		if at := strings.LastIndex(address, "@"); at > 0 {
+			domain := address[at+1:]
+			if fixed, isTypo := emailDomainTypos[domain]; isTypo {
+				domain = fixed
+			} else if dot := strings.LastIndex(domain, "."); dot > 0 {
+				if fixed, isTypo := emailTLDTypos[domain[dot+1:]]; isTypo {
+					domain = domain[:dot+1] + fixed
+				}
+			}
+			address = address[:at+1] + domain
+		}
+
+		if !emailRegex.MatchString(address) || strings.Contains(address, "..") {
+			invalid = append(invalid, original)
+			continue
+		}
+		if address != strings.TrimPrefix(strings.ToLower(original), "mailto:") {
+			corrections = append(corrections, EmailCorrection{Original: original, Corrected: address})
+		}
+		if !containsString(valid, address) {
+			valid = append(valid, address)
+		}
+	}
+
+	return valid, corrections, invalid
+}
+
+// SplitTeachers splits a list of teachers like "Mario Rossi, Anna Bianchi e Luca Verdi" into the single names.
+// Email addresses written together with the names are returned separately.
+func SplitTeachers(raw string) ([]string, string) {
+	names := make([]string, 0)
+	emails := make([]string, 0)
+
+	for _, token := range teacherSeparatorRegex.Split(strings.TrimSpace(raw), -1) {
+		token = strings.Join(strings.Fields(token), " ")
+		if token == "" || token == "-" {
+			continue
+		}
+		if strings.Contains(token, "@") {
+			emails = append(emails, token)
+			continue
+		}
+		duplicate := false
+		for _, name := range names {
+			if strings.EqualFold(name, token) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			names = append(names, token)
+		}
+	}
+
+	return names, strings.Join(emails, " ")
+}
+
+// addContacts merges in the group the teachers and the email addresses of a row.
+func (g *VisitingGroup) addContacts(teachersRaw, emailsRaw string) {
+	teachers, emailsInTeachers := SplitTeachers(teachersRaw)
+	for _, teacher := range teachers {
+		if !containsFold(g.Teachers, teacher) {
+			g.Teachers = append(g.Teachers, teacher)
+		}
+	}
+
+	emails, corrections, invalid := ParseEmails(emailsRaw + " " + emailsInTeachers)
+	for _, email := range emails {
+		if !containsString(g.Emails, email) {
+			g.Emails = append(g.Emails, email)
+		}
+	}
+	for _, correction := range corrections {
+		alreadyCorrected := false
+		for _, existing := range g.EmailCorrections {
+			alreadyCorrected = alreadyCorrected || existing == correction
+		}
+		if !alreadyCorrected {
+			g.EmailCorrections = append(g.EmailCorrections, correction)
+		}
+	}
+	for _, value := range invalid {
+		if !containsString(g.InvalidEmails, value) {
+			g.InvalidEmails = append(g.InvalidEmails, value)
+		}
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferentContact is a class referent, merged between all the groups with the same email or with the same name
+// in the same school when there is no email.
+type ReferentContact struct {
+	Names      []string    `json:"names"`
+	Emails     []string    `json:"emails"`
+	SchoolCode string      `json:"school_code"`
+	GroupCodes []string    `json:"group_codes"`
+	Dates      []time.Time `json:"dates"`
+}
+
+func (c ReferentContact) DisplayName() string {
+	if len(c.Names) > 0 {
+		return strings.Join(c.Names, ", ")
+	}
+	if len(c.Emails) > 0 {
+		return c.Emails[0]
+	}
+	return ""
+}
+
+// CollectReferentContacts builds the de-duplicated list of the class referents of the period.
+func CollectReferentContacts(rows []OutputRow, anagraphicsRef *OutputAnagraphics) []ReferentContact {
+	datesByGroup := make(map[string][]time.Time)
+	groupCodes := make([]string, 0)
+	for _, row := range rows {
+		if row.VisitingGroupCode == "" {
+			continue
+		}
+		if _, ok := datesByGroup[row.VisitingGroupCode]; !ok {
+			groupCodes = append(groupCodes, row.VisitingGroupCode)
+		}
+		dates := datesByGroup[row.VisitingGroupCode]
+		found := false
+		for _, d := range dates {
+			if dateKey(d) == dateKey(row.Date) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dates = append(dates, row.Date)
+		}
+		datesByGroup[row.VisitingGroupCode] = dates
+	}
+	sort.Strings(groupCodes)
+
+	out := make([]*ReferentContact, 0)
+	index := make(map[string]*ReferentContact)
+
+	for _, groupCode := range groupCodes {
+		group := anagraphicsRef.VisitingGroups[groupCode]
+		if len(group.Teachers) == 0 && len(group.Emails) == 0 {
+			continue
+		}
+
+		keys := make([]string, 0, len(group.Emails)+len(group.Teachers))
+		for _, email := range group.Emails {
+			keys = append(keys, "email:"+email)
+		}
+		if len(group.Emails) == 0 {
+			for _, teacher := range group.Teachers {
+				keys = append(keys, "name:"+group.SchoolCode+":"+nameToCode(teacher))
+			}
+		}
+
+		var contact *ReferentContact
+		for _, key := range keys {
+			if existing, ok := index[key]; ok {
+				contact = existing
+				break
+			}
+		}
+		if contact == nil {
+			contact = &ReferentContact{SchoolCode: group.SchoolCode}
+			out = append(out, contact)
+		}
+		for _, key := range keys {
+			index[key] = contact
+		}
+
+		for _, teacher := range group.Teachers {
+			if !containsFold(contact.Names, teacher) {
+				contact.Names = append(contact.Names, teacher)
+			}
+		}
+		for _, email := range group.Emails {
+			if !containsString(contact.Emails, email) {
+				contact.Emails = append(contact.Emails, email)
+			}
+		}
+		contact.GroupCodes = append(contact.GroupCodes, groupCode)
+		contact.Dates = append(contact.Dates, datesByGroup[groupCode]...)
+	}
+
+	result := make([]ReferentContact, 0, len(out))
+	for _, contact := range out {
+		sort.Slice(contact.Dates, func(i, j int) bool {
+			return contact.Dates[i].Before(contact.Dates[j])
+		})
+		result = append(result, *contact)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToLower(result[i].DisplayName()) < strings.ToLower(result[j].DisplayName())
+	})
+	return result
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEmails(t *testing.T) {
+	type testCase struct {
+		input       string
+		valid       []string
+		corrections []EmailCorrection
+		invalid     []string
+	}
+
+	testCases := []testCase{
+		{"", []string{}, []EmailCorrection{}, []string{}},
+		{"-", []string{}, []EmailCorrection{}, []string{}},
+		{"mario.rossi@gmail.com", []string{"mario.rossi@gmail.com"}, []EmailCorrection{}, []string{}},
+		{"Mario.Rossi@Gmail.com", []string{"mario.rossi@gmail.com"}, []EmailCorrection{}, []string{}},
+		{"mario.rossi@gmail,com", []string{"mario.rossi@gmail.com"},
+			[]EmailCorrection{{"mario.rossi@gmail,com", "mario.rossi@gmail.com"}}, []string{}},
+		{"mario.rossi@gmial.com", []string{"mario.rossi@gmail.com"},
+			[]EmailCorrection{{"mario.rossi@gmial.com", "mario.rossi@gmail.com"}}, []string{}},
+		{"anna@scuola.con", []string{"anna@scuola.com"},
+			[]EmailCorrection{{"anna@scuola.con", "anna@scuola.com"}}, []string{}},
+		{"a@libero.it; b@libero.it, c@libero.it / a@libero.it", []string{"a@libero.it", "b@libero.it", "c@libero.it"}, []EmailCorrection{}, []string{}},
+		{"Rossi <mario@x.it>", []string{"mario@x.it"}, []EmailCorrection{}, []string{}},
+		{"mailto:mario@x.it", []string{"mario@x.it"}, []EmailCorrection{}, []string{}},
+		{"mario.rossi.gmail.com", []string{}, []EmailCorrection{}, []string{"mario.rossi.gmail.com"}},
+		{"mario@@gmail.com a@b", []string{}, []EmailCorrection{}, []string{"mario@@gmail.com", "a@b"}},
+		{"mario..rossi@gmail.com", []string{}, []EmailCorrection{}, []string{"mario..rossi@gmail.com"}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			valid, corrections, invalid := ParseEmails(tc.input)
+			assert.Equal(t, tc.valid, valid)
+			assert.Equal(t, tc.corrections, corrections)
+			assert.Equal(t, tc.invalid, invalid)
+		})
+	}
+}
+
+func TestSplitTeachers(t *testing.T) {
+	type testCase struct {
+		input  string
+		names  []string
+		emails string
+	}
+
+	testCases := []testCase{
+		{"", []string{}, ""},
+		{"Mario Rossi", []string{"Mario Rossi"}, ""},
+		{"Mario Rossi, Anna  Bianchi e Luca Verdi", []string{"Mario Rossi", "Anna Bianchi", "Luca Verdi"}, ""},
+		{"Rossi / Bianchi; rossi", []string{"Rossi", "Bianchi"}, ""},
+		{"Rossi - mario@x.it", []string{"Rossi"}, "mario@x.it"},
+		{"Emanuele Esposito", []string{"Emanuele Esposito"}, ""},
+		// an initial is not a separator
+		{"Prof.ssa E. Bianchi", []string{"Prof.ssa E. Bianchi"}, ""},
+		{"Mario Rossi e E. Bianchi", []string{"Mario Rossi", "E. Bianchi"}, ""},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			names, emails := SplitTeachers(tc.input)
+			assert.Equal(t, tc.names, names)
+			assert.Equal(t, tc.emails, emails)
+		})
+	}
+}
+
+func TestCollectReferentContacts(t *testing.T) {
+	day1 := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	group := func(code, school, teachers, emails string) VisitingGroup {
+		out := VisitingGroup{Code: code, SchoolCode: school}
+		out.addContacts(teachers, emails)
+		return out
+	}
+
+	anagraphics := &OutputAnagraphics{
+		VisitingGroups: map[string]VisitingGroup{
+			"a": group("a", "s1", "Mario Rossi", "mario.rossi@gmail,com"),
+			"b": group("b", "s1", "M. Rossi", "Mario.Rossi@gmail.com"),
+			"c": group("c", "s2", "Anna Bianchi", ""),
+			"d": group("d", "s2", "anna bianchi", ""),
+			"e": group("e", "s3", "Anna Bianchi", ""),
+			"f": group("f", "s3", "", ""),
+		},
+	}
+
+	rows := []OutputRow{
+		{VisitingGroupCode: "b", Date: day2},
+		{VisitingGroupCode: "a", Date: day1},
+		{VisitingGroupCode: "a", Date: day1},
+		{VisitingGroupCode: "c", Date: day1},
+		{VisitingGroupCode: "d", Date: day2},
+		{VisitingGroupCode: "e", Date: day2},
+		{VisitingGroupCode: "f", Date: day2},
+	}
+
+	contacts := CollectReferentContacts(rows, anagraphics)
+	if assert.Len(t, contacts, 3) {
+		assert.Equal(t, []string{"Anna Bianchi"}, contacts[0].Names)
+		assert.Equal(t, []string{"c", "d"}, contacts[0].GroupCodes)
+		assert.Equal(t, []time.Time{day1, day2}, contacts[0].Dates)
+
+		assert.Equal(t, []string{"e"}, contacts[1].GroupCodes)
+
+		assert.Equal(t, []string{"Mario Rossi", "M. Rossi"}, contacts[2].Names)
+		assert.Equal(t, []string{"mario.rossi@gmail.com"}, contacts[2].Emails)
+		assert.Equal(t, []string{"a", "b"}, contacts[2].GroupCodes)
+	}
+}
@@ -0,0 +1,11 @@
+package parser
+
+import "time"
+
+// testDay is the day of the test fixtures
+var testDay = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// at returns the given time of testDay
+func at(hour, minute int) time.Time {
+	return testDay.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func HydrateGroups(
-	_ config.WorkflowContext,
+	ctx config.WorkflowContext,
 	rows []Row,
 ) (
 	[]Row,
@@ -21,6 +21,11 @@ func HydrateGroups(
 	outSchools := make([]School, 0, 10)
 	outSchoolClasses := make([]SchoolClass, 0, 10)
 
+	schoolsByRawKey, err := resolveSchools(ctx, rows)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	groupsIndex := make(map[string]int)
 	schoolsIndex := make(map[string]School)
 	schoolClassesIndex := make(map[string]SchoolClass)
@@ -36,7 +41,8 @@ func HydrateGroups(
 			groupMapped, groupAlreadyMapped := groupsIndex[groupCode]
 			if !groupAlreadyMapped {
 
-				schoolCode := nameToCode(row.schoolType) + "/" + nameToCode(row.schoolName)
+				school := schoolsByRawKey[rawSchoolKey(row)]
+				schoolCode := school.Code
 				schoolGroupCode := schoolCode + "/" + nameToCode(row.class) + "/" + nameToCode(row.classSection) + "/" + row.BookingCode
 
 				newGroup := VisitingGroup{
@@ -53,18 +59,15 @@ func HydrateGroups(
 					BookingNotes:        row.BookingNote,
 					OperatorNotes:       row.OperatorNote,
 					SpecialProjectNotes: row.SpecialProjectName,
+					Bus:                 ParseBus(row.Bus, row.Date),
 				}
+				newGroup.addContacts(row.classTeacher, row.classRefEmail)
 				outGroups = append(outGroups, newGroup)
 				groupsIndex[newGroup.Code] = len(outGroups) - 1
 
 				if _, isAlreadyMapped := schoolsIndex[schoolCode]; !isAlreadyMapped {
-					newSchool := School{
-						Code: schoolCode,
-						Type: cleanStringForVisualization(row.schoolType),
-						Name: cleanStringForVisualization(row.schoolName),
-					}
-					schoolsIndex[schoolCode] = newSchool
-					outSchools = append(outSchools, newSchool)
+					schoolsIndex[schoolCode] = school
+					outSchools = append(outSchools, school)
 				}
 
 				if _, isAlreadyMapped := schoolClassesIndex[schoolGroupCode]; !isAlreadyMapped {
@@ -72,6 +75,7 @@ func HydrateGroups(
 						Code:       schoolGroupCode,
 						SchoolCode: schoolCode,
 						Number:     cleanStringForVisualization(row.class),
+						Grade:      ParseClassGrade(row.class),
 						Section:    cleanStringForVisualization(row.classSection),
 					}
 					schoolClassesIndex[schoolGroupCode] = newClass
@@ -99,6 +103,10 @@ func HydrateGroups(
 				if row.classRefEmail != "" && toEnrich.ClassRefEmail == "" {
 					toEnrich.ClassRefEmail = row.classRefEmail
 				}
+				toEnrich.addContacts(row.classTeacher, row.classRefEmail)
+				if row.Bus != "" && toEnrich.Bus.Raw == "" {
+					toEnrich.Bus = ParseBus(row.Bus, row.Date)
+				}
 				outGroups[groupMapped] = toEnrich
 			}
 		}
@@ -2,7 +2,10 @@ package parser
 
 import (
 	"strings"
+	"time"
 	"unicode"
+
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 func nameToCode(raw string) string {
@@ -23,3 +26,52 @@ func removeWhiteSpaces(str string) string {
 func cleanStringForVisualization(raw string) string {
 	return strings.TrimSpace(raw)
 }
+
+// resolveRoomCode maps a room name or alias to the code of the room.
+func resolveRoomCode(raw string) string {
+	code := nameToCode(raw)
+	if knownRoom, isKnown := database.GetKnownRoom(code); isKnown {
+		return knownRoom.Code
+	}
+	return code
+}
+
+func isSameDay(a, b time.Time) bool {
+	ya, ma, da := a.Date()
+	yb, mb, db := b.Date()
+	return ya == yb && ma == mb && da == db
+}
+
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if nameToCode(c) == code {
+			return true
+		}
+	}
+	return false
+}
+
+var languageNamesToCode = map[string]string{
+	"italiano": "it",
+	"inglese":  "en",
+	"english":  "en",
+	"francese": "fr",
+	"tedesco":  "de",
+	"spagnolo": "es",
+}
+
+// normalizeLanguage maps the language as written in the input to its code (ex. "Inglese" -> "en").
+func normalizeLanguage(raw string) string {
+	code := nameToCode(raw)
+	if mapped, ok := languageNamesToCode[code]; ok {
+		return mapped
+	}
+	return code
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -7,11 +7,24 @@ import (
 )
 
 type Input struct {
-	Rows []InputRow
+	Rows         []InputRow
+	Availability []InputAvailability
+}
+
+type InputAvailability struct {
+	Date      time.Time
+	StartTime time.Time
+	EndTime   time.Time
+	AllDay    bool
+	IsAbsence bool
+	Note      string
+
+	operatorRawString string
 }
 
 type InputRow struct {
-	ID int
+	ID        int
+	RowNumber uint
 
 	BookingCode          string
 	Date                 time.Time
@@ -53,6 +66,7 @@ func ToInputRows(rows []reader.OutputRow) []InputRow {
 	for _, input := range rows {
 		out = append(out, InputRow{
 			ID:                          input.ID,
+			RowNumber:                   input.RowNumber(),
 			BookingCode:                 input.BookingCode,
 			Date:                        input.Date,
 			operatorRawString:           input.Operator,
@@ -85,3 +99,21 @@ func ToInputRows(rows []reader.OutputRow) []InputRow {
 
 	return out
 }
+
+func ToInputAvailability(rows []reader.OutputAvailabilityRow) []InputAvailability {
+	out := make([]InputAvailability, 0, len(rows))
+
+	for _, input := range rows {
+		out = append(out, InputAvailability{
+			Date:              input.Date,
+			StartTime:         input.StartTime,
+			EndTime:           input.EndTime,
+			AllDay:            input.AllDay,
+			IsAbsence:         input.IsAbsence,
+			Note:              input.Note,
+			operatorRawString: input.Operator,
+		})
+	}
+
+	return out
+}
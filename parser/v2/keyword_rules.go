@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/pkg/errors"
+)
+
+var defaultKeywordRules = []config.KeywordRule{
+	{
+		Code:   string(HighlightSpecialProject),
+		Kind:   config.KeywordRuleKindHighlight,
+		Fields: []string{"nome progetto speciale"},
+		Regex:  `\S`,
+		Color:  "#9900cc",
+	},
+	{
+		Code:     string(HighlightSpecialNotes),
+		Kind:     config.KeywordRuleKindHighlight,
+		Fields:   []string{"nota prenotazione", "nota operatore"},
+		Keywords: []string{"special"},
+		Color:    "#0066ff",
+	},
+	{
+		Code:     "activity-question-marks",
+		Kind:     config.KeywordRuleKindWarning,
+		Fields:   []string{"evento"},
+		Keywords: []string{"??"},
+		Message:  "QUESTA ATTIVITA' SEMBRA INDETERMINATA",
+	},
+	{
+		Code:    "non-it-lang",
+		Kind:    config.KeywordRuleKindWarning,
+		Fields:  []string{"lingua dell'attività"},
+		Regex:   `\S`,
+		Exclude: `(?i)^\s*it\s*$`,
+		Message: "ATTIVITA' PREVISTA IN LINGUA: {valore}",
+	},
+}
+
+var keywordRuleFields = map[string]func(row Row) string{
+	"codice":                 func(row Row) string { return row.BookingCode },
+	"educatore":              func(row Row) string { return row.operatorRawString },
+	"aula":                   func(row Row) string { return row.roomRawString },
+	"evento":                 func(row Row) string { return row.activityRawString },
+	"lingua dell'attività":   func(row Row) string { return row.activityLanguageRawString },
+	"tipologia":              func(row Row) string { return row.activityTypeRawString },
+	"nota prenotazione":      func(row Row) string { return row.BookingNote },
+	"nota operatore":         func(row Row) string { return row.OperatorNote },
+	"tipologia scuola":       func(row Row) string { return row.schoolType },
+	"nome scuola":            func(row Row) string { return row.schoolName },
+	"insegnante":             func(row Row) string { return row.classTeacher },
+	"email referente":        func(row Row) string { return row.classRefEmail },
+	"bus":                    func(row Row) string { return row.Bus },
+	"acconti":                func(row Row) string { return row.PaymentAdvance },
+	"stato acconti":          func(row Row) string { return row.PaymentAdvanceStatus },
+	"nome progetto speciale": func(row Row) string { return row.SpecialProjectName },
+}
+
+type keywordRule struct {
+	config.KeywordRule
+	regex    *regexp.Regexp
+	exclude  *regexp.Regexp
+	keywords []string
+}
+
+// effectiveKeywordRules merges the default rules with the ones declared in the configuration.
+func effectiveKeywordRules(ctx config.WorkflowContext) ([]keywordRule, error) {
+	merged := make([]config.KeywordRule, 0, len(defaultKeywordRules)+len(ctx.Config.KeywordRules))
+	merged = append(merged, defaultKeywordRules...)
+
+	for _, declared := range ctx.Config.KeywordRules {
+		replaced := false
+		for i, existing := range merged {
+			if existing.Code == declared.Code {
+				merged[i] = declared
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, declared)
+		}
+	}
+
+	out := make([]keywordRule, 0, len(merged))
+	for _, declared := range merged {
+		if declared.Disabled {
+			continue
+		}
+		compiled, err := compileKeywordRule(declared)
+		if err != nil {
+			return nil, errors.Wrapf(err, "regola '%s' non valida", declared.Code)
+		}
+		out = append(out, compiled)
+	}
+
+	return out, nil
+}
+
+func compileKeywordRule(declared config.KeywordRule) (keywordRule, error) {
+	out := keywordRule{KeywordRule: declared}
+
+	if declared.Code == "" {
+		return out, errors.New("manca il codice")
+	}
+	if declared.Kind != config.KeywordRuleKindHighlight && declared.Kind != config.KeywordRuleKindWarning {
+		return out, errors.Errorf("tipo '%s' non valido, deve essere '%s' o '%s'",
+			declared.Kind, config.KeywordRuleKindHighlight, config.KeywordRuleKindWarning)
+	}
+	if _, ok := ParseWarningSeverity(declared.Severity); declared.Severity != "" && !ok {
+		return out, errors.Errorf("gravità '%s' non valida", declared.Severity)
+	}
+	if len(declared.Fields) == 0 {
+		return out, errors.New("nessun campo specificato")
+	}
+	for _, field := range declared.Fields {
+		if _, ok := keywordRuleFields[strings.ToLower(field)]; !ok {
+			return out, errors.Errorf("il campo '%s' non esiste", field)
+		}
+	}
+	if declared.Regex == "" && len(declared.Keywords) == 0 {
+		return out, errors.New("specificare un'espressione regolare o delle parole chiave")
+	}
+
+	var err error
+	if declared.Regex != "" {
+		if out.regex, err = regexp.Compile(declared.Regex); err != nil {
+			return out, errors.Wrap(err, "espressione regolare non valida")
+		}
+	}
+	if declared.Exclude != "" {
+		if out.exclude, err = regexp.Compile(declared.Exclude); err != nil {
+			return out, errors.Wrap(err, "espressione regolare di esclusione non valida")
+		}
+	}
+	for _, keyword := range declared.Keywords {
+		out.keywords = append(out.keywords, strings.ToLower(keyword))
+	}
+
+	return out, nil
+}
+
+// match returns the value of the first field matching the rule.
+func (r keywordRule) match(row Row) (string, bool) {
+	for _, field := range r.Fields {
+		value := strings.TrimSpace(keywordRuleFields[strings.ToLower(field)](row))
+		if value == "" {
+			continue
+		}
+		if r.exclude != nil && r.exclude.MatchString(value) {
+			continue
+		}
+		if r.regex != nil && r.regex.MatchString(value) {
+			return value, true
+		}
+		lowered := strings.ToLower(value)
+		for _, keyword := range r.keywords {
+			if strings.Contains(lowered, keyword) {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (r keywordRule) severity() WarningSeverity {
+	if severity, ok := ParseWarningSeverity(r.Severity); ok {
+		return severity
+	}
+	return SeverityWarning
+}
+
+func (r keywordRule) message(value string) string {
+	return strings.ReplaceAll(r.Message, "{valore}", value)
+}
+
+func buildHighlightKinds(keywordRules []keywordRule) map[HighlightReason]HighlightKind {
+	out := make(map[HighlightReason]HighlightKind)
+	for _, r := range keywordRules {
+		if r.Kind != config.KeywordRuleKindHighlight {
+			continue
+		}
+		out[HighlightReason(r.Code)] = HighlightKind{
+			Code:     HighlightReason(r.Code),
+			Message:  r.Message,
+			Severity: r.severity(),
+			Color:    r.Color,
+		}
+	}
+	return out
+}
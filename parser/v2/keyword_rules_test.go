@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func TestKeywordRulesDefaults(t *testing.T) {
+	keywordRules, err := effectiveKeywordRules(testRuleContext(config.WorkflowContextConfig{}))
+	assert.NoError(t, err)
+
+	warningsFor := func(row Row) []string {
+		out := make([]string, 0)
+		for _, r := range keywordRules {
+			if r.Kind != config.KeywordRuleKindWarning {
+				continue
+			}
+			if value, ok := r.match(row); ok {
+				out = append(out, r.message(value))
+			}
+		}
+		return out
+	}
+
+	type testCase struct {
+		row      Row
+		expected []string
+	}
+
+	testCases := []testCase{
+		{Row{InputRow: InputRow{activityRawString: "Sistema solare"}}, []string{}},
+		{Row{InputRow: InputRow{activityRawString: "Sistema ??"}}, []string{"QUESTA ATTIVITA' SEMBRA INDETERMINATA"}},
+		{Row{InputRow: InputRow{activityLanguageRawString: " IT "}}, []string{}},
+		{Row{InputRow: InputRow{activityLanguageRawString: "EN"}}, []string{"ATTIVITA' PREVISTA IN LINGUA: EN"}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, warningsFor(tc.row))
+		})
+	}
+}
+
+func TestKeywordRulesFromConfiguration(t *testing.T) {
+	keywordRules, err := effectiveKeywordRules(testRuleContext(config.WorkflowContextConfig{
+		KeywordRules: []config.KeywordRule{
+			{Code: "non-it-lang", Disabled: true},
+			{
+				Code:     "disabile",
+				Kind:     config.KeywordRuleKindHighlight,
+				Fields:   []string{"Nota prenotazione", "nota operatore"},
+				Keywords: []string{"DISABILE", "carrozzina"},
+				Message:  "GRUPPO CON DISABILI",
+				Color:    "#ff9900",
+			},
+			{
+				Code:     "ritardo-bus",
+				Kind:     config.KeywordRuleKindWarning,
+				Fields:   []string{"bus"},
+				Regex:    `(?i)ritardo\s+(\d+)`,
+				Message:  "BUS IN RITARDO: {valore}",
+				Severity: "error",
+			},
+		},
+	}))
+	assert.NoError(t, err)
+
+	codes := make([]string, 0)
+	for _, r := range keywordRules {
+		codes = append(codes, r.Code)
+	}
+	assert.Equal(t, []string{"special_project", "special_notes", "activity-question-marks", "disabile", "ritardo-bus"}, codes)
+
+	value, ok := keywordRules[3].match(Row{InputRow: InputRow{OperatorNote: "un bambino in Carrozzina"}})
+	assert.True(t, ok)
+	assert.Equal(t, "un bambino in Carrozzina", value)
+
+	_, ok = keywordRules[4].match(Row{InputRow: InputRow{Bus: "in ritardo"}})
+	assert.False(t, ok)
+	value, ok = keywordRules[4].match(Row{InputRow: InputRow{Bus: "ritardo 20 minuti"}})
+	assert.True(t, ok)
+	assert.Equal(t, "BUS IN RITARDO: ritardo 20 minuti", keywordRules[4].message(value))
+	assert.Equal(t, SeverityError, keywordRules[4].severity())
+
+	kinds := buildHighlightKinds(keywordRules)
+	assert.Equal(t, "#ff9900", kinds["disabile"].Color)
+	assert.Equal(t, "#9900cc", kinds[HighlightSpecialProject].Color)
+}
+
+func TestKeywordRulesValidation(t *testing.T) {
+	invalid := []config.KeywordRule{
+		{Code: "", Kind: config.KeywordRuleKindWarning, Fields: []string{"bus"}, Regex: "x"},
+		{Code: "a", Kind: "other", Fields: []string{"bus"}, Regex: "x"},
+		{Code: "a", Kind: config.KeywordRuleKindWarning, Fields: []string{"unknown"}, Regex: "x"},
+		{Code: "a", Kind: config.KeywordRuleKindWarning, Fields: []string{"bus"}},
+		{Code: "a", Kind: config.KeywordRuleKindWarning, Fields: []string{"bus"}, Regex: "("},
+		{Code: "a", Kind: config.KeywordRuleKindWarning, Fields: []string{"bus"}, Regex: "x", Severity: "fatal"},
+	}
+
+	for i, declared := range invalid {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			_, err := effectiveKeywordRules(testRuleContext(config.WorkflowContextConfig{
+				KeywordRules: []config.KeywordRule{declared},
+			}))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/pkg/errors"
+)
+
+const lunchRoomCode = database.LunchRoomCode
+
+type LunchTurn struct {
+	StartTime          time.Time `json:"start_time"`
+	EndTime            time.Time `json:"end_time"`
+	Seats              int       `json:"seats"`
+	SeatsUsed          int       `json:"seats_used"`
+	VisitingGroupCodes []string  `json:"visiting_group_codes"`
+	RowIDs             []int     `json:"row_ids"`
+	// Configured is false for the turns of rows that do not match any configured turn
+	Configured bool `json:"configured"`
+}
+
+func (t LunchTurn) IsOverbooked() bool {
+	return t.Seats > 0 && t.SeatsUsed > t.Seats
+}
+
+func (t LunchTurn) FreeSeats() int {
+	return t.Seats - t.SeatsUsed
+}
+
+type LunchPlan struct {
+	Date  time.Time   `json:"date"`
+	Turns []LunchTurn `json:"turns"`
+	// Unassigned are the groups that booked lunch without a time and could not be assigned to a turn
+	Unassigned []string `json:"unassigned,omitempty"`
+}
+
+type lunchTurnSetting struct {
+	startHour, startMinute int
+	endHour, endMinute     int
+}
+
+type lunchSettings struct {
+	seats int
+	turns []lunchTurnSetting
+}
+
+func newLunchSettings(ctx config.WorkflowContext) (lunchSettings, error) {
+	out := lunchSettings{
+		seats: ctx.Config.Lunch.Seats,
+	}
+
+	for _, raw := range ctx.Config.Lunch.Turns {
+		parts := strings.Split(raw, "-")
+		if len(parts) != 2 {
+			return lunchSettings{}, errors.Errorf("turno pranzo non valido: '%s' (formato atteso 'HH:MM-HH:MM')", raw)
+		}
+		start, err := time.Parse("15:04", strings.TrimSpace(parts[0]))
+		if err != nil {
+			return lunchSettings{}, errors.Errorf("turno pranzo non valido: '%s' (formato atteso 'HH:MM-HH:MM')", raw)
+		}
+		end, err := time.Parse("15:04", strings.TrimSpace(parts[1]))
+		if err != nil || !end.After(start) {
+			return lunchSettings{}, errors.Errorf("turno pranzo non valido: '%s' (formato atteso 'HH:MM-HH:MM')", raw)
+		}
+		out.turns = append(out.turns, lunchTurnSetting{
+			startHour: start.Hour(), startMinute: start.Minute(),
+			endHour: end.Hour(), endMinute: end.Minute(),
+		})
+	}
+	sort.Slice(out.turns, func(i, j int) bool {
+		ti, tj := out.turns[i], out.turns[j]
+		return ti.startHour*60+ti.startMinute < tj.startHour*60+tj.startMinute
+	})
+
+	return out, nil
+}
+
+func (s lunchSettings) newPlan(date time.Time) *LunchPlan {
+	plan := &LunchPlan{
+		Date: time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location()),
+	}
+	for _, turn := range s.turns {
+		plan.Turns = append(plan.Turns, LunchTurn{
+			StartTime:  time.Date(date.Year(), date.Month(), date.Day(), turn.startHour, turn.startMinute, 0, 0, date.Location()),
+			EndTime:    time.Date(date.Year(), date.Month(), date.Day(), turn.endHour, turn.endMinute, 0, 0, date.Location()),
+			Seats:      s.seats,
+			Configured: true,
+		})
+	}
+	return plan
+}
+
+func isLunchRow(row Row) bool {
+	return row.RoomCode == lunchRoomCode && !row.IsPlaceholderNumeroAttivita
+}
+
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// buildLunchPlans computes, for each day with a lunch, the seats used in each turn.
+// A row belongs to the configured turn it starts in, otherwise to a turn with its own times.
+func buildLunchPlans(rows []Row, anagraphicsRef *OutputAnagraphics, settings lunchSettings) []LunchPlan {
+	plans := make([]LunchPlan, 0)
+
+	for _, row := range rows {
+		if !isLunchRow(row) || row.StartTime.IsZero() {
+			continue
+		}
+
+		p := planIndexFor(&plans, settings, row.StartTime)
+		plan := &plans[p]
+
+		turnIndex := -1
+		for i, turn := range plan.Turns {
+			if turn.Configured && !row.StartTime.Before(turn.StartTime) && row.StartTime.Before(turn.EndTime) {
+				turnIndex = i
+				break
+			}
+		}
+		if turnIndex < 0 {
+			for i, turn := range plan.Turns {
+				if !turn.Configured && turn.StartTime.Equal(row.StartTime) && turn.EndTime.Equal(row.EndTime) {
+					turnIndex = i
+					break
+				}
+			}
+		}
+		if turnIndex < 0 {
+			plan.Turns = append(plan.Turns, LunchTurn{
+				StartTime: row.StartTime,
+				EndTime:   row.EndTime,
+				Seats:     settings.seats,
+			})
+			turnIndex = len(plan.Turns) - 1
+		}
+
+		addToLunchTurn(&plan.Turns[turnIndex], row, anagraphicsRef)
+	}
+
+	sortLunchPlans(plans)
+	return plans
+}
+
+// planIndexFor returns the plan of the day, adding it when missing.
+func planIndexFor(plans *[]LunchPlan, settings lunchSettings, date time.Time) int {
+	for i, plan := range *plans {
+		if dateKey(plan.Date) == dateKey(date) {
+			return i
+		}
+	}
+	*plans = append(*plans, *settings.newPlan(date))
+	return len(*plans) - 1
+}
+
+func addToLunchTurn(turn *LunchTurn, row Row, anagraphicsRef *OutputAnagraphics) {
+	turn.RowIDs = append(turn.RowIDs, row.ID)
+	if row.VisitingGroupCode != "" && !containsString(turn.VisitingGroupCodes, row.VisitingGroupCode) {
+		turn.VisitingGroupCodes = append(turn.VisitingGroupCodes, row.VisitingGroupCode)
+		turn.SeatsUsed += anagraphicsRef.VisitingGroups[row.VisitingGroupCode].Composition.NumTotal()
+	}
+}
+
+func sortLunchPlans(plans []LunchPlan) {
+	for _, plan := range plans {
+		sort.SliceStable(plan.Turns, func(i, j int) bool {
+			return plan.Turns[i].StartTime.Before(plan.Turns[j].StartTime)
+		})
+		for _, turn := range plan.Turns {
+			sort.Strings(turn.VisitingGroupCodes)
+			sort.Ints(turn.RowIDs)
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].Date.Before(plans[j].Date)
+	})
+}
+
+// PlanLunches computes the lunch turns of each day and proposes a turn for the groups that booked lunch without a time.
+// The proposed turn is the earliest configured one with enough free seats not overlapping the other activities of the group,
+// or the least crowded one when none has enough seats. The lunches that can not be placed are kept without a time
+// and reported among the unassigned ones of the day.
+func PlanLunches(ctx config.WorkflowContext, rows []Row, anagraphicsRef *OutputAnagraphics) ([]Row, []LunchPlan, error) {
+	log := ctx.Logger
+
+	settings, err := newLunchSettings(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toPlan := make([]int, 0)
+	for i, row := range rows {
+		if !row.StartTime.IsZero() {
+			continue
+		}
+		if !isLunchRow(row) {
+			return nil, nil, errors.Errorf("l'attività della riga %d non ha un orario", row.RowNumber)
+		}
+		toPlan = append(toPlan, i)
+	}
+
+	sort.Slice(toPlan, func(i, j int) bool {
+		ri, rj := rows[toPlan[i]], rows[toPlan[j]]
+		if !ri.Date.Equal(rj.Date) {
+			return ri.Date.Before(rj.Date)
+		}
+		if ri.RowNumber != rj.RowNumber {
+			return ri.RowNumber < rj.RowNumber
+		}
+		return ri.ID < rj.ID
+	})
+
+	plans := buildLunchPlans(rows, anagraphicsRef, settings)
+
+	for _, i := range toPlan {
+		row := rows[i]
+		headcount := anagraphicsRef.VisitingGroups[row.VisitingGroupCode].Composition.NumTotal()
+		plan := &plans[planIndexFor(&plans, settings, row.Date)]
+
+		chosen := -1
+		for t, turn := range plan.Turns {
+			if !turn.Configured || groupIsBusyDuring(rows, row, turn.StartTime, turn.EndTime) {
+				continue
+			}
+			if turn.Seats <= 0 || turn.SeatsUsed+headcount <= turn.Seats {
+				chosen = t
+				break
+			}
+			if chosen < 0 || turn.SeatsUsed < plan.Turns[chosen].SeatsUsed {
+				chosen = t
+			}
+		}
+
+		if chosen < 0 {
+			log.Warnf("nessun turno pranzo disponibile per il gruppo %s del %s, il pranzo resta senza orario",
+				row.BookingCode, row.Date.Format("02/01"))
+			plan.Unassigned = append(plan.Unassigned, row.VisitingGroupCode)
+			continue
+		}
+
+		turn := &plan.Turns[chosen]
+		log.Infof("proposed lunch turn %s-%s for group %s on %s",
+			turn.StartTime.Format("15:04"), turn.EndTime.Format("15:04"), row.BookingCode, row.Date.Format("02/01"))
+
+		row.StartTime = turn.StartTime
+		row.EndTime = turn.EndTime
+		row.Duration = row.EndTime.Sub(row.StartTime)
+		row.LunchTurnProposed = true
+		rows[i] = row
+		addToLunchTurn(turn, row, anagraphicsRef)
+	}
+
+	sortLunchPlans(plans)
+	return rows, plans, nil
+}
+
+func groupIsBusyDuring(rows []Row, lunchRow Row, start, end time.Time) bool {
+	if lunchRow.VisitingGroupCode == "" {
+		return false
+	}
+	for _, other := range rows {
+		if other.ID == lunchRow.ID || other.VisitingGroupCode != lunchRow.VisitingGroupCode ||
+			other.StartTime.IsZero() || other.EndTime.IsZero() {
+			continue
+		}
+		if other.StartTime.Before(end) && start.Before(other.EndTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// overbookedLunchTurns indexes by row ID the overbooked turns.
+func overbookedLunchTurns(plans []LunchPlan) map[int]LunchTurn {
+	out := make(map[int]LunchTurn)
+	for _, plan := range plans {
+		for _, turn := range plan.Turns {
+			if !turn.IsOverbooked() {
+				continue
+			}
+			for _, id := range turn.RowIDs {
+				out[id] = turn
+			}
+		}
+	}
+	return out
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
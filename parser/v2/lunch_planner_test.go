@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func TestPlanLunches(t *testing.T) {
+	row := func(id int, room, group string, start, end time.Time) Row {
+		return Row{
+			InputRow:          InputRow{ID: id, RowNumber: uint(id), Date: testDay, StartTime: start, EndTime: end},
+			RoomCode:          room,
+			VisitingGroupCode: group,
+		}
+	}
+	group := func(code string, num int) VisitingGroup {
+		return VisitingGroup{Code: code, Composition: GroupComposition{NumPaying: num}}
+	}
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			lunchRoomCode: {Code: lunchRoomCode},
+		},
+		VisitingGroups: map[string]VisitingGroup{
+			"a": group("a", 40),
+			"b": group("b", 30),
+			"c": group("c", 25),
+			"d": group("d", 20),
+		},
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		Lunch: config.LunchConfig{Seats: 60, Turns: []string{"12:00-12:45", "12:45-13:30", "13:30-14:15"}},
+	})
+
+	rows := []Row{
+		row(1, lunchRoomCode, "a", at(12, 0), at(12, 45)),
+		// b is busy during the second turn and the first one has not enough seats
+		row(2, "aula1", "b", at(12, 45), at(13, 30)),
+		row(3, lunchRoomCode, "b", time.Time{}, time.Time{}),
+		row(4, lunchRoomCode, "c", time.Time{}, time.Time{}),
+		row(5, lunchRoomCode, "d", time.Time{}, time.Time{}),
+	}
+
+	out, plans, err := PlanLunches(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+	assert.Len(t, out, 5)
+
+	assert.Equal(t, at(13, 30), out[2].StartTime)
+	assert.Equal(t, at(14, 15), out[2].EndTime)
+	assert.True(t, out[2].LunchTurnProposed)
+
+	assert.Equal(t, at(12, 45), out[3].StartTime)
+	assert.True(t, out[3].LunchTurnProposed)
+
+	assert.Equal(t, at(12, 0), out[4].StartTime)
+	assert.True(t, out[4].LunchTurnProposed)
+
+	assert.False(t, out[0].LunchTurnProposed)
+
+	if assert.Len(t, plans, 1) && assert.Len(t, plans[0].Turns, 3) {
+		turns := plans[0].Turns
+		assert.Equal(t, []string{"a", "d"}, turns[0].VisitingGroupCodes)
+		assert.Equal(t, 60, turns[0].SeatsUsed)
+		assert.False(t, turns[0].IsOverbooked())
+		assert.Equal(t, []string{"c"}, turns[1].VisitingGroupCodes)
+		assert.Equal(t, []string{"b"}, turns[2].VisitingGroupCodes)
+		assert.Empty(t, plans[0].Unassigned)
+	}
+}
+
+func TestPlanLunchesWithoutFreeTurns(t *testing.T) {
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			lunchRoomCode: {Code: lunchRoomCode},
+		},
+		VisitingGroups: map[string]VisitingGroup{
+			"a": {Code: "a", Composition: GroupComposition{NumPaying: 20}},
+		},
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		Lunch: config.LunchConfig{Seats: 10, Turns: []string{"12:00-13:00"}},
+	})
+
+	rows := []Row{
+		{InputRow: InputRow{ID: 1, RowNumber: 1, Date: testDay, StartTime: at(11, 30), EndTime: at(12, 30)}, RoomCode: "aula1", VisitingGroupCode: "a"},
+		{InputRow: InputRow{ID: 2, RowNumber: 2, Date: testDay}, RoomCode: lunchRoomCode, VisitingGroupCode: "a"},
+	}
+
+	out, plans, err := PlanLunches(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+	// the lunch is kept without a time
+	if assert.Len(t, out, 2) {
+		assert.True(t, out[1].StartTime.IsZero())
+		assert.False(t, out[1].LunchTurnProposed)
+	}
+	if assert.Len(t, plans, 1) {
+		assert.Equal(t, []string{"a"}, plans[0].Unassigned)
+	}
+
+	warned, err := EmitWarnings(ctx, out, anagraphics, plans, nil)
+	assert.NoError(t, err)
+	codes := make([]string, 0)
+	for _, w := range warned[1].Warnings {
+		codes = append(codes, w.Code)
+	}
+	assert.Contains(t, codes, "lunch-turn-missing")
+
+	_, _, err = PlanLunches(ctx, []Row{{InputRow: InputRow{ID: 3, RowNumber: 3, Date: testDay}, RoomCode: "aula1"}}, anagraphics)
+	assert.Error(t, err)
+
+	ctx.Config.Lunch.Turns = []string{"13:00"}
+	_, _, err = PlanLunches(ctx, nil, anagraphics)
+	assert.Error(t, err)
+}
+
+func TestPlanLunchesWithoutConfiguration(t *testing.T) {
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			lunchRoomCode: {Code: lunchRoomCode},
+		},
+		VisitingGroups: map[string]VisitingGroup{
+			"a": {Code: "a", Composition: GroupComposition{NumPaying: 200}},
+			"b": {Code: "b", Composition: GroupComposition{NumPaying: 20}},
+		},
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{})
+
+	rows := []Row{
+		{InputRow: InputRow{ID: 1, RowNumber: 1, Date: testDay, StartTime: at(12, 0), EndTime: at(13, 0)}, RoomCode: lunchRoomCode, VisitingGroupCode: "a"},
+		{InputRow: InputRow{ID: 2, RowNumber: 2, Date: testDay}, RoomCode: lunchRoomCode, VisitingGroupCode: "b"},
+	}
+
+	out, plans, err := PlanLunches(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+	assert.Len(t, out, 2)
+	assert.True(t, out[1].StartTime.IsZero())
+	if assert.Len(t, plans, 1) && assert.Len(t, plans[0].Turns, 1) {
+		// without seats the turn is never overbooked
+		assert.Equal(t, 0, plans[0].Turns[0].Seats)
+		assert.False(t, plans[0].Turns[0].IsOverbooked())
+		assert.Equal(t, []string{"b"}, plans[0].Unassigned)
+	}
+}
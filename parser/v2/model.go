@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 type HighlightReason string
@@ -25,6 +29,9 @@ type Room struct {
 	ShowActivityNamesInside        bool   `json:"-"`
 	AlwaysShow                     bool   `json:"-"`
 	DoesNotRequireOperator         bool   `json:"-"`
+
+	GroupingPolicy    database.GroupingPolicy `json:"-"`
+	GroupingTolerance time.Duration           `json:"-"`
 }
 
 type Row struct {
@@ -33,22 +40,108 @@ type Row struct {
 	OperatorCode      string
 	VisitingGroupCode string
 	ActivityCode      string
+	OperatorSuggested bool
+	LunchTurnProposed bool
 
 	Warnings []Warning
 }
 
 type Operator struct {
-	Code            string `json:"code"`
-	Name            string `json:"name"`
-	Known           bool   `json:"is_known"`
-	BackgroundColor string `json:"-"`
+	Code            string                 `json:"code"`
+	Name            string                 `json:"name"`
+	Known           bool                   `json:"is_known"`
+	BackgroundColor string                 `json:"-"`
+	Availability    []OperatorAvailability `json:"availability,omitempty"`
+	Skills          OperatorSkills         `json:"skills"`
+}
+
+// AbsenceDuring returns the first absence overlapping the given time range, if any.
+func (o Operator) AbsenceDuring(start, end time.Time) (OperatorAvailability, bool) {
+	for _, a := range o.Availability {
+		if a.IsAbsence && a.Overlaps(start, end) {
+			return a, true
+		}
+	}
+	return OperatorAvailability{}, false
+}
+
+// IsWorkingDuring tells whether the given time range is covered by the declared working hours.
+// Operators without declared working hours for the day are considered available.
+func (o Operator) IsWorkingDuring(start, end time.Time) bool {
+	workingHours := make([]OperatorAvailability, 0)
+	for _, a := range o.Availability {
+		if !a.IsAbsence && isSameDay(a.Date, start) {
+			workingHours = append(workingHours, a)
+		}
+	}
+	if len(workingHours) == 0 {
+		return true
+	}
+
+	sort.Slice(workingHours, func(i, j int) bool {
+		return workingHours[i].StartTime.Before(workingHours[j].StartTime)
+	})
+
+	// walk the sorted working hours, checking they cover the whole range without gaps
+	coveredUpTo := start
+	for _, a := range workingHours {
+		if a.StartTime.After(coveredUpTo) {
+			break
+		}
+		if a.EndTime.After(coveredUpTo) {
+			coveredUpTo = a.EndTime
+		}
+	}
+
+	return !coveredUpTo.Before(end)
+}
+
+type OperatorSkills struct {
+	Rooms         []string `json:"rooms,omitempty"`
+	ActivityTypes []string `json:"activity_types,omitempty"`
+	Languages     []string `json:"languages,omitempty"`
+}
+
+func (s OperatorSkills) CoversRoom(roomCode string) bool {
+	return len(s.Rooms) == 0 || containsCode(s.Rooms, roomCode)
+}
+
+func (s OperatorSkills) CoversActivityType(activityTypeCode string) bool {
+	return len(s.ActivityTypes) == 0 || containsCode(s.ActivityTypes, activityTypeCode)
+}
+
+func (s OperatorSkills) CoversLanguage(language string) bool {
+	language = normalizeLanguage(language)
+	if len(s.Languages) == 0 || language == "" || language == "it" {
+		return true
+	}
+	for _, spoken := range s.Languages {
+		if normalizeLanguage(spoken) == language {
+			return true
+		}
+	}
+	return false
+}
+
+type OperatorAvailability struct {
+	Date      time.Time `json:"date"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	AllDay    bool      `json:"all_day"`
+	IsAbsence bool      `json:"is_absence"`
+	Note      string    `json:"note,omitempty"`
+}
+
+func (a OperatorAvailability) Overlaps(start, end time.Time) bool {
+	return a.StartTime.Before(end) && start.Before(a.EndTime)
 }
 
 type Activity struct {
-	Code     string `json:"code"`
-	TypeCode string `json:"type_code"`
-	Name     string `json:"name"`
-	Language string `json:"lang"`
+	Code        string `json:"code"`
+	TypeCode    string `json:"type_code"`
+	Name        string `json:"name"`
+	Language    string `json:"lang"`
+	CatalogCode string `json:"catalog_code,omitempty"`
 }
 
 type ActivityType struct {
@@ -57,22 +150,46 @@ type ActivityType struct {
 }
 
 type VisitingGroup struct {
-	Code                string
-	SchoolCode          string
-	SchoolClassCode     string
-	Composition         GroupComposition
-	ClassTeacher        string
-	ClassRefEmail       string
+	Code            string
+	SchoolCode      string
+	SchoolClassCode string
+	Composition     GroupComposition
+	ClassTeacher    string
+	ClassRefEmail   string
+	// Teachers and Emails are the normalized contacts of the class referents
+	Teachers            []string
+	Emails              []string
+	EmailCorrections    []EmailCorrection
+	InvalidEmails       []string
 	BookingNotes        string
 	OperatorNotes       string
 	SpecialProjectNotes string
 	Highlights          []HighlightReason
+	Bus                 BusInfo
 }
 
 type School struct {
-	Code string `json:"code"`
-	Type string `json:"type"`
-	Name string `json:"name"`
+	Code      string      `json:"code"`
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Level     SchoolLevel `json:"level,omitempty"`
+	ShortName string      `json:"short_name,omitempty"`
+	City      string      `json:"city,omitempty"`
+	// Aliases are the other names used in the input for the same school
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// DisplayName is the short name of the school if known, the canonical name otherwise.
+func (s School) DisplayName() string {
+	if s.ShortName != "" {
+		return s.ShortName
+	}
+	return s.Name
+}
+
+// DisplayType is the abbreviated school type (ex. "Primaria" -> "EL").
+func (s School) DisplayType() string {
+	return rewriteSchoolNameWithRules(s.Type)
 }
 
 func (s School) FullDescription() string {
@@ -104,7 +221,9 @@ type SchoolClass struct {
 	Code       string `json:"code"`
 	SchoolCode string `json:"school_code"`
 	Number     string `json:"number"`
-	Section    string `json:"section"`
+	// Grade is the numeric grade parsed from Number, 0 if not recognized
+	Grade   int    `json:"grade,omitempty"`
+	Section string `json:"section"`
 }
 
 func (s SchoolClass) FullDescription() string {
@@ -145,9 +264,53 @@ func (c GroupComposition) NumTotal() int {
 type PaymentStatus struct {
 	PaymentAdvance       string `json:"advance"`
 	PaymentAdvanceStatus string `json:"advance_status"`
+	// AdvanceAmount and AdvanceState are parsed from the raw values
+	AdvanceAmount Money        `json:"advance_amount"`
+	AdvanceState  PaymentState `json:"advance_state,omitempty"`
+}
+
+type WarningSeverity string
+
+const (
+	SeverityInfo    WarningSeverity = "info"
+	SeverityWarning WarningSeverity = "warning"
+	SeverityError   WarningSeverity = "error"
+)
+
+// Rank orders the severities, from the least to the most severe.
+func (s WarningSeverity) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 0
+	case SeverityError:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func ParseWarningSeverity(raw string) (WarningSeverity, bool) {
+	switch s := WarningSeverity(strings.ToLower(strings.TrimSpace(raw))); s {
+	case SeverityInfo, SeverityWarning, SeverityError:
+		return s, true
+	}
+	return "", false
 }
 
 type Warning struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code     string          `json:"code"`
+	Message  string          `json:"message"`
+	Severity WarningSeverity `json:"severity"`
+}
+
+// IsRelevant tells whether the warning should be pointed out, not just reported.
+func (w Warning) IsRelevant() bool {
+	return w.Severity.Rank() >= SeverityWarning.Rank()
+}
+
+type HighlightKind struct {
+	Code     HighlightReason `json:"code"`
+	Message  string          `json:"message,omitempty"`
+	Severity WarningSeverity `json:"severity,omitempty"`
+	Color    string          `json:"color,omitempty"`
 }
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperatorIsWorkingDuring(t *testing.T) {
+	workingHours := func(from, to time.Time) OperatorAvailability {
+		return OperatorAvailability{Date: at(12, 0), StartTime: from, EndTime: to}
+	}
+
+	type testCase struct {
+		availability []OperatorAvailability
+		start, end   time.Time
+		expected     bool
+	}
+
+	testCases := []testCase{
+		{nil, at(9, 0), at(10, 0), true},
+		{[]OperatorAvailability{workingHours(at(8, 0), at(12, 0))}, at(9, 0), at(10, 0), true},
+		{[]OperatorAvailability{workingHours(at(8, 0), at(12, 0))}, at(11, 30), at(12, 30), false},
+		{[]OperatorAvailability{workingHours(at(13, 0), at(17, 0)), workingHours(at(8, 0), at(13, 0))}, at(12, 30), at(13, 30), true},
+		{[]OperatorAvailability{workingHours(at(8, 0), at(12, 0)), workingHours(at(13, 0), at(17, 0))}, at(11, 30), at(13, 30), false},
+		{[]OperatorAvailability{{Date: at(12, 0), StartTime: at(8, 0), EndTime: at(9, 0), IsAbsence: true}}, at(11, 30), at(12, 30), true},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			operator := Operator{Availability: testCase.availability}
+			assert.Equal(t, testCase.expected, operator.IsWorkingDuring(testCase.start, testCase.end))
+		})
+	}
+}
+
+func TestOperatorSkillsCoverage(t *testing.T) {
+	skills := OperatorSkills{
+		Rooms:         []string{"Planetario", "aula1"},
+		ActivityTypes: []string{"laboratorio"},
+		Languages:     []string{"EN", "francese"},
+	}
+
+	assert.True(t, skills.CoversRoom("planetario"))
+	assert.True(t, skills.CoversRoom("aula1"))
+	assert.False(t, skills.CoversRoom("museo"))
+
+	assert.True(t, skills.CoversActivityType("laboratorio"))
+	assert.False(t, skills.CoversActivityType("visita"))
+
+	assert.True(t, skills.CoversLanguage(""))
+	assert.True(t, skills.CoversLanguage("IT"))
+	assert.True(t, skills.CoversLanguage("inglese"))
+	assert.True(t, skills.CoversLanguage("fr"))
+	assert.False(t, skills.CoversLanguage("de"))
+
+	assert.True(t, OperatorSkills{}.CoversRoom("museo"))
+	assert.True(t, OperatorSkills{}.CoversLanguage("de"))
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func HydrateOperatorAvailability(
+	ctx config.WorkflowContext,
+	availability []InputAvailability,
+	operators []Operator,
+) ([]Operator, error) {
+	operatorsIndex := make(map[string]int)
+	for i, operator := range operators {
+		operatorsIndex[operator.Code] = i
+	}
+
+	for _, entry := range availability {
+		operatorCode := nameToCode(entry.operatorRawString)
+		if operatorCode == "" {
+			continue
+		}
+
+		// resolve aliases of known operators the same way rows do
+		resolved := buildNewOperator(operatorCode, strings.TrimSpace(entry.operatorRawString))
+
+		index, ok := operatorsIndex[resolved.Code]
+		if !ok {
+			ctx.Logger.Debugf("operator %s is referenced only in the availability data", resolved.Code)
+			operators = append(operators, resolved)
+			index = len(operators) - 1
+			operatorsIndex[resolved.Code] = index
+		}
+
+		operators[index].Availability = append(operators[index].Availability, OperatorAvailability{
+			Date:      entry.Date,
+			StartTime: entry.StartTime,
+			EndTime:   entry.EndTime,
+			AllDay:    entry.AllDay,
+			IsAbsence: entry.IsAbsence,
+			Note:      cleanStringForVisualization(entry.Note),
+		})
+	}
+
+	return operators, nil
+}
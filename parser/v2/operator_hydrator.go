@@ -53,9 +53,7 @@ func buildNewOperator(code string, name string) Operator {
 
 	backgroundColor := knownOperator.BackgroundColor
 
-	if !isKnown {
-		backgroundColor = pickColor("op/" + code)
-	} else {
+	if isKnown {
 		if len(knownOperator.Name) > 0 {
 			name = knownOperator.Name
 		}
@@ -67,5 +65,39 @@ func buildNewOperator(code string, name string) Operator {
 		Name:            cleanStringForVisualization(name),
 		Known:           isKnown,
 		BackgroundColor: backgroundColor,
+		Skills: OperatorSkills{
+			Rooms:         knownOperator.Skills.Rooms,
+			ActivityTypes: knownOperator.Skills.ActivityTypes,
+			Languages:     knownOperator.Skills.Languages,
+		},
 	}
 }
+
+// assignOperatorSkills applies the skills configured for the operators, matched by name, code or alias.
+// The configured skills replace the ones of the known operator.
+func assignOperatorSkills(ctx config.WorkflowContext, operators []Operator) []Operator {
+	for key, skills := range ctx.Config.OperatorSkills {
+		code := nameToCode(key)
+		if knownOperator, isKnown := database.GetKnownOperator(code); isKnown {
+			code = knownOperator.Code
+		}
+
+		for i, operator := range operators {
+			if operator.Code != code && nameToCode(operator.Name) != code {
+				continue
+			}
+
+			rooms := make([]string, 0, len(skills.Rooms))
+			for _, room := range skills.Rooms {
+				rooms = append(rooms, resolveRoomCode(room))
+			}
+			operators[i].Skills = OperatorSkills{
+				Rooms:         rooms,
+				ActivityTypes: skills.ActivityTypes,
+				Languages:     skills.Languages,
+			}
+			ctx.Logger.Debugf("operator %s has the configured skills %+v", operator.Code, operators[i].Skills)
+		}
+	}
+	return operators
+}
@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+type OperatorSuggestion struct {
+	RowID        int       `json:"row_id"`
+	BookingCode  string    `json:"booking_code"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	RoomCode     string    `json:"room_code"`
+	ActivityCode string    `json:"activity_code"`
+	OperatorCode string    `json:"operator_code"`
+}
+
+type operatorAssignment struct {
+	roomCode     string
+	activityCode string
+	startTime    time.Time
+	endTime      time.Time
+}
+
+// SuggestOperators proposes an operator for the rows that need one and have none.
+// Existing assignments are kept fixed; each proposal respects the operators' availability and skills,
+// never double-books an operator and prefers the operators with the lowest workload in the day.
+func SuggestOperators(ctx config.WorkflowContext, rows []Row, anagraphicsRef *OutputAnagraphics) ([]Row, []OperatorSuggestion, error) {
+	log := ctx.Logger
+
+	candidates := make([]Operator, 0, len(anagraphicsRef.Operators))
+	for _, operator := range anagraphicsRef.Operators {
+		if operator.Known {
+			candidates = append(candidates, operator)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return strings.Compare(candidates[i].Code, candidates[j].Code) < 0
+	})
+
+	// index the fixed assignments
+	assignments := make(map[string][]operatorAssignment)
+	toAssign := make([]int, 0)
+
+	for i, row := range rows {
+		if row.IsPlaceholderNumeroAttivita || row.StartTime.IsZero() || row.EndTime.IsZero() {
+			continue
+		}
+		if row.OperatorCode != "" {
+			assignments[row.OperatorCode] = append(assignments[row.OperatorCode], operatorAssignment{
+				roomCode:     row.RoomCode,
+				activityCode: row.ActivityCode,
+				startTime:    row.StartTime,
+				endTime:      row.EndTime,
+			})
+			continue
+		}
+		room, ok := anagraphicsRef.Rooms[row.RoomCode]
+		if !ok || room.AllowMissingOperator || room.DoesNotRequireOperator {
+			continue
+		}
+		toAssign = append(toAssign, i)
+	}
+
+	// assign in a stable order, earliest activities first
+	sort.Slice(toAssign, func(i, j int) bool {
+		ri, rj := rows[toAssign[i]], rows[toAssign[j]]
+		if !ri.StartTime.Equal(rj.StartTime) {
+			return ri.StartTime.Before(rj.StartTime)
+		}
+		if !ri.EndTime.Equal(rj.EndTime) {
+			return ri.EndTime.Before(rj.EndTime)
+		}
+		if ri.RoomCode != rj.RoomCode {
+			return strings.Compare(ri.RoomCode, rj.RoomCode) < 0
+		}
+		return ri.ID < rj.ID
+	})
+
+	suggestions := make([]OperatorSuggestion, 0)
+
+	for _, rowIndex := range toAssign {
+		row := rows[rowIndex]
+		activity := anagraphicsRef.Activities[row.ActivityCode]
+		room := anagraphicsRef.Rooms[row.RoomCode]
+
+		bestCode := ""
+		bestSharesActivity := false
+		var bestWorkload time.Duration
+
+		for _, candidate := range candidates {
+			if !operatorCanRun(candidate, row, activity) {
+				continue
+			}
+
+			sharesActivity, conflicts := false, false
+			for _, assigned := range assignments[candidate.Code] {
+				if !assigned.startTime.Before(row.EndTime) || !row.StartTime.Before(assigned.endTime) {
+					continue
+				}
+				if groupedWith(room, assigned, row) {
+					// same grouped activity, can be run together
+					sharesActivity = true
+				} else {
+					conflicts = true
+					break
+				}
+			}
+			if conflicts {
+				continue
+			}
+
+			workload := workloadInDay(assignments[candidate.Code], row.StartTime)
+
+			// prefer the operator already running the same grouped activity, then the less busy one
+			better := bestCode == "" ||
+				(sharesActivity && !bestSharesActivity) ||
+				(sharesActivity == bestSharesActivity && workload < bestWorkload)
+
+			if better {
+				bestCode = candidate.Code
+				bestSharesActivity = sharesActivity
+				bestWorkload = workload
+			}
+		}
+
+		if bestCode == "" {
+			log.Warnf("no operator can be suggested for activity %v in room %s at %s",
+				row.ID, row.RoomCode, row.StartTime.Format("02/01 15:04"))
+			continue
+		}
+
+		log.Debugf("suggesting operator %s for activity %v in room %s at %s",
+			bestCode, row.ID, row.RoomCode, row.StartTime.Format("02/01 15:04"))
+
+		row.OperatorCode = bestCode
+		row.OperatorSuggested = true
+		rows[rowIndex] = row
+
+		if !bestSharesActivity {
+			assignments[bestCode] = append(assignments[bestCode], operatorAssignment{
+				roomCode:     row.RoomCode,
+				activityCode: row.ActivityCode,
+				startTime:    row.StartTime,
+				endTime:      row.EndTime,
+			})
+		}
+
+		suggestions = append(suggestions, OperatorSuggestion{
+			RowID:        row.ID,
+			BookingCode:  row.BookingCode,
+			StartTime:    row.StartTime,
+			EndTime:      row.EndTime,
+			RoomCode:     row.RoomCode,
+			ActivityCode: row.ActivityCode,
+			OperatorCode: bestCode,
+		})
+	}
+
+	log.Infof("suggested an operator for %d of %d activities without one", len(suggestions), len(toAssign))
+
+	return rows, suggestions, nil
+}
+
+// groupedWith tells whether the row is shown in the same group of the assigned activity,
+// following the grouping policy of the room as the aggregator does.
+func groupedWith(room Room, assigned operatorAssignment, row Row) bool {
+	if !room.GroupActivities || room.GroupingPolicy == database.GroupingNone ||
+		assigned.roomCode != row.RoomCode || assigned.activityCode == "" || row.ActivityCode == "" {
+		return false
+	}
+	switch room.GroupingPolicy {
+	case database.GroupingOverlap:
+		return absDuration(row.StartTime.Sub(assigned.startTime)) <= room.GroupingTolerance &&
+			absDuration(row.EndTime.Sub(assigned.endTime)) <= room.GroupingTolerance
+	case database.GroupingSameTimeAndActivity:
+		if assigned.activityCode != row.ActivityCode {
+			return false
+		}
+	}
+	return assigned.startTime.Equal(row.StartTime) && assigned.endTime.Equal(row.EndTime)
+}
+
+func operatorCanRun(operator Operator, row Row, activity Activity) bool {
+	if _, isAbsent := operator.AbsenceDuring(row.StartTime, row.EndTime); isAbsent {
+		return false
+	}
+	if !operator.IsWorkingDuring(row.StartTime, row.EndTime) {
+		return false
+	}
+	if !operator.Skills.CoversRoom(row.RoomCode) {
+		return false
+	}
+	if activity.TypeCode != "" && !operator.Skills.CoversActivityType(activity.TypeCode) {
+		return false
+	}
+	return operator.Skills.CoversLanguage(activity.Language)
+}
+
+func workloadInDay(assignments []operatorAssignment, day time.Time) time.Duration {
+	total := time.Duration(0)
+	for _, assigned := range assignments {
+		if isSameDay(assigned.startTime, day) {
+			total += assigned.endTime.Sub(assigned.startTime)
+		}
+	}
+	return total
+}
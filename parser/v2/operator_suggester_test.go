@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+func suggesterRow(id int, room, activity, operator string, start, end time.Time) Row {
+	return Row{
+		InputRow:     InputRow{ID: id, StartTime: start, EndTime: end},
+		RoomCode:     room,
+		ActivityCode: activity,
+		OperatorCode: operator,
+	}
+}
+
+func TestSuggestOperators(t *testing.T) {
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			"aula1": {Code: "aula1", GroupActivities: true},
+			"aula2": {Code: "aula2"},
+			"aula3": {Code: "aula3", DoesNotRequireOperator: true},
+		},
+		Operators: map[string]Operator{
+			"anna":  {Code: "anna", Known: true},
+			"bruno": {Code: "bruno", Known: true, Skills: OperatorSkills{Rooms: []string{"aula1"}}},
+			"carla": {Code: "carla", Known: true, Availability: []OperatorAvailability{
+				{Date: at(0, 0), StartTime: at(0, 0), EndTime: at(0, 0).AddDate(0, 0, 1), AllDay: true, IsAbsence: true},
+			}},
+		},
+		Activities: map[string]Activity{},
+	}
+
+	rows := []Row{
+		suggesterRow(1, "aula1", "lab", "anna", at(9, 0), at(10, 0)),
+		suggesterRow(2, "aula2", "lab", "", at(9, 0), at(10, 0)),
+		suggesterRow(3, "aula1", "lab", "", at(9, 0), at(10, 0)),
+		suggesterRow(4, "aula1", "lab", "", at(11, 0), at(12, 0)),
+		suggesterRow(5, "aula3", "lab", "", at(11, 0), at(12, 0)),
+	}
+
+	out, suggestions, err := SuggestOperators(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+
+	// fixed assignment is kept
+	assert.Equal(t, "anna", out[0].OperatorCode)
+	assert.False(t, out[0].OperatorSuggested)
+
+	// bruno can not work in aula2, carla is absent: nobody is available
+	assert.Equal(t, "", out[1].OperatorCode)
+
+	// anna is already running the same activity in the same room
+	assert.Equal(t, "anna", out[2].OperatorCode)
+	assert.True(t, out[2].OperatorSuggested)
+
+	// bruno has the lowest workload
+	assert.Equal(t, "bruno", out[3].OperatorCode)
+
+	// rooms not requiring an operator are skipped
+	assert.Equal(t, "", out[4].OperatorCode)
+
+	assert.Len(t, suggestions, 2)
+}
+
+func TestSuggestOperatorsFollowsRoomGrouping(t *testing.T) {
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			"museo": {Code: "museo"},
+			"aula1": {Code: "aula1", GroupActivities: true, GroupingPolicy: database.GroupingSameTimeAndActivity},
+			"planetario": {Code: "planetario", GroupActivities: true, GroupingPolicy: database.GroupingOverlap,
+				GroupingTolerance: 10 * time.Minute},
+		},
+		Operators: map[string]Operator{
+			"anna":  {Code: "anna", Known: true},
+			"bruno": {Code: "bruno", Known: true},
+		},
+		Activities: map[string]Activity{},
+	}
+
+	rows := []Row{
+		// two bookings at the same time in a room that does not group them
+		suggesterRow(1, "museo", "visita", "anna", at(9, 0), at(10, 0)),
+		suggesterRow(2, "museo", "visita", "", at(9, 0), at(10, 0)),
+		// grouped only with the same activity
+		suggesterRow(3, "aula1", "stelle", "anna", at(11, 0), at(12, 0)),
+		suggesterRow(4, "aula1", "pianeti", "", at(11, 0), at(12, 0)),
+		suggesterRow(5, "aula1", "stelle", "", at(11, 0), at(12, 0)),
+		// grouped within the tolerance
+		suggesterRow(6, "planetario", "stelle", "bruno", at(14, 0), at(15, 0)),
+		suggesterRow(7, "planetario", "stelle", "", at(14, 0), at(15, 5)),
+	}
+
+	out, _, err := SuggestOperators(ctx, rows, anagraphics)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "bruno", out[1].OperatorCode)
+	assert.Equal(t, "bruno", out[3].OperatorCode)
+	assert.Equal(t, "anna", out[4].OperatorCode)
+	assert.Equal(t, "bruno", out[6].OperatorCode)
+
+	// with a single operator the second booking in the museum gets none
+	delete(anagraphics.Operators, "bruno")
+	out, _, err = SuggestOperators(ctx, []Row{
+		suggesterRow(1, "museo", "visita", "anna", at(9, 0), at(10, 0)),
+		suggesterRow(2, "museo", "visita", "", at(9, 0), at(10, 0)),
+	}, anagraphics)
+	assert.NoError(t, err)
+	assert.Equal(t, "", out[1].OperatorCode)
+}
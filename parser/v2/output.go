@@ -10,15 +10,22 @@ type OutputAnagraphics struct {
 	SchoolClasses  map[string]SchoolClass
 	Activities     map[string]Activity
 	ActivityTypes  map[string]ActivityType
+	HighlightKinds map[HighlightReason]HighlightKind
 }
 
 type Output struct {
-	Anagraphics *OutputAnagraphics
-	Rows        []OutputRow
+	Anagraphics         *OutputAnagraphics
+	Rows                []OutputRow
+	OperatorSuggestions []OperatorSuggestion
+	Normalizations      []ActivityNameNormalization
+	LunchPlans          []LunchPlan
+	// Changes compared to the previous version of the bookings, when given
+	Changes []BookingChange
 }
 
 type OutputRow struct {
-	ID int
+	ID        int
+	RowNumber uint
 
 	BookingCode  string
 	Date         time.Time
@@ -34,12 +41,17 @@ type OutputRow struct {
 	OperatorCode      string
 	VisitingGroupCode string
 	ActivityCode      string
+	OperatorSuggested bool
+	LunchTurnProposed bool
 
 	Confirmed                   *bool
 	IsPlaceholderNumeroAttivita bool
 
 	Warnings []Warning
 
+	// Change is set when the row differs from the previous version of the bookings
+	Change *BookingChange `json:",omitempty"`
+
 	anagraphicsRef *OutputAnagraphics
 }
 
@@ -48,22 +60,22 @@ func ToOutputRows(rows []Row, anagraphicsRef *OutputAnagraphics) []OutputRow {
 
 	for _, input := range rows {
 		out = append(out, OutputRow{
-			ID:                input.ID,
-			BookingCode:       input.BookingCode,
-			Date:              input.Date,
-			StartTime:         input.StartTime,
-			EndTime:           input.EndTime,
-			Duration:          input.Duration,
-			BookingNote:       input.BookingNote,
-			OperatorNote:      input.OperatorNote,
-			RoomCode:          input.RoomCode,
-			OperatorCode:      input.OperatorCode,
-			VisitingGroupCode: input.VisitingGroupCode,
-			ActivityCode:      input.ActivityCode,
-			Payment: PaymentStatus{
-				PaymentAdvance:       input.PaymentAdvance,
-				PaymentAdvanceStatus: input.PaymentAdvanceStatus,
-			},
+			ID:                          input.ID,
+			RowNumber:                   input.RowNumber,
+			BookingCode:                 input.BookingCode,
+			Date:                        input.Date,
+			StartTime:                   input.StartTime,
+			EndTime:                     input.EndTime,
+			Duration:                    input.Duration,
+			BookingNote:                 input.BookingNote,
+			OperatorNote:                input.OperatorNote,
+			RoomCode:                    input.RoomCode,
+			OperatorCode:                input.OperatorCode,
+			VisitingGroupCode:           input.VisitingGroupCode,
+			ActivityCode:                input.ActivityCode,
+			OperatorSuggested:           input.OperatorSuggested,
+			LunchTurnProposed:           input.LunchTurnProposed,
+			Payment:                     NewPaymentStatus(input.PaymentAdvance, input.PaymentAdvanceStatus),
 			Warnings:                    input.Warnings,
 			Bus:                         input.Bus,
 			Confirmed:                   input.Confirmed,
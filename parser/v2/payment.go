@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in euro cents.
+type Money int64
+
+func MoneyFromEuros(euros float64) Money {
+	if euros < 0 {
+		return -MoneyFromEuros(-euros)
+	}
+	return Money(euros*100 + 0.5)
+}
+
+func (m Money) Euros() float64 {
+	return float64(m) / 100
+}
+
+type PaymentState string
+
+const (
+	PaymentStatePaid    PaymentState = "paid"
+	PaymentStatePending PaymentState = "pending"
+	PaymentStateNotDue  PaymentState = "not-due"
+)
+
+var (
+	moneyRegex          = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+	paymentNoneRegex    = regexp.MustCompile(`(?i)^\s*(-+|no|nessuno|n\.?\s*d\.?)\s*$`)
+	paymentNotDueRegex  = regexp.MustCompile(`(?i)\b(non\s+dovut[oi]|non\s+previst[oi]|non\s+richiest[oi]|esent[ei]|nessun\s+acconto|gratuit[oi])\b`)
+	paymentPendingRegex = regexp.MustCompile(`(?i)\b(non\s+pagat[oi]|non\s+versat[oi]|non\s+ricevut[oi]|da\s+pagare|da\s+versare|da\s+saldare|in\s+attesa|attesa|sollecit\w*|pending)\b`)
+	paymentPaidRegex    = regexp.MustCompile(`(?i)\b(pagat[oi]|versat[oi]|ricevut[oi]|saldat[oi]|incassat[oi]|ok|si|paid)\b`)
+)
+
+// ParseMoney reads an amount like "€ 150", "1.234,50" or "100 + 50" (amounts joined by '+' are summed).
+// The second value is false when no amount is found.
+func ParseMoney(raw string) (Money, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || paymentNoneRegex.MatchString(raw) {
+		return 0, false
+	}
+
+	matches := moneyRegex.FindAllString(raw, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	if !strings.Contains(raw, "+") {
+		matches = matches[:1]
+	}
+
+	total := Money(0)
+	for _, match := range matches {
+		amount, ok := parseSingleAmount(match)
+		if !ok {
+			return 0, false
+		}
+		total += amount
+	}
+	return total, true
+}
+
+func parseSingleAmount(raw string) (Money, bool) {
+	// the last separator followed by one or two digits is the decimal one, the others group the thousands
+	units, cents := raw, ""
+	if i := strings.LastIndexAny(raw, ".,"); i >= 0 && len(raw)-i-1 <= 2 {
+		units, cents = raw[:i], raw[i+1:]
+	}
+	units = strings.NewReplacer(".", "", ",", "").Replace(units)
+	if len(cents) == 1 {
+		cents += "0"
+	}
+
+	value, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	out := Money(value * 100)
+	if cents != "" {
+		c, err := strconv.ParseInt(cents, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		out += Money(c)
+	}
+	return out, true
+}
+
+// ParsePaymentState reads the status of the advance, returning an empty state when it is not recognized.
+func ParsePaymentState(raw string) PaymentState {
+	switch {
+	case strings.TrimSpace(raw) == "" || paymentNoneRegex.MatchString(raw):
+		return ""
+	case paymentNotDueRegex.MatchString(raw):
+		return PaymentStateNotDue
+	case paymentPendingRegex.MatchString(raw):
+		return PaymentStatePending
+	case paymentPaidRegex.MatchString(raw):
+		return PaymentStatePaid
+	default:
+		return ""
+	}
+}
+
+func NewPaymentStatus(advanceRaw, advanceStatusRaw string) PaymentStatus {
+	out := PaymentStatus{
+		PaymentAdvance:       advanceRaw,
+		PaymentAdvanceStatus: advanceStatusRaw,
+		AdvanceState:         ParsePaymentState(advanceStatusRaw),
+	}
+	out.AdvanceAmount, _ = ParseMoney(advanceRaw)
+	if out.AdvanceState == "" && paymentNoneRegex.MatchString(advanceRaw) {
+		out.AdvanceState = PaymentStateNotDue
+	}
+	return out
+}
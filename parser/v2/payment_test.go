@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected Money
+		found    bool
+	}
+
+	testCases := []testCase{
+		{"", 0, false},
+		{"-", 0, false},
+		{"nessuno", 0, false},
+		{"150", 15000, true},
+		{"€ 150", 15000, true},
+		{"150 euro", 15000, true},
+		{"150,5", 15050, true},
+		{"150.50", 15050, true},
+		{"1.234,50", 123450, true},
+		{"1.234", 123400, true},
+		{"1,234.50", 123450, true},
+		{"100 + 50,25", 15025, true},
+		{"acconto 80 versato il 12/02", 8000, true},
+		{"da definire", 0, false},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			actual, found := ParseMoney(tc.input)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestParsePaymentState(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected PaymentState
+	}
+
+	testCases := []testCase{
+		{"", ""},
+		{"-", ""},
+		{"Pagato", PaymentStatePaid},
+		{"saldato il 10/02", PaymentStatePaid},
+		{"ricevuto", PaymentStatePaid},
+		{"Non pagato", PaymentStatePending},
+		{"in attesa", PaymentStatePending},
+		{"da versare", PaymentStatePending},
+		{"non dovuto", PaymentStateNotDue},
+		{"esente", PaymentStateNotDue},
+		{"boh", ""},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParsePaymentState(tc.input))
+		})
+	}
+}
+
+func TestMoneyFromEuros(t *testing.T) {
+	assert.Equal(t, Money(850), MoneyFromEuros(8.5))
+	assert.Equal(t, Money(1999), MoneyFromEuros(19.99))
+	assert.Equal(t, Money(-1200), MoneyFromEuros(-12))
+	assert.Equal(t, 12.34, Money(1234).Euros())
+}
+
+func TestNewPaymentStatus(t *testing.T) {
+	status := NewPaymentStatus("€ 150", "pagato")
+	assert.Equal(t, Money(15000), status.AdvanceAmount)
+	assert.Equal(t, PaymentStatePaid, status.AdvanceState)
+	assert.Equal(t, "€ 150", status.PaymentAdvance)
+
+	status = NewPaymentStatus("-", "")
+	assert.Equal(t, Money(0), status.AdvanceAmount)
+	assert.Equal(t, PaymentStateNotDue, status.AdvanceState)
+
+	status = NewPaymentStatus("100", "")
+	assert.Equal(t, Money(10000), status.AdvanceAmount)
+	assert.Equal(t, PaymentState(""), status.AdvanceState)
+}
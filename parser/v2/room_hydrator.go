@@ -7,7 +7,7 @@ import (
 	"github.com/fabiofenoglio/excelconv/database"
 )
 
-func HydrateRooms(_ config.WorkflowContext, rows []InputRow) ([]Row, []Room, error) {
+func HydrateRooms(ctx config.WorkflowContext, rows []InputRow) ([]Row, []Room, error) {
 	outRows := make([]Row, 0, len(rows))
 	outRooms := make([]Room, 0, 10)
 
@@ -47,9 +47,37 @@ func HydrateRooms(_ config.WorkflowContext, rows []InputRow) ([]Row, []Room, err
 		}
 	}
 
+	for i, room := range outRooms {
+		outRooms[i] = withGroupingOverride(ctx, room)
+	}
+
 	return outRows, outRooms, nil
 }
 
+// withGroupingOverride applies the grouping configured for the room, matched by its name, code or alias.
+func withGroupingOverride(ctx config.WorkflowContext, room Room) Room {
+	for key, grouping := range ctx.Config.RoomGrouping {
+		code := nameToCode(key)
+		if knownRoom, isKnown := database.GetKnownRoom(code); isKnown {
+			code = knownRoom.Code
+		}
+		if code != room.Code && nameToCode(room.Name) != code {
+			continue
+		}
+
+		policy, tolerance, err := grouping.Resolve()
+		if err != nil {
+			// already validated with the configuration
+			continue
+		}
+		room.GroupActivities = policy != database.GroupingNone
+		room.GroupingPolicy = policy
+		room.GroupingTolerance = tolerance
+		ctx.Logger.Debugf("room %s groups the activities with policy %s", room.Code, policy)
+	}
+	return room
+}
+
 func buildNewRoom(code string, name string) Room {
 
 	knownRoom, isKnown := database.GetKnownRoom(code)
@@ -77,5 +105,7 @@ func buildNewRoom(code string, name string) Room {
 		ShowActivityNamesInside:        knownRoom.ShowActivityNamesInside,
 		AlwaysShow:                     knownRoom.AlwaysShow,
 		DoesNotRequireOperator:         knownRoom.DoesNotRequireOperator,
+		GroupingPolicy:                 knownRoom.GroupingPolicy,
+		GroupingTolerance:              knownRoom.GroupingTolerance,
 	}
 }
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/rules"
+	"github.com/pkg/errors"
+)
+
+var defaultComboRules = []config.ComboRule{
+	{
+		// ex. "1h + Sistema solare": one hour in the museum, then the planetarium show
+		Code:            "museo-planetario",
+		Pattern:         `(?m)^\s*[\s\*]*\s*([\s0-9hmHM\,\.]{2,})\s*\+\s*(.*)`,
+		FirstRoom:       "museo",
+		SecondRoom:      "planetario",
+		FirstRoomKeeps:  config.ComboKeepsFull,
+		SecondRoomKeeps: config.ComboKeepsSecond,
+	},
+}
+
+// activityCoupleSplitRule handles the activities booked as a combo ("X + Y") on two rows of the same group,
+// one in each room of the combo: each row keeps the part of the name pertaining to its room.
+type activityCoupleSplitRule struct{}
+
+func (r activityCoupleSplitRule) Code() string           { return "activity-couple-split" }
+func (r activityCoupleSplitRule) Stage() rules.Stage     { return rules.StageB0 }
+func (r activityCoupleSplitRule) EnabledByDefault() bool { return true }
+func (r activityCoupleSplitRule) Description() string {
+	return "divide le attività prenotate come combinazione di due aule (es. museo + planetario)"
+}
+
+type comboRule struct {
+	config.ComboRule
+	pattern    *regexp.Regexp
+	firstRoom  string
+	secondRoom string
+}
+
+func (r activityCoupleSplitRule) Apply(ctx config.WorkflowContext, data RuleB0Data) (RuleB0Data, error) {
+	comboRules, err := effectiveComboRules(ctx)
+	if err != nil {
+		return data, err
+	}
+
+	rows := data.Rows
+	updatedIndex := make(map[int]bool)
+
+	for _, combo := range comboRules {
+		// candidates are grouped by day, group and booked activity name
+		candidates := make(map[string][]int)
+		keys := make([]string, 0)
+
+		for i, row := range rows {
+			if updatedIndex[i] || row.activityRawString == "" {
+				continue
+			}
+			if row.RoomCode != combo.firstRoom && row.RoomCode != combo.secondRoom {
+				continue
+			}
+			if !combo.pattern.MatchString(row.activityRawString) {
+				continue
+			}
+			key := fmt.Sprintf("%s|%s|%s", row.Date.Format("2006-01-02"), row.BookingCode, row.activityRawString)
+			if _, ok := candidates[key]; !ok {
+				keys = append(keys, key)
+			}
+			candidates[key] = append(candidates[key], i)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			inFirstRoom, inSecondRoom := make([]int, 0, 1), make([]int, 0, 1)
+			for _, i := range candidates[key] {
+				if rows[i].RoomCode == combo.firstRoom {
+					inFirstRoom = append(inFirstRoom, i)
+				} else {
+					inSecondRoom = append(inSecondRoom, i)
+				}
+			}
+
+			if len(inFirstRoom) == 0 || len(inSecondRoom) == 0 {
+				continue
+			}
+			if len(inFirstRoom) > 1 || len(inSecondRoom) > 1 {
+				ctx.Logger.WithField("rule", r.Code()).Warnf(
+					"l'attività [%s] del gruppo %s non è stata divisa: trovate %d righe in %s e %d in %s",
+					rows[inFirstRoom[0]].activityRawString, rows[inFirstRoom[0]].BookingCode,
+					len(inFirstRoom), combo.firstRoom, len(inSecondRoom), combo.secondRoom)
+				continue
+			}
+
+			// found two rows that match the criteria:
+			// - same group, same day
+			// - same booked activity name, in the form of the combo pattern
+			// - one in each room of the combo
+			first, second := inFirstRoom[0], inSecondRoom[0]
+			matches := combo.pattern.FindStringSubmatch(rows[first].activityRawString)
+			if len(matches) < 3 {
+				continue
+			}
+
+			for _, target := range []struct {
+				index int
+				keeps string
+			}{{first, combo.FirstRoomKeeps}, {second, combo.SecondRoomKeeps}} {
+				row := rows[target.index]
+				rewritten := row.activityRawString
+				switch target.keeps {
+				case config.ComboKeepsFirst:
+					rewritten = strings.TrimSpace(matches[1])
+				case config.ComboKeepsSecond:
+					rewritten = strings.TrimSpace(matches[2])
+				}
+
+				if rewritten != row.activityRawString {
+					rules.LogChange(ctx, r, "rewrote activity %v from [%s] in room [%s] to [%s]",
+						row.ID, row.activityRawString, row.RoomCode, rewritten)
+					row.activityRawString = rewritten
+					rows[target.index] = row
+				}
+				updatedIndex[target.index] = true
+			}
+		}
+	}
+
+	data.Rows = rows
+	return data, nil
+}
+
+// effectiveComboRules merges the default combo rules with the ones declared in the configuration.
+func effectiveComboRules(ctx config.WorkflowContext) ([]comboRule, error) {
+	merged := make([]config.ComboRule, 0, len(defaultComboRules)+len(ctx.Config.ComboRules))
+	merged = append(merged, defaultComboRules...)
+
+	for _, declared := range ctx.Config.ComboRules {
+		replaced := false
+		for i, existing := range merged {
+			if existing.Code == declared.Code {
+				merged[i] = declared
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, declared)
+		}
+	}
+
+	out := make([]comboRule, 0, len(merged))
+	for _, declared := range merged {
+		if declared.Disabled {
+			continue
+		}
+		compiled, err := compileComboRule(declared)
+		if err != nil {
+			return nil, errors.Wrapf(err, "combinazione '%s' non valida", declared.Code)
+		}
+		out = append(out, compiled)
+	}
+
+	return out, nil
+}
+
+func compileComboRule(declared config.ComboRule) (comboRule, error) {
+	out := comboRule{ComboRule: declared}
+
+	if declared.Code == "" {
+		return out, errors.New("manca il codice")
+	}
+	if declared.FirstRoom == "" || declared.SecondRoom == "" {
+		return out, errors.New("specificare entrambe le aule")
+	}
+	out.firstRoom = resolveRoomCode(declared.FirstRoom)
+	out.secondRoom = resolveRoomCode(declared.SecondRoom)
+	if out.firstRoom == out.secondRoom {
+		return out, errors.New("le due aule devono essere diverse")
+	}
+
+	for _, keeps := range []string{declared.FirstRoomKeeps, declared.SecondRoomKeeps} {
+		switch keeps {
+		case config.ComboKeepsFull, config.ComboKeepsFirst, config.ComboKeepsSecond:
+		default:
+			return out, errors.Errorf("valore '%s' non valido, deve essere '%s', '%s' o '%s'",
+				keeps, config.ComboKeepsFull, config.ComboKeepsFirst, config.ComboKeepsSecond)
+		}
+	}
+
+	var err error
+	if out.pattern, err = regexp.Compile(declared.Pattern); err != nil {
+		return out, errors.Wrap(err, "espressione regolare non valida")
+	}
+	if out.pattern.NumSubexp() < 2 {
+		return out, errors.New("l'espressione regolare deve avere due gruppi")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/adrg/strutil"
+	"github.com/adrg/strutil/metrics"
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/fabiofenoglio/excelconv/rules"
+	"github.com/pkg/errors"
+)
+
+type ActivityNameNormalization struct {
+	Original   string
+	Normalized string
+	Confidence float64
+	RowIDs     []int
+	RowNumbers []uint
+}
+
+type activityNameCandidate struct {
+	key       string
+	cleaned   string
+	rawName   string
+	rows      []int
+	firstRow  uint
+	protected bool
+}
+
+// activityNameNormalizationRule merges the activity names that look like typos of a more frequent one.
+// Protected names and names found in the activity catalog are never rewritten.
+type activityNameNormalizationRule struct{}
+
+func (r activityNameNormalizationRule) Code() string           { return "activity-name-normalization" }
+func (r activityNameNormalizationRule) Stage() rules.Stage     { return rules.StageB0 }
+func (r activityNameNormalizationRule) EnabledByDefault() bool { return false }
+func (r activityNameNormalizationRule) Description() string {
+	return "uniforma i nomi delle attività molto simili tra loro"
+}
+
+func (r activityNameNormalizationRule) Apply(ctx config.WorkflowContext, data RuleB0Data) (RuleB0Data, error) {
+	rows := data.Rows
+	settings := ctx.Config.ActivityNameNormalization
+	if settings.Threshold <= 0 || settings.Threshold > 1 {
+		return data, errors.Errorf("soglia di normalizzazione non valida: %v (deve essere compresa tra 0 e 1)", settings.Threshold)
+	}
+
+	stringSimilarityMetrics := metrics.NewJaroWinkler()
+
+	clean := func(raw string) string {
+		return strings.ToUpper(strings.TrimSpace(raw))
+	}
+
+	protected := make(map[string]bool)
+	for _, name := range settings.ProtectedNames {
+		protected[nameToCode(name)] = true
+	}
+
+	candidatesIndex := make(map[string]*activityNameCandidate)
+	candidates := make([]*activityNameCandidate, 0)
+
+	for i, row := range rows {
+		key := nameToCode(row.activityRawString)
+		if key == "" {
+			continue
+		}
+		candidate, ok := candidatesIndex[key]
+		if !ok {
+			_, inCatalog := database.GetKnownActivity(row.activityRawString)
+			candidate = &activityNameCandidate{
+				key:       key,
+				cleaned:   clean(row.activityRawString),
+				rawName:   row.activityRawString,
+				firstRow:  row.RowNumber,
+				protected: protected[key] || inCatalog,
+			}
+			candidatesIndex[key] = candidate
+			candidates = append(candidates, candidate)
+		} else if row.RowNumber < candidate.firstRow {
+			candidate.rawName = row.activityRawString
+			candidate.firstRow = row.RowNumber
+		}
+		candidate.rows = append(candidate.rows, i)
+	}
+
+	// protected names first, then the most used ones: typos are rarer than the correct spelling
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.protected != cj.protected {
+			return ci.protected
+		}
+		if len(ci.rows) != len(cj.rows) {
+			return len(ci.rows) > len(cj.rows)
+		}
+		if ci.firstRow != cj.firstRow {
+			return ci.firstRow < cj.firstRow
+		}
+		return ci.key < cj.key
+	})
+
+	canonicals := make([]*activityNameCandidate, 0, len(candidates))
+	report := make([]ActivityNameNormalization, 0)
+
+	for _, candidate := range candidates {
+		if candidate.protected {
+			canonicals = append(canonicals, candidate)
+			continue
+		}
+
+		var best *activityNameCandidate
+		bestSimilarity := 0.0
+		for _, canonical := range canonicals {
+			similarity := strutil.Similarity(candidate.cleaned, canonical.cleaned, stringSimilarityMetrics)
+			if similarity >= settings.Threshold && similarity > bestSimilarity {
+				best = canonical
+				bestSimilarity = similarity
+			}
+		}
+
+		if best == nil {
+			canonicals = append(canonicals, candidate)
+			continue
+		}
+
+		entry := ActivityNameNormalization{
+			Original:   cleanStringForVisualization(candidate.rawName),
+			Normalized: cleanStringForVisualization(best.rawName),
+			Confidence: bestSimilarity,
+		}
+
+		for _, i := range candidate.rows {
+			row := rows[i]
+			rules.LogChange(ctx, r, "normalized activity %v name from [%s] to [%s] (confidence: %v)",
+				row.ID, row.activityRawString, best.rawName, bestSimilarity)
+
+			entry.RowIDs = append(entry.RowIDs, row.ID)
+			entry.RowNumbers = append(entry.RowNumbers, row.RowNumber)
+
+			row.activityRawString = best.rawName
+			rows[i] = row
+		}
+
+		sort.Slice(entry.RowNumbers, func(i, j int) bool { return entry.RowNumbers[i] < entry.RowNumbers[j] })
+		sort.Ints(entry.RowIDs)
+
+		report = append(report, entry)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].RowNumbers[0] < report[j].RowNumbers[0]
+	})
+
+	data.Rows = rows
+	data.Normalizations = report
+	return data, nil
+}
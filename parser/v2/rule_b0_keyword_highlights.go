@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/rules"
+)
+
+// keywordHighlightsRule highlights the groups having at least one row matching a highlight keyword rule,
+// as the special projects (colonna AH) or the "special" word in the notes (colonne AI e AJ).
+type keywordHighlightsRule struct{}
+
+func (r keywordHighlightsRule) Code() string           { return "keyword-highlights" }
+func (r keywordHighlightsRule) Stage() rules.Stage     { return rules.StageB0 }
+func (r keywordHighlightsRule) EnabledByDefault() bool { return true }
+func (r keywordHighlightsRule) Description() string {
+	return "evidenzia i gruppi secondo le regole per parole chiave"
+}
+
+func (r keywordHighlightsRule) Apply(ctx config.WorkflowContext, data RuleB0Data) (RuleB0Data, error) {
+	groupsIndex := make(map[string]int)
+	for i, group := range data.Groups {
+		groupsIndex[group.Code] = i
+	}
+
+	// rule by rule, so that the highlights are in the order of the rules whatever the order of the rows
+	for _, keywordRule := range data.KeywordRules {
+		if keywordRule.Kind != config.KeywordRuleKindHighlight {
+			continue
+		}
+		for _, row := range data.Rows {
+			groupIndex, ok := groupsIndex[row.VisitingGroupCode]
+			if !ok {
+				continue
+			}
+			group := data.Groups[groupIndex]
+			if hasHighlight(group, HighlightReason(keywordRule.Code)) {
+				continue
+			}
+			if value, matches := keywordRule.match(row); matches {
+				group.Highlights = append(group.Highlights, HighlightReason(keywordRule.Code))
+				rules.LogChange(ctx, r, "highlighted group %s as %s because of [%s]", group.Code, keywordRule.Code, value)
+				data.Groups[groupIndex] = group
+			}
+		}
+	}
+
+	return data, nil
+}
+
+func hasHighlight(group VisitingGroup, highlight HighlightReason) bool {
+	for _, h := range group.Highlights {
+		if h == highlight {
+			return true
+		}
+	}
+	return false
+}
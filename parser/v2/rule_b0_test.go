@@ -0,0 +1,259 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func testRuleContext(cfg config.WorkflowContextConfig) config.WorkflowContext {
+	return config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+		Config:  cfg,
+	}
+}
+
+func TestApplyRuleB0LevelEnablement(t *testing.T) {
+	rows := func() []Row {
+		return []Row{
+			{
+				InputRow:          InputRow{ID: 1, SpecialProjectName: "progetto", BookingNote: "gruppo Special"},
+				VisitingGroupCode: "a",
+			},
+			{
+				InputRow:          InputRow{ID: 2, OperatorNote: "special"},
+				VisitingGroupCode: "a",
+			},
+		}
+	}
+	groups := func() []VisitingGroup {
+		return []VisitingGroup{{Code: "a"}}
+	}
+
+	keywordRules, err := effectiveKeywordRules(testRuleContext(config.WorkflowContextConfig{}))
+	assert.NoError(t, err)
+
+	_, out, normalizations, err := ApplyRuleB0Level(testRuleContext(config.WorkflowContextConfig{}), rows(), groups(), keywordRules)
+	assert.NoError(t, err)
+	assert.Equal(t, []HighlightReason{HighlightSpecialProject, HighlightSpecialNotes}, out[0].Highlights)
+	// normalization is opt-in
+	assert.Nil(t, normalizations)
+
+	_, out, _, err = ApplyRuleB0Level(testRuleContext(config.WorkflowContextConfig{
+		Rules: map[string]bool{"keyword-highlights": false},
+	}), rows(), groups(), keywordRules)
+	assert.NoError(t, err)
+	assert.Empty(t, out[0].Highlights)
+}
+
+func TestActivityCoupleSplitRule(t *testing.T) {
+	row := func(id int, bookingCode, room, activity string) Row {
+		return Row{
+			InputRow: InputRow{ID: id, BookingCode: bookingCode, Date: testDay, activityRawString: activity},
+			RoomCode: room,
+		}
+	}
+
+	rows := []Row{
+		row(1, "B1", "planetario", "1h + Sistema solare"),
+		row(2, "B1", "museo", "1h + Sistema solare"),
+		row(3, "B2", "planetario", "1h + Sistema solare"),
+		row(4, "B3", "planetario", "Costellazioni"),
+		row(5, "B3", "museo", "Costellazioni"),
+	}
+
+	out, err := activityCoupleSplitRule{}.Apply(testRuleContext(config.WorkflowContextConfig{}), RuleB0Data{Rows: rows})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Sistema solare", out.Rows[0].activityRawString)
+	assert.Equal(t, "1h + Sistema solare", out.Rows[1].activityRawString)
+	// no matching row in the other room
+	assert.Equal(t, "1h + Sistema solare", out.Rows[2].activityRawString)
+	// not in the "aaaa + bbbb" form
+	assert.Equal(t, "Costellazioni", out.Rows[3].activityRawString)
+	assert.Equal(t, "Costellazioni", out.Rows[4].activityRawString)
+}
+
+func TestActivityCoupleSplitRuleAmbiguousMatches(t *testing.T) {
+	row := func(id int, room string) Row {
+		return Row{
+			InputRow: InputRow{ID: id, BookingCode: "B1", Date: testDay, activityRawString: "1h + Costellazioni"},
+			RoomCode: room,
+		}
+	}
+
+	type testCase struct {
+		rows []Row
+	}
+
+	testCases := []testCase{
+		{[]Row{row(1, "planetario"), row(2, "museo"), row(3, "museo")}},
+		{[]Row{row(1, "museo"), row(2, "planetario"), row(3, "museo")}},
+		{[]Row{row(1, "museo"), row(2, "museo"), row(3, "planetario")}},
+		{[]Row{row(1, "museo"), row(2, "planetario"), row(3, "planetario"), row(4, "museo")}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			out, err := activityCoupleSplitRule{}.Apply(testRuleContext(config.WorkflowContextConfig{}), RuleB0Data{Rows: tc.rows})
+			assert.NoError(t, err)
+
+			// none of the candidates is rewritten, whatever the order of the rows
+			for _, r := range out.Rows {
+				assert.Equal(t, "1h + Costellazioni", r.activityRawString)
+			}
+		})
+	}
+}
+
+func TestActivityCoupleSplitRuleFromConfiguration(t *testing.T) {
+	row := func(id int, room, activity string) Row {
+		return Row{
+			InputRow: InputRow{ID: id, BookingCode: "B1", Date: testDay, activityRawString: activity},
+			RoomCode: room,
+		}
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		ComboRules: []config.ComboRule{
+			{Code: "museo-planetario", Disabled: true},
+			{
+				Code:            "lab-planetario",
+				Pattern:         `^\s*(.+?)\s*\+\s*(.+?)\s*$`,
+				FirstRoom:       "laboratorio",
+				SecondRoom:      "planetario",
+				FirstRoomKeeps:  config.ComboKeepsFirst,
+				SecondRoomKeeps: config.ComboKeepsSecond,
+			},
+		},
+	})
+
+	rows := []Row{
+		row(1, "planetario", "Razzi + Sistema solare"),
+		row(2, "laboratorio", "Razzi + Sistema solare"),
+		row(3, "museo", "1h + Costellazioni"),
+		row(4, "planetario", "1h + Costellazioni"),
+	}
+
+	out, err := activityCoupleSplitRule{}.Apply(ctx, RuleB0Data{Rows: rows})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Sistema solare", out.Rows[0].activityRawString)
+	assert.Equal(t, "Razzi", out.Rows[1].activityRawString)
+	// the default combo has been disabled
+	assert.Equal(t, "1h + Costellazioni", out.Rows[2].activityRawString)
+	assert.Equal(t, "1h + Costellazioni", out.Rows[3].activityRawString)
+}
+
+func TestComboRulesValidation(t *testing.T) {
+	valid := config.ComboRule{
+		Code:            "a",
+		Pattern:         `(.*)\+(.*)`,
+		FirstRoom:       "museo",
+		SecondRoom:      "planetario",
+		FirstRoomKeeps:  config.ComboKeepsFull,
+		SecondRoomKeeps: config.ComboKeepsSecond,
+	}
+
+	invalid := []func(r *config.ComboRule){
+		func(r *config.ComboRule) { r.Code = "" },
+		func(r *config.ComboRule) { r.SecondRoom = "" },
+		func(r *config.ComboRule) { r.SecondRoom = "Museo" },
+		func(r *config.ComboRule) { r.FirstRoomKeeps = "all" },
+		func(r *config.ComboRule) { r.Pattern = "(" },
+		func(r *config.ComboRule) { r.Pattern = "(.*)" },
+	}
+
+	_, err := effectiveComboRules(testRuleContext(config.WorkflowContextConfig{
+		ComboRules: []config.ComboRule{valid},
+	}))
+	assert.NoError(t, err)
+
+	for i, change := range invalid {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			declared := valid
+			change(&declared)
+			_, err := effectiveComboRules(testRuleContext(config.WorkflowContextConfig{
+				ComboRules: []config.ComboRule{declared},
+			}))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestActivityNameNormalizationRule(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		ActivityNameNormalization: config.ActivityNameNormalizationConfig{
+			Threshold:      0.9,
+			ProtectedNames: []string{"Laboratorio Luna", "Laboratorio Luce"},
+		},
+	})
+
+	row := func(id int, activity string) Row {
+		return Row{
+			InputRow: InputRow{ID: id, RowNumber: uint(id + 4), activityRawString: activity},
+		}
+	}
+
+	rows := []Row{
+		row(1, "Sistema Solre"),
+		row(2, "Sistema Solare"),
+		row(3, "sistema solare "),
+		row(4, "Laboratorio Luce"),
+		row(5, "Laboratorio Luna"),
+		row(6, "Laboratorio Luce"),
+		row(7, "Osservazione"),
+	}
+
+	out, err := activityNameNormalizationRule{}.Apply(ctx, RuleB0Data{Rows: rows})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Sistema Solare", out.Rows[0].activityRawString)
+	assert.Equal(t, "Sistema Solare", out.Rows[1].activityRawString)
+	// protected names are never rewritten
+	assert.Equal(t, "Laboratorio Luce", out.Rows[3].activityRawString)
+	assert.Equal(t, "Laboratorio Luna", out.Rows[4].activityRawString)
+	assert.Equal(t, "Osservazione", out.Rows[6].activityRawString)
+
+	report := out.Normalizations
+	if assert.Len(t, report, 1) {
+		assert.Equal(t, "Sistema Solre", report[0].Original)
+		assert.Equal(t, "Sistema Solare", report[0].Normalized)
+		assert.Equal(t, []int{1}, report[0].RowIDs)
+		assert.Equal(t, []uint{5}, report[0].RowNumbers)
+		assert.GreaterOrEqual(t, report[0].Confidence, 0.9)
+	}
+}
+
+func TestKeywordHighlightsDoNotDependOnRowOrder(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		KeywordRules: []config.KeywordRule{
+			{Code: "disabile", Kind: config.KeywordRuleKindHighlight, Fields: []string{"nota prenotazione"},
+				Keywords: []string{"disabile"}, Color: "#ff9900"},
+			{Code: "vip", Kind: config.KeywordRuleKindHighlight, Fields: []string{"nota operatore"},
+				Keywords: []string{"vip"}, Color: "#00ff00"},
+		},
+	})
+	keywordRules, err := effectiveKeywordRules(ctx)
+	assert.NoError(t, err)
+
+	disabled := Row{InputRow: InputRow{ID: 1, BookingNote: "alunno disabile"}, VisitingGroupCode: "a"}
+	vip := Row{InputRow: InputRow{ID: 2, OperatorNote: "ospite vip"}, VisitingGroupCode: "a"}
+
+	for _, rows := range [][]Row{{disabled, vip}, {vip, disabled}} {
+		out, err := keywordHighlightsRule{}.Apply(ctx, RuleB0Data{
+			Rows:         rows,
+			Groups:       []VisitingGroup{{Code: "a"}},
+			KeywordRules: keywordRules,
+		})
+		assert.NoError(t, err)
+		// in the order of the rules, that decides the colour of the border
+		assert.Equal(t, []HighlightReason{"disabile", "vip"}, out.Groups[0].Highlights)
+	}
+}
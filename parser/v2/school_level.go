@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type SchoolLevel string
+
+const (
+	SchoolLevelInfanzia     SchoolLevel = "infanzia"
+	SchoolLevelPrimaria     SchoolLevel = "primaria"
+	SchoolLevelSecondariaI  SchoolLevel = "secondaria-i"
+	SchoolLevelSecondariaII SchoolLevel = "secondaria-ii"
+	SchoolLevelUniversity   SchoolLevel = "universita"
+	SchoolLevelOther        SchoolLevel = "altro"
+)
+
+var schoolLevelLabels = map[SchoolLevel]string{
+	SchoolLevelInfanzia:     "Infanzia",
+	SchoolLevelPrimaria:     "Primaria",
+	SchoolLevelSecondariaI:  "Secondaria I grado",
+	SchoolLevelSecondariaII: "Secondaria II grado",
+	SchoolLevelUniversity:   "Università",
+	SchoolLevelOther:        "Altro",
+}
+
+func (l SchoolLevel) Label() string {
+	return schoolLevelLabels[l]
+}
+
+func splitWords(raw string) []string {
+	return strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ParseSchoolLevel maps the free text school type (ex. "Secondaria I grado", "scuola media", "SM")
+// to the school level. Returns an empty level for an empty input and SchoolLevelOther when not recognized.
+func ParseSchoolLevel(raw string) SchoolLevel {
+	if level := SchoolLevel(strings.ToLower(strings.TrimSpace(raw))); level.Label() != "" {
+		return level
+	}
+
+	words := splitWords(raw)
+	if len(words) == 0 {
+		return ""
+	}
+
+	has := func(prefixes ...string) bool {
+		for _, w := range words {
+			for _, p := range prefixes {
+				if strings.HasPrefix(w, p) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	hasWord := func(candidates ...string) bool {
+		for _, w := range words {
+			for _, c := range candidates {
+				if w == c {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("infanzia", "matern") || hasWord("inf"):
+		return SchoolLevelInfanzia
+	case has("primaria", "elementar") || hasWord("el"):
+		return SchoolLevelPrimaria
+	case has("universit", "ateneo", "politecnico"):
+		return SchoolLevelUniversity
+	case has("secondari"):
+		if hasWord("ii", "secondo", "2") || has("superior") {
+			return SchoolLevelSecondariaII
+		}
+		if hasWord("i", "primo", "1") {
+			return SchoolLevelSecondariaI
+		}
+		return SchoolLevelOther
+	case has("medi") || hasWord("sm"):
+		return SchoolLevelSecondariaI
+	case has("superior", "liceo") || hasWord("sup", "iss"):
+		return SchoolLevelSecondariaII
+	}
+	return SchoolLevelOther
+}
+
+var romanGrades = map[string]int{
+	"i": 1, "ii": 2, "iii": 3, "iv": 4, "v": 5,
+}
+
+var ordinalGrades = map[string]int{
+	"prima": 1, "primo": 1,
+	"seconda": 2, "secondo": 2,
+	"terza": 3, "terzo": 3,
+	"quarta": 4, "quarto": 4,
+	"quinta": 5, "quinto": 5,
+}
+
+// ParseClassGrade maps the free text class (ex. "3", "3°", "III", "terza") to the numeric grade.
+// Returns 0 when not recognized.
+func ParseClassGrade(raw string) int {
+	for _, word := range splitWords(raw) {
+		digits := strings.TrimRightFunc(word, unicode.IsLetter)
+		if digits != "" && strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			if grade, err := strconv.Atoi(digits); err == nil && grade > 0 && grade <= 5 {
+				return grade
+			}
+			return 0
+		}
+		if grade, ok := romanGrades[word]; ok {
+			return grade
+		}
+		if grade, ok := ordinalGrades[word]; ok {
+			return grade
+		}
+	}
+	return 0
+}
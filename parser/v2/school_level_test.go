@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+func TestParseSchoolLevel(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected SchoolLevel
+	}
+
+	testCases := []testCase{
+		{"", ""},
+		{"  ", ""},
+		{"Infanzia", SchoolLevelInfanzia},
+		{"Scuola materna", SchoolLevelInfanzia},
+		{"Primaria", SchoolLevelPrimaria},
+		{"scuola elementare", SchoolLevelPrimaria},
+		{"EL", SchoolLevelPrimaria},
+		{"Secondaria I grado", SchoolLevelSecondariaI},
+		{"Secondaria I° grado", SchoolLevelSecondariaI},
+		{"secondaria di primo grado", SchoolLevelSecondariaI},
+		{"Scuola media", SchoolLevelSecondariaI},
+		{"SM", SchoolLevelSecondariaI},
+		{"Secondaria II grado", SchoolLevelSecondariaII},
+		{"Secondaria 2° grado", SchoolLevelSecondariaII},
+		{"Istruzione secondaria superiore", SchoolLevelSecondariaII},
+		{"Liceo", SchoolLevelSecondariaII},
+		{"Università", SchoolLevelUniversity},
+		{"secondaria-ii", SchoolLevelSecondariaII},
+		{"altro", SchoolLevelOther},
+		{"Gruppo adulti", SchoolLevelOther},
+		{"Secondaria", SchoolLevelOther},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseSchoolLevel(tc.input))
+		})
+	}
+}
+
+func TestParseClassGrade(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected int
+	}
+
+	testCases := []testCase{
+		{"", 0},
+		{"3", 3},
+		{"3°", 3},
+		{" 2A ", 2},
+		{"III", 3},
+		{"iv", 4},
+		{"terza", 3},
+		{"Prima", 1},
+		{"classe quinta", 5},
+		{"12", 0},
+		{"pluriclasse", 0},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseClassGrade(tc.input))
+		})
+	}
+}
+
+func TestActivitySchoolLevelMismatchWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalogo.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[
+		{"code": "costellazioni", "name": "Costellazioni", "school_levels": ["Primaria", "secondaria-i"]}
+	]`), 0o600))
+	assert.NoError(t, database.LoadKnownActivities(path))
+
+	anagraphics := &OutputAnagraphics{
+		Activities: map[string]Activity{"a": {Code: "a", CatalogCode: "costellazioni"}},
+		VisitingGroups: map[string]VisitingGroup{
+			"g1": {Code: "g1", SchoolCode: "s1"},
+			"g2": {Code: "g2", SchoolCode: "s2"},
+		},
+		Schools: map[string]School{
+			"s1": {Code: "s1", Level: SchoolLevelPrimaria},
+			"s2": {Code: "s2", Level: SchoolLevelInfanzia},
+		},
+	}
+
+	codes := func(groupCode string) []string {
+		warnings, err := emitWarningsForRow(testRuleContext(config.WorkflowContextConfig{}), Row{
+			ActivityCode:      "a",
+			RoomCode:          "aula",
+			VisitingGroupCode: groupCode,
+		}, anagraphics, nil)
+		assert.NoError(t, err)
+		out := make([]string, 0, len(warnings))
+		for _, w := range warnings {
+			out = append(out, w.Code)
+		}
+		return out
+	}
+
+	assert.NotContains(t, codes("g1"), "activity-school-level-mismatch")
+	assert.Contains(t, codes("g2"), "activity-school-level-mismatch")
+}
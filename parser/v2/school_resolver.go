@@ -0,0 +1,296 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/adrg/strutil"
+	"github.com/adrg/strutil/metrics"
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+	"github.com/pkg/errors"
+)
+
+type rewriteRule struct {
+	find    *regexp.Regexp
+	replace string
+}
+
+var schoolNameRewriteRules = []rewriteRule{
+	{find: regexp.MustCompile(`(?mi)^scuola\s*\:*\s*`), replace: ""},
+	{find: regexp.MustCompile(`(?mi)(^|\s)((?:ISTITUTO\s+COMPRENSIVO|I\.C\.|(?:IC))[\s\-]+)+`), replace: "${1}I.C. "},
+	{find: regexp.MustCompile(`(?mi)istruzione\s+secondaria\s*superiore`), replace: "I.S.S."},
+	{find: regexp.MustCompile(`(?mi)(?:^|\s)(primaria)(?:$|\s)`), replace: " EL "},
+	{find: regexp.MustCompile(`(?mi)(?:^|\s)(secondaria\s+(I°?|primo)\s+grado)(?:$|\s)`), replace: " SM "},
+	{find: regexp.MustCompile(`(?mi)(?:^|\s)(secondaria\s+(II°?|secondo)\s+grado)(?:$|\s)`), replace: " SUP "},
+	{find: regexp.MustCompile(`[\r\n]+`), replace: " "},
+	{find: regexp.MustCompile(`[\s\t]+`), replace: " "},
+	{find: regexp.MustCompile(`^\s*`), replace: ""},
+	{find: regexp.MustCompile(`\s*$`), replace: ""},
+}
+
+func rewriteSchoolNameWithRules(raw string) string {
+	for _, r := range schoolNameRewriteRules {
+		raw = r.find.ReplaceAllString(raw, r.replace)
+	}
+	return raw
+}
+
+type schoolCandidate struct {
+	school   School
+	typeCode string
+	// keys are the names the school is known as, used for the similarity comparison
+	keys     []string
+	known    bool
+	rawKeys  []string
+	rawNames []string
+	count    int
+	firstRow uint
+}
+
+func rawSchoolKey(row Row) string {
+	return nameToCode(row.schoolType) + "/" + nameToCode(row.schoolName)
+}
+
+// schoolNameKey ignores case, spaces and punctuation so that "IC Rivoli" and "I.C. RIVOLI" are the same school.
+func schoolNameKey(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	for _, ch := range strings.ToLower(name) {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+func schoolNameDigits(key string) string {
+	var b strings.Builder
+	for _, ch := range key {
+		if unicode.IsDigit(ch) {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+func knownSchoolKeys(known database.KnownSchool) []string {
+	names := append([]string{known.Name, known.ShortName}, known.Aliases...)
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if key := schoolNameKey(rewriteSchoolNameWithRules(name)); key != "" {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+func (c *schoolCandidate) similarity(key string, m strutil.StringMetric) float64 {
+	best := 0.0
+	for _, own := range c.keys {
+		if schoolNameDigits(own) != schoolNameDigits(key) {
+			continue
+		}
+		if similarity := strutil.Similarity(key, own, m); similarity > best {
+			best = similarity
+		}
+	}
+	return best
+}
+
+func lookupKnownSchool(names ...string) (database.KnownSchool, bool) {
+	for _, name := range names {
+		if known, ok := database.GetKnownSchool(name); ok {
+			return known, true
+		}
+	}
+	return database.KnownSchool{}, false
+}
+
+// resolveSchools maps the school written in each row to the canonical school.
+// Names are rewritten with the normalization rules, then looked up in the school registry
+// and finally merged with a similar school of the same type when the similarity is above the configured threshold.
+// The result is indexed by the raw school key of the rows.
+func resolveSchools(ctx config.WorkflowContext, rows []Row) (map[string]School, error) {
+	threshold := ctx.Config.SchoolDeduplicationThreshold
+	if threshold < 0 || threshold > 1 {
+		return nil, errors.Errorf("soglia di deduplicazione delle scuole non valida: %v (deve essere compresa tra 0 e 1)", threshold)
+	}
+
+	candidatesIndex := make(map[string]*schoolCandidate)
+	candidates := make([]*schoolCandidate, 0)
+	seenRawKeys := make(map[string]*schoolCandidate)
+
+	for _, row := range rows {
+		if nameToCode(row.BookingCode) == "" {
+			continue
+		}
+		rawKey := rawSchoolKey(row)
+		if candidate, ok := seenRawKeys[rawKey]; ok {
+			candidate.count++
+			if row.RowNumber < candidate.firstRow {
+				candidate.firstRow = row.RowNumber
+			}
+			continue
+		}
+
+		typeCode := nameToCode(row.schoolType)
+		rawName := cleanStringForVisualization(row.schoolName)
+		rewritten := rewriteSchoolNameWithRules(rawName)
+
+		var school School
+		// the first key is always the one of the name in the input
+		keys := []string{schoolNameKey(rewritten)}
+		known, isKnown := lookupKnownSchool(rewritten, rawName)
+		if isKnown {
+			keys = append(keys, knownSchoolKeys(known)...)
+			school = School{
+				Code:      typeCode + "/" + known.Code,
+				Name:      known.Name,
+				ShortName: known.ShortName,
+				City:      known.City,
+			}
+		} else {
+			school = School{
+				Code: typeCode + "/" + schoolNameKey(rewritten),
+				Name: rewritten,
+			}
+		}
+		school.Type = cleanStringForVisualization(row.schoolType)
+		school.Level = ParseSchoolLevel(row.schoolType)
+
+		candidate, ok := candidatesIndex[school.Code]
+		if !ok {
+			candidate = &schoolCandidate{
+				school:   school,
+				typeCode: typeCode,
+				keys:     keys,
+				known:    isKnown,
+				firstRow: row.RowNumber,
+			}
+			candidatesIndex[school.Code] = candidate
+			candidates = append(candidates, candidate)
+		} else if row.RowNumber < candidate.firstRow {
+			candidate.firstRow = row.RowNumber
+		}
+		candidate.count++
+		candidate.rawKeys = append(candidate.rawKeys, rawKey)
+		if rawName != "" && rawName != school.Name {
+			candidate.rawNames = append(candidate.rawNames, rawName)
+		}
+		seenRawKeys[rawKey] = candidate
+	}
+
+	// known schools first, then the most frequent ones: typos are rarer than the correct spelling
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.known != cj.known {
+			return ci.known
+		}
+		if ci.count != cj.count {
+			return ci.count > cj.count
+		}
+		if ci.firstRow != cj.firstRow {
+			return ci.firstRow < cj.firstRow
+		}
+		return ci.school.Code < cj.school.Code
+	})
+
+	stringSimilarityMetrics := metrics.NewJaroWinkler()
+	knownSchools := database.GetKnownSchools()
+
+	canonicals := make([]*schoolCandidate, 0, len(candidates))
+	out := make(map[string]School)
+
+	for _, candidate := range candidates {
+		if candidate.known || threshold == 0 || candidate.keys[0] == "" {
+			canonicals = append(canonicals, candidate)
+			continue
+		}
+
+		key := candidate.keys[0]
+
+		var best *schoolCandidate
+		bestSimilarity := 0.0
+
+		for _, canonical := range canonicals {
+			if canonical.typeCode != candidate.typeCode {
+				continue
+			}
+			similarity := canonical.similarity(key, stringSimilarityMetrics)
+			if similarity >= threshold && similarity > bestSimilarity {
+				best = canonical
+				bestSimilarity = similarity
+			}
+		}
+
+		if best == nil {
+			// a school of the registry which was not found in the input yet
+			for _, known := range knownSchools {
+				knownCandidate := &schoolCandidate{
+					school: School{
+						Code:      candidate.typeCode + "/" + known.Code,
+						Type:      candidate.school.Type,
+						Level:     candidate.school.Level,
+						Name:      known.Name,
+						ShortName: known.ShortName,
+						City:      known.City,
+					},
+					typeCode: candidate.typeCode,
+					keys:     knownSchoolKeys(known),
+					known:    true,
+				}
+				similarity := knownCandidate.similarity(key, stringSimilarityMetrics)
+				if similarity >= threshold && similarity > bestSimilarity {
+					bestSimilarity = similarity
+					best = knownCandidate
+				}
+			}
+			if best != nil {
+				canonicals = append(canonicals, best)
+			}
+		}
+
+		if best == nil {
+			canonicals = append(canonicals, candidate)
+			continue
+		}
+
+		ctx.Logger.Infof("school [%s] merged into [%s] (similarity: %.2f)", candidate.school.Name, best.school.Name, bestSimilarity)
+
+		best.rawKeys = append(best.rawKeys, candidate.rawKeys...)
+		best.rawNames = append(best.rawNames, candidate.rawNames...)
+		if candidate.school.Name != best.school.Name {
+			best.rawNames = append(best.rawNames, candidate.school.Name)
+		}
+	}
+
+	for _, canonical := range canonicals {
+		school := canonical.school
+		school.Aliases = uniqueSorted(canonical.rawNames, school.Name)
+		for _, rawKey := range canonical.rawKeys {
+			out[rawKey] = school
+		}
+	}
+
+	return out, nil
+}
+
+func uniqueSorted(values []string, exclude string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == exclude || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,8 +1,15 @@
-package excel
+package parser
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 func Test_rewriteSchoolNameWithRules(t *testing.T) {
@@ -122,3 +129,79 @@ func Test_rewriteSchoolNameWithRules(t *testing.T) {
 		})
 	}
 }
+
+func schoolRow(id int, schoolType, schoolName string) Row {
+	return Row{
+		InputRow: InputRow{
+			ID:          id,
+			RowNumber:   uint(id + 1),
+			BookingCode: fmt.Sprintf("B%d", id),
+			schoolType:  schoolType,
+			schoolName:  schoolName,
+		},
+	}
+}
+
+func TestHydrateGroupsMergesSchools(t *testing.T) {
+	rows := []Row{
+		schoolRow(1, "Primaria", "IC Rivoli"),
+		schoolRow(2, "Primaria", "I.C. Rivoli"),
+		schoolRow(3, "Primaria", "Istituto Comprensivo - RIVOLI"),
+		schoolRow(4, "Primaria", "I.C. Rivolli"),
+		schoolRow(5, "Primaria", "I.C. 1 San Mauro"),
+		schoolRow(6, "Primaria", "I.C. 2 San Mauro"),
+		schoolRow(7, "Secondaria I grado", "IC Rivoli"),
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{SchoolDeduplicationThreshold: 0.95})
+	_, groups, schools, classes, err := HydrateGroups(ctx, rows)
+	assert.NoError(t, err)
+	assert.Len(t, groups, 7)
+	assert.Len(t, classes, 7)
+
+	schoolCodes := make([]string, 0, len(schools))
+	for _, school := range schools {
+		schoolCodes = append(schoolCodes, school.Code)
+	}
+	assert.Equal(t, []string{"primaria/icrivoli", "primaria/ic1sanmauro", "primaria/ic2sanmauro", "secondariaigrado/icrivoli"}, schoolCodes)
+
+	assert.Equal(t, "I.C. Rivoli", schools[0].Name)
+	assert.Equal(t, []string{"I.C. Rivolli", "IC Rivoli", "Istituto Comprensivo - RIVOLI"}, schools[0].Aliases)
+	for _, group := range groups[:4] {
+		assert.Equal(t, "primaria/icrivoli", group.SchoolCode)
+	}
+
+	// fuzzy deduplication can be disabled
+	_, _, schools, _, err = HydrateGroups(testRuleContext(config.WorkflowContextConfig{}), rows)
+	assert.NoError(t, err)
+	assert.Len(t, schools, 5)
+
+	_, _, _, _, err = HydrateGroups(testRuleContext(config.WorkflowContextConfig{SchoolDeduplicationThreshold: 1.5}), rows)
+	assert.Error(t, err)
+}
+
+func TestHydrateGroupsUsesSchoolRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scuole.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[
+		{"code": "ic-alpignano", "name": "Istituto Comprensivo Alpignano", "short_name": "I.C. Alpignano", "city": "Alpignano", "aliases": ["Scuola Alpignano"]}
+	]`), 0o600))
+	t.Cleanup(database.ResetKnownSchools)
+	assert.NoError(t, database.LoadKnownSchools(path))
+
+	rows := []Row{
+		schoolRow(1, "Primaria", "ISTITUTO COMPRENSIVO - I.C. ALPIGNANO"),
+		schoolRow(2, "Primaria", "scuola alpignano"),
+		schoolRow(3, "Primaria", "I.C. Alpignanoo"),
+	}
+
+	ctx := testRuleContext(config.WorkflowContextConfig{SchoolDeduplicationThreshold: 0.95})
+	_, _, schools, _, err := HydrateGroups(ctx, rows)
+	assert.NoError(t, err)
+	if assert.Len(t, schools, 1) {
+		assert.Equal(t, "primaria/icalpignano", schools[0].Code)
+		assert.Equal(t, "Istituto Comprensivo Alpignano", schools[0].Name)
+		assert.Equal(t, "I.C. Alpignano", schools[0].DisplayName())
+		assert.Equal(t, "Alpignano", schools[0].City)
+		assert.Equal(t, "EL", schools[0].DisplayType())
+	}
+}
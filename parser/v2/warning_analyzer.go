@@ -1,45 +1,105 @@
 package parser
 
 import (
-	"strings"
+	"fmt"
+	"time"
 
 	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
 	"github.com/pkg/errors"
 )
 
-func EmitWarnings(ctx config.WorkflowContext, rows []Row, anagraphicsRef *OutputAnagraphics) ([]Row, error) {
+// activityDurationTolerance is the difference from the duration in the activity catalog that is not reported
+const activityDurationTolerance = 10 * time.Minute
+
+func EmitWarnings(
+	ctx config.WorkflowContext,
+	rows []Row,
+	anagraphicsRef *OutputAnagraphics,
+	lunchPlans []LunchPlan,
+	keywordRules []keywordRule,
+) ([]Row, error) {
 	out := make([]Row, 0, len(rows))
 
+	policy, err := newWarningPolicy(ctx.Config.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	overbookedLunches := overbookedLunchTurns(lunchPlans)
+
+	// the contacts belong to the group, they are reported only on its first row
+	firstRowOfGroup := make(map[string]Row)
+	for _, row := range rows {
+		first, ok := firstRowOfGroup[row.VisitingGroupCode]
+		if !ok || row.RowNumber < first.RowNumber || (row.RowNumber == first.RowNumber && row.ID < first.ID) {
+			firstRowOfGroup[row.VisitingGroupCode] = row
+		}
+	}
+
 	for _, row := range rows {
 		outCopy := row
-		warnings, err := emitWarningsForRow(ctx, outCopy, anagraphicsRef)
+		warnings, err := emitWarningsForRow(ctx, outCopy, anagraphicsRef, keywordRules)
 		if err != nil {
 			return nil, errors.Wrap(err, "errore nell'analisi di coerenza")
 		}
-		outCopy.Warnings = warnings
+		if turn, overbooked := overbookedLunches[row.ID]; overbooked {
+			warnings = append(warnings, Warning{
+				Code: "lunch-overbooked",
+				Message: fmt.Sprintf("TURNO PRANZO %s-%s OLTRE LA CAPIENZA: %d POSTI OCCUPATI SU %d",
+					turn.StartTime.Format("15:04"), turn.EndTime.Format("15:04"), turn.SeatsUsed, turn.Seats),
+			})
+		}
+		if row.VisitingGroupCode != "" && firstRowOfGroup[row.VisitingGroupCode].ID == row.ID {
+			warnings = append(warnings, emitContactWarnings(anagraphicsRef.VisitingGroups[row.VisitingGroupCode])...)
+		}
+		outCopy.Warnings = policy.apply(row, warnings)
 		out = append(out, outCopy)
 	}
 
 	return out, nil
 }
 
-func emitWarningsForRow(ctx config.WorkflowContext, row Row, anagraphicsRef *OutputAnagraphics) ([]Warning, error) {
+func emitWarningsForRow(ctx config.WorkflowContext, row Row, anagraphicsRef *OutputAnagraphics, keywordRules []keywordRule) ([]Warning, error) {
 	out := make([]Warning, 0)
 
 	activity := anagraphicsRef.Activities[row.ActivityCode]
 	room := anagraphicsRef.Rooms[row.RoomCode]
 
-	if strings.Contains(activity.Name, "??") {
+	for _, keywordRule := range keywordRules {
+		if keywordRule.Kind != config.KeywordRuleKindWarning {
+			continue
+		}
+		if value, matches := keywordRule.match(row); matches {
+			out = append(out, Warning{
+				Code:     keywordRule.Code,
+				Message:  keywordRule.message(value),
+				Severity: keywordRule.severity(),
+			})
+		}
+	}
+
+	if row.LunchTurnProposed {
+		out = append(out, Warning{
+			Code:     "lunch-turn-proposed",
+			Message:  "TURNO PRANZO PROPOSTO, DA CONFERMARE",
+			Severity: SeverityInfo,
+		})
+	}
+
+	if isLunchRow(row) && row.StartTime.IsZero() {
 		out = append(out, Warning{
-			Code:    "activity-question-marks",
-			Message: "QUESTA ATTIVITA' SEMBRA INDETERMINATA",
+			Code:     "lunch-turn-missing",
+			Message:  "PRANZO SENZA ORARIO, NESSUN TURNO DISPONIBILE",
+			Severity: SeverityError,
 		})
 	}
 
 	if row.RoomCode == "" {
 		out = append(out, Warning{
-			Code:    "no-room",
-			Message: "NESSUNA AULA O RISORSA ASSEGNATA",
+			Code:     "no-room",
+			Message:  "NESSUNA AULA O RISORSA ASSEGNATA",
+			Severity: SeverityError,
 		})
 	}
 
@@ -50,12 +110,111 @@ func emitWarningsForRow(ctx config.WorkflowContext, row Row, anagraphicsRef *Out
 		})
 	}
 
-	if activity.Language != "" && strings.ToLower(activity.Language) != "it" {
+	if operator, ok := anagraphicsRef.Operators[row.OperatorCode]; ok && !row.StartTime.IsZero() && !row.EndTime.IsZero() {
+		if absence, isAbsent := operator.AbsenceDuring(row.StartTime, row.EndTime); isAbsent {
+			message := "EDUCATORE ASSENTE: " + operator.Name
+			if absence.Note != "" {
+				message += " (" + absence.Note + ")"
+			}
+			out = append(out, Warning{
+				Code:     "operator-absent",
+				Message:  message,
+				Severity: SeverityError,
+			})
+		} else if !operator.IsWorkingDuring(row.StartTime, row.EndTime) {
+			out = append(out, Warning{
+				Code:    "operator-not-available",
+				Message: "ATTIVITA' FUORI DALL'ORARIO DI DISPONIBILITA' DI " + operator.Name,
+			})
+		}
+	}
+
+	if knownActivity, ok := database.GetKnownActivity(activity.CatalogCode); ok && activity.CatalogCode != "" {
+		duration := row.EndTime.Sub(row.StartTime)
+		if knownActivity.ExpectedDuration > 0 && !row.StartTime.IsZero() && !row.EndTime.IsZero() &&
+			!durationWithinTolerance(duration, knownActivity.ExpectedDuration) {
+			out = append(out, Warning{
+				Code: "activity-duration-mismatch",
+				Message: fmt.Sprintf("DURATA DI %d MINUTI, PER L'ATTIVITA' SONO PREVISTI %d MINUTI",
+					int(duration.Minutes()), int(knownActivity.ExpectedDuration.Minutes())),
+			})
+		}
+		school := anagraphicsRef.Schools[anagraphicsRef.VisitingGroups[row.VisitingGroupCode].SchoolCode]
+		if school.Level != "" && len(knownActivity.SchoolLevels) > 0 && !catalogAllowsSchoolLevel(knownActivity, school.Level) {
+			out = append(out, Warning{
+				Code:    "activity-school-level-mismatch",
+				Message: "ATTIVITA' NON PREVISTA PER IL LIVELLO SCOLASTICO: " + school.Level.Label(),
+			})
+		}
+		if row.RoomCode != "" && len(knownActivity.Rooms) > 0 && !catalogAllowsRoom(knownActivity, row.RoomCode) {
+			out = append(out, Warning{
+				Code:    "activity-room-mismatch",
+				Message: "AULA NON PREVISTA PER L'ATTIVITA': " + room.Name,
+			})
+		}
+	}
+
+	if operator, ok := anagraphicsRef.Operators[row.OperatorCode]; ok {
+		if row.RoomCode != "" && !operator.Skills.CoversRoom(row.RoomCode) {
+			out = append(out, Warning{
+				Code:    "operator-room-skill",
+				Message: operator.Name + " NON E' ABILITATO PER L'AULA: " + room.Name,
+			})
+		}
+		if activity.TypeCode != "" && !operator.Skills.CoversActivityType(activity.TypeCode) {
+			out = append(out, Warning{
+				Code:    "operator-activity-type-skill",
+				Message: operator.Name + " NON E' ABILITATO PER LA TIPOLOGIA: " + anagraphicsRef.ActivityTypes[activity.TypeCode].Name,
+			})
+		}
+		if !operator.Skills.CoversLanguage(activity.Language) {
+			out = append(out, Warning{
+				Code:    "operator-language-skill",
+				Message: operator.Name + " NON HA COMPETENZE NELLA LINGUA: " + activity.Language,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+func emitContactWarnings(group VisitingGroup) []Warning {
+	out := make([]Warning, 0)
+	for _, invalid := range group.InvalidEmails {
+		out = append(out, Warning{
+			Code:    "invalid-email",
+			Message: "EMAIL REFERENTE NON VALIDA: " + invalid,
+		})
+	}
+	for _, correction := range group.EmailCorrections {
 		out = append(out, Warning{
-			Code:    "non-it-lang",
-			Message: "ATTIVITA' PREVISTA IN LINGUA: " + activity.Language,
+			Code:     "email-corrected",
+			Message:  "EMAIL REFERENTE CORRETTA: " + correction.Original + " -> " + correction.Corrected,
+			Severity: SeverityInfo,
 		})
 	}
+	return out
+}
 
-	return out, nil
+// durationWithinTolerance tells whether the duration differs from the expected one at most by activityDurationTolerance
+func durationWithinTolerance(duration, expected time.Duration) bool {
+	return absDuration(duration-expected) <= activityDurationTolerance
+}
+
+func catalogAllowsRoom(knownActivity database.KnownActivity, roomCode string) bool {
+	for _, allowed := range knownActivity.Rooms {
+		if resolveRoomCode(allowed) == roomCode {
+			return true
+		}
+	}
+	return false
+}
+
+func catalogAllowsSchoolLevel(knownActivity database.KnownActivity, level SchoolLevel) bool {
+	for _, allowed := range knownActivity.SchoolLevels {
+		if ParseSchoolLevel(allowed) == level {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/database"
+)
+
+func TestActivityCatalogWarnings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attivita.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`[
+		{"code": "sistema-solare", "name": "Sistema Solare", "duration_minutes": 60, "rooms": ["Planetario"]}
+	]`), 0o600))
+	t.Cleanup(database.ResetKnownActivities)
+	assert.NoError(t, database.LoadKnownActivities(path))
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			"planetario": {Code: "planetario", Name: "Planetario"},
+			"museo":      {Code: "museo", Name: "Museo"},
+		},
+		Activities: map[string]Activity{
+			"a": {Code: "a", Name: "Sistema Solare", CatalogCode: "sistemasolare"},
+		},
+	}
+
+	type testCase struct {
+		room       string
+		start, end time.Time
+		expected   []string
+	}
+
+	testCases := []testCase{
+		{"planetario", at(9, 0), at(10, 0), nil},
+		// a few minutes more or less are tolerated
+		{"planetario", at(9, 0), at(10, 10), nil},
+		{"planetario", at(9, 0), at(9, 50), nil},
+		{"planetario", at(9, 0), at(10, 15), []string{"activity-duration-mismatch"}},
+		{"planetario", at(9, 0), at(9, 30), []string{"activity-duration-mismatch"}},
+		{"museo", at(9, 0), at(10, 0), []string{"activity-room-mismatch"}},
+		{"museo", at(9, 0), at(11, 0), []string{"activity-duration-mismatch", "activity-room-mismatch"}},
+		// without times the duration is not checked
+		{"planetario", time.Time{}, time.Time{}, nil},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			row := Row{
+				InputRow:     InputRow{ID: 1, Date: testDay, StartTime: testCase.start, EndTime: testCase.end},
+				RoomCode:     testCase.room,
+				ActivityCode: "a",
+			}
+			warnings, err := emitWarningsForRow(testRuleContext(config.WorkflowContextConfig{}), row, anagraphics, nil)
+			assert.NoError(t, err)
+
+			var codes []string
+			for _, warning := range warnings {
+				codes = append(codes, warning.Code)
+			}
+			assert.Equal(t, testCase.expected, codes)
+		})
+	}
+}
+
+func TestOperatorAvailabilityWarnings(t *testing.T) {
+	anagraphics := &OutputAnagraphics{
+		Operators: map[string]Operator{
+			"jo": {Code: "jo", Name: "Jo", Availability: []OperatorAvailability{
+				{Date: testDay, StartTime: at(9, 0), EndTime: at(13, 0)},
+				{Date: testDay, StartTime: at(11, 0), EndTime: at(12, 0), IsAbsence: true, Note: "dentista"},
+			}},
+		},
+	}
+
+	type testCase struct {
+		start, end time.Time
+		expected   []Warning
+	}
+
+	testCases := []testCase{
+		{at(9, 0), at(10, 0), nil},
+		{at(10, 30), at(11, 30), []Warning{{Code: "operator-absent", Message: "EDUCATORE ASSENTE: Jo (dentista)", Severity: SeverityError}}},
+		{at(14, 0), at(15, 0), []Warning{{Code: "operator-not-available", Message: "ATTIVITA' FUORI DALL'ORARIO DI DISPONIBILITA' DI Jo"}}},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			row := Row{
+				InputRow:     InputRow{ID: 1, Date: testDay, StartTime: testCase.start, EndTime: testCase.end},
+				OperatorCode: "jo",
+			}
+			warnings, err := emitWarningsForRow(testRuleContext(config.WorkflowContextConfig{}), row, anagraphics, nil)
+			assert.NoError(t, err)
+
+			var availabilityWarnings []Warning
+			for _, warning := range warnings {
+				if warning.Code == "operator-absent" || warning.Code == "operator-not-available" {
+					availabilityWarnings = append(availabilityWarnings, warning)
+				}
+			}
+			assert.Equal(t, testCase.expected, availabilityWarnings)
+		})
+	}
+}
+
+func TestOperatorSkillWarnings(t *testing.T) {
+	ctx := testRuleContext(config.WorkflowContextConfig{
+		OperatorSkills: map[string]config.OperatorSkillsConfig{
+			"Marco": {Rooms: []string{"Planetario"}, ActivityTypes: []string{"Visita"}, Languages: []string{"inglese"}},
+		},
+	})
+
+	operators := assignOperatorSkills(ctx, []Operator{
+		{Code: "marco", Name: "Marco", Known: true},
+		{Code: "jonida", Name: "Jo", Known: true},
+	})
+	assert.Equal(t, OperatorSkills{Rooms: []string{"planetario"}, ActivityTypes: []string{"Visita"}, Languages: []string{"inglese"}},
+		operators[0].Skills)
+	assert.Equal(t, OperatorSkills{}, operators[1].Skills)
+
+	anagraphics := &OutputAnagraphics{
+		Rooms: map[string]Room{
+			"planetario": {Code: "planetario", Name: "Planetario"},
+			"museo":      {Code: "museo", Name: "Museo"},
+		},
+		Operators: map[string]Operator{},
+		Activities: map[string]Activity{
+			"visita":      {Code: "visita", TypeCode: "visita", Language: "en"},
+			"laboratorio": {Code: "laboratorio", TypeCode: "laboratorio", Language: "francese"},
+		},
+		ActivityTypes: map[string]ActivityType{
+			"visita":      {Code: "visita", Name: "Visita"},
+			"laboratorio": {Code: "laboratorio", Name: "Laboratorio"},
+		},
+	}
+	for _, operator := range operators {
+		anagraphics.Operators[operator.Code] = operator
+	}
+
+	type testCase struct {
+		operator string
+		room     string
+		activity string
+		expected []string
+	}
+
+	testCases := []testCase{
+		{"marco", "planetario", "visita", nil},
+		{"marco", "museo", "visita", []string{"operator-room-skill"}},
+		{"marco", "planetario", "laboratorio", []string{"operator-activity-type-skill", "operator-language-skill"}},
+		// an operator without restrictions is never warned
+		{"jonida", "museo", "laboratorio", nil},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			row := Row{
+				InputRow:     InputRow{ID: 1, Date: testDay},
+				RoomCode:     testCase.room,
+				ActivityCode: testCase.activity,
+				OperatorCode: testCase.operator,
+			}
+			warnings, err := emitWarningsForRow(ctx, row, anagraphics, nil)
+			assert.NoError(t, err)
+
+			var codes []string
+			for _, warning := range warnings {
+				codes = append(codes, warning.Code)
+			}
+			assert.Equal(t, testCase.expected, codes)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/pkg/errors"
+)
+
+// warningPolicy applies the configured severities and suppressions to the emitted warnings.
+type warningPolicy struct {
+	suppressed          map[string]bool
+	suppressedByBooking map[string]map[string]bool
+	severities          map[string]WarningSeverity
+}
+
+func newWarningPolicy(cfg config.WarningsConfig) (warningPolicy, error) {
+	out := warningPolicy{
+		suppressed:          make(map[string]bool),
+		suppressedByBooking: make(map[string]map[string]bool),
+		severities:          make(map[string]WarningSeverity),
+	}
+
+	for _, code := range cfg.Suppress {
+		out.suppressed[code] = true
+	}
+	for bookingCode, codes := range cfg.SuppressByBookingCode {
+		key := nameToCode(bookingCode)
+		if out.suppressedByBooking[key] == nil {
+			out.suppressedByBooking[key] = make(map[string]bool)
+		}
+		for _, code := range codes {
+			out.suppressedByBooking[key][code] = true
+		}
+	}
+	for code, raw := range cfg.Severities {
+		severity, ok := ParseWarningSeverity(raw)
+		if !ok {
+			return out, errors.Errorf("gravità '%s' non valida per l'avviso %s", raw, code)
+		}
+		out.severities[code] = severity
+	}
+
+	return out, nil
+}
+
+func (p warningPolicy) apply(row Row, warnings []Warning) []Warning {
+	out := make([]Warning, 0, len(warnings))
+	for _, w := range warnings {
+		if p.suppressed[w.Code] || p.suppressedByBooking[nameToCode(row.BookingCode)][w.Code] {
+			continue
+		}
+		if severity, ok := p.severities[w.Code]; ok {
+			w.Severity = severity
+		} else if w.Severity == "" {
+			w.Severity = SeverityWarning
+		}
+		out = append(out, w)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"sort"
+)
+
+type WarningSummary struct {
+	Code     string
+	Severity WarningSeverity
+	// Message is the message of the warning, empty if it changes from row to row
+	Message    string
+	Count      int
+	RowNumbers []uint
+}
+
+// SummarizeWarnings groups the warnings by code, the most severe first.
+func SummarizeWarnings(rows []OutputRow) []WarningSummary {
+	index := make(map[string]*WarningSummary)
+
+	sorted := make([]OutputRow, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RowNumber < sorted[j].RowNumber
+	})
+
+	for _, row := range sorted {
+		for _, w := range row.Warnings {
+			summary, ok := index[w.Code]
+			if !ok {
+				summary = &WarningSummary{
+					Code:     w.Code,
+					Severity: w.Severity,
+					Message:  w.Message,
+				}
+				index[w.Code] = summary
+			}
+			if summary.Message != w.Message {
+				summary.Message = ""
+			}
+			if w.Severity.Rank() > summary.Severity.Rank() {
+				summary.Severity = w.Severity
+			}
+			summary.Count++
+			if n := len(summary.RowNumbers); row.RowNumber > 0 && (n == 0 || summary.RowNumbers[n-1] != row.RowNumber) {
+				summary.RowNumbers = append(summary.RowNumbers, row.RowNumber)
+			}
+		}
+	}
+
+	out := make([]WarningSummary, 0, len(index))
+	for _, summary := range index {
+		out = append(out, *summary)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Severity.Rank() != out[j].Severity.Rank() {
+			return out[i].Severity.Rank() > out[j].Severity.Rank()
+		}
+		return out[i].Code < out[j].Code
+	})
+
+	return out
+}
+
+// CountWarningsAtLeast counts the warnings with at least the given severity.
+func CountWarningsAtLeast(summaries []WarningSummary, severity WarningSeverity) int {
+	count := 0
+	for _, summary := range summaries {
+		if summary.Severity.Rank() >= severity.Rank() {
+			count += summary.Count
+		}
+	}
+	return count
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func TestSummarizeWarnings(t *testing.T) {
+	noRoom := Warning{Code: "no-room", Message: "NESSUNA AULA", Severity: SeverityError}
+	lang := func(l string) Warning {
+		return Warning{Code: "non-it-lang", Message: "LINGUA: " + l, Severity: SeverityInfo}
+	}
+
+	rows := []OutputRow{
+		{RowNumber: 9, Warnings: []Warning{lang("EN")}},
+		{RowNumber: 7, Warnings: []Warning{noRoom, lang("FR")}},
+		{RowNumber: 5, Warnings: []Warning{noRoom}},
+		{RowNumber: 6},
+	}
+
+	summaries := SummarizeWarnings(rows)
+
+	assert.Equal(t, []WarningSummary{
+		{Code: "no-room", Severity: SeverityError, Message: "NESSUNA AULA", Count: 2, RowNumbers: []uint{5, 7}},
+		{Code: "non-it-lang", Severity: SeverityInfo, Message: "", Count: 2, RowNumbers: []uint{7, 9}},
+	}, summaries)
+
+	assert.Equal(t, 2, CountWarningsAtLeast(summaries, SeverityError))
+	assert.Equal(t, 2, CountWarningsAtLeast(summaries, SeverityWarning))
+	assert.Equal(t, 4, CountWarningsAtLeast(summaries, SeverityInfo))
+}
+
+func TestWarningPolicy(t *testing.T) {
+	policy, err := newWarningPolicy(config.WarningsConfig{
+		Suppress:              []string{"no-operator"},
+		SuppressByBookingCode: map[string][]string{"B 001": {"non-it-lang"}},
+		Severities:            map[string]string{"no-room": "Info"},
+	})
+	assert.NoError(t, err)
+
+	warnings := []Warning{
+		{Code: "no-operator"},
+		{Code: "non-it-lang"},
+		{Code: "no-room", Severity: SeverityError},
+		{Code: "other"},
+	}
+
+	assert.Equal(t, []Warning{
+		{Code: "no-room", Severity: SeverityInfo},
+		{Code: "other", Severity: SeverityWarning},
+	}, policy.apply(Row{InputRow: InputRow{BookingCode: "b001"}}, warnings))
+
+	assert.Equal(t, []Warning{
+		{Code: "non-it-lang", Severity: SeverityWarning},
+		{Code: "no-room", Severity: SeverityInfo},
+		{Code: "other", Severity: SeverityWarning},
+	}, policy.apply(Row{InputRow: InputRow{BookingCode: "B002"}}, warnings))
+
+	_, err = newWarningPolicy(config.WarningsConfig{Severities: map[string]string{"no-room": "fatal"}})
+	assert.Error(t, err)
+}
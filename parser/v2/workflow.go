@@ -8,7 +8,8 @@ import (
 
 func Execute(ctx config.WorkflowContext, rawInput reader.Output) (Output, error) {
 	input := Input{
-		Rows: ToInputRows(rawInput.Rows),
+		Rows:         ToInputRows(rawInput.Rows),
+		Availability: ToInputAvailability(rawInput.Availability),
 	}
 
 	rowsWithRooms, rooms, err := HydrateRooms(ctx, input.Rows)
@@ -16,17 +17,31 @@ func Execute(ctx config.WorkflowContext, rawInput reader.Output) (Output, error)
 		return Output{}, errors.Wrap(err, "errore nella lettura delle aule")
 	}
 
+	keywordRules, err := effectiveKeywordRules(ctx)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nelle regole per parole chiave")
+	}
+
 	rowsWithOperators, operators, err := HydrateOperators(ctx, rowsWithRooms)
 	if err != nil {
 		return Output{}, errors.Wrap(err, "errore nella lettura degli educatori")
 	}
 
+	operators, err = HydrateOperatorAvailability(ctx, input.Availability, operators)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nella lettura delle disponibilità degli educatori")
+	}
+
+	// after the availability, that can add operators not referenced by the rows
+	operators = assignOperatorColors(ctx, operators)
+	operators = assignOperatorSkills(ctx, operators)
+
 	rowsWithGroups, groups, schools, schoolClasses, err := HydrateGroups(ctx, rowsWithOperators)
 	if err != nil {
 		return Output{}, errors.Wrap(err, "errore nella lettura dei gruppi scuola")
 	}
 
-	rowsWithGroups, groups, err = ApplyRuleB0Level(ctx, rowsWithGroups, groups)
+	rowsWithGroups, groups, normalizations, err := ApplyRuleB0Level(ctx, rowsWithGroups, groups, keywordRules)
 	if err != nil {
 		return Output{}, errors.Wrap(err, "errore nell'applicazione delle regole di livello B0")
 	}
@@ -46,6 +61,7 @@ func Execute(ctx config.WorkflowContext, rawInput reader.Output) (Output, error)
 		SchoolClasses:  make(map[string]SchoolClass),
 		Activities:     make(map[string]Activity),
 		ActivityTypes:  make(map[string]ActivityType),
+		HighlightKinds: buildHighlightKinds(keywordRules),
 	}
 
 	for _, o := range rooms {
@@ -70,14 +86,30 @@ func Execute(ctx config.WorkflowContext, rawInput reader.Output) (Output, error)
 		anagraphics.ActivityTypes[o.Code] = o
 	}
 
-	rowsWithWarnings, err := EmitWarnings(ctx, rowsWithActivities, &anagraphics)
+	var suggestions []OperatorSuggestion
+	if ctx.Config.EnableOperatorSuggestions {
+		rowsWithActivities, suggestions, err = SuggestOperators(ctx, rowsWithActivities, &anagraphics)
+		if err != nil {
+			return Output{}, errors.Wrap(err, "errore nella proposta degli educatori")
+		}
+	}
+
+	rowsWithActivities, lunchPlans, err := PlanLunches(ctx, rowsWithActivities, &anagraphics)
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nella pianificazione dei pranzi")
+	}
+
+	rowsWithWarnings, err := EmitWarnings(ctx, rowsWithActivities, &anagraphics, lunchPlans, keywordRules)
 	if err != nil {
 		return Output{}, errors.Wrap(err, "errore nella ricerca dei warning")
 	}
 
 	out := Output{
-		Anagraphics: &anagraphics,
-		Rows:        ToOutputRows(rowsWithWarnings, &anagraphics),
+		Anagraphics:         &anagraphics,
+		Rows:                ToOutputRows(rowsWithWarnings, &anagraphics),
+		OperatorSuggestions: suggestions,
+		Normalizations:      normalizations,
+		LunchPlans:          lunchPlans,
 	}
 
 	return out, nil
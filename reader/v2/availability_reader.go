@@ -0,0 +1,74 @@
+package reader
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/excel"
+)
+
+// ReadAvailability reads the operators' absences and working hours.
+// When availabilityFile is specified the data is read from its availability sheet (or its first sheet),
+// otherwise the input file is searched for an availability sheet. Missing data is not an error.
+func ReadAvailability(ctx config.WorkflowContext, inputFile string, availabilityFile string) ([]AvailabilityRow, error) {
+	log := ctx.Logger
+
+	source := inputFile
+	if availabilityFile != "" {
+		source = availabilityFile
+	}
+
+	f, err := excelize.OpenFile(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening availability file %s", source)
+	}
+
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			log.Errorf("error closing availability file: %s", closeErr.Error())
+		}
+	}()
+
+	sheetName := findAvailabilitySheet(f)
+	if sheetName == "" {
+		if availabilityFile == "" {
+			log.Debug("no availability sheet found in input file")
+			return nil, nil
+		}
+		sheetName = f.GetSheetName(0)
+	}
+
+	log.Debugf("reading operators availability from sheet '%s' of %s", sheetName, source)
+
+	tableRows, err := readTable(ctx, f, excel.NewCell(sheetName, 1, 1), reflect.TypeOf(AvailabilityRow{}))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]AvailabilityRow, 0, len(tableRows))
+	for _, tableRow := range tableRows {
+		row := AvailabilityRow{
+			rowNumber: tableRow.rowNumber,
+		}
+		tableRow.assignTo(&row)
+		results = append(results, row)
+	}
+
+	log.Infof("found %d availability entries for operators", len(results))
+
+	return results, nil
+}
+
+func findAvailabilitySheet(f *excelize.File) string {
+	for _, sheetName := range f.GetSheetList() {
+		for _, candidate := range AvailabilitySheetNames {
+			if stringToCode(sheetName) == stringToCode(candidate) {
+				return sheetName
+			}
+		}
+	}
+	return ""
+}
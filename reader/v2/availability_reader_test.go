@@ -0,0 +1,112 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func writeAvailabilityFile(t *testing.T, sheetName string, rows [][]string) string {
+	f := excelize.NewFile()
+	if sheetName != "" {
+		assert.NoError(t, f.SetSheetName("Sheet1", sheetName))
+	}
+	header := []string{"educatore", "data", "orario", "tipo", "nota"}
+	for r, row := range append([][]string{header}, rows...) {
+		for c, value := range row {
+			cell, _ := excelize.CoordinatesToCellName(c+1, r+1)
+			assert.NoError(t, f.SetCellValue(f.GetSheetName(0), cell, value))
+		}
+	}
+	path := filepath.Join(t.TempDir(), "disponibilita.xlsx")
+	assert.NoError(t, f.SaveAs(path))
+	return path
+}
+
+func TestReadAvailability(t *testing.T) {
+	rome := config.TimeZone()
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	input := writeAvailabilityFile(t, "Assenze", [][]string{
+		{"Marco", "10/03/2025", "", "", "ferie"},
+		{"Jo", "10/03/2025", "14:00-16:00", "Assenza", ""},
+		{"Jo", "11/03/2025", "09:00-13:00", "presenza", ""},
+	})
+
+	rows, err := ReadAvailability(ctx, input, "")
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateAvailability(rows))
+	rows, err = ConvertAvailability(ctx, rows)
+	if !assert.NoError(t, err) || !assert.Len(t, rows, 3) {
+		return
+	}
+
+	assert.Equal(t, "Marco", rows[0].Operator)
+	assert.Equal(t, "ferie", rows[0].Note)
+	assert.True(t, rows[0].AllDay)
+	assert.True(t, rows[0].IsAbsence)
+	assert.True(t, time.Date(2025, 3, 10, 0, 0, 0, 0, rome).Equal(rows[0].StartTime))
+	assert.True(t, time.Date(2025, 3, 11, 0, 0, 0, 0, rome).Equal(rows[0].EndTime))
+
+	assert.False(t, rows[1].AllDay)
+	assert.True(t, rows[1].IsAbsence)
+	assert.True(t, time.Date(2025, 3, 10, 14, 0, 0, 0, rome).Equal(rows[1].StartTime))
+	assert.True(t, time.Date(2025, 3, 10, 16, 0, 0, 0, rome).Equal(rows[1].EndTime))
+
+	assert.False(t, rows[2].IsAbsence)
+
+	// a separate file is read from its first sheet, whatever its name
+	rows, err = ReadAvailability(ctx, "", writeAvailabilityFile(t, "", [][]string{{"Marco", "10/03/2025"}}))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	// the input file without an availability sheet has no availability
+	rows, err = ReadAvailability(ctx, writeAvailabilityFile(t, "Organizzazione", nil), "")
+	assert.NoError(t, err)
+	assert.Nil(t, rows)
+}
+
+func TestAvailabilityRowErrors(t *testing.T) {
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+
+	type testCase struct {
+		row             AvailabilityRow
+		validationError bool
+		conversionError bool
+	}
+
+	testCases := []testCase{
+		{AvailabilityRow{Operator: "Marco", DateRawString: "10/03/2025", TimesRawString: "09:00-13:00"}, false, false},
+		{AvailabilityRow{DateRawString: "10/03/2025"}, true, false},
+		{AvailabilityRow{Operator: "Marco", DateRawString: "10-03-2025"}, true, false},
+		{AvailabilityRow{Operator: "Marco", DateRawString: "10/03/2025", TimesRawString: "mattina"}, true, false},
+		{AvailabilityRow{Operator: "Marco", DateRawString: "10/03/2025", KindRawString: "boh"}, false, true},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			err := ValidateAvailability([]AvailabilityRow{testCase.row})
+			assert.Equal(t, testCase.validationError, err != nil, "validation error: %v", err)
+			if err != nil {
+				return
+			}
+			_, err = ConvertAvailability(ctx, []AvailabilityRow{testCase.row})
+			assert.Equal(t, testCase.conversionError, err != nil, "conversion error: %v", err)
+		})
+	}
+}
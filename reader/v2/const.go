@@ -4,3 +4,9 @@ const (
 	MaxHeaders       = 5000
 	DefaultSheetName = "Organizzazione"
 )
+
+var (
+	// AvailabilitySheetNames are the names (compared as codes) of the optional
+	// sheet listing the operators' absences and working hours.
+	AvailabilitySheetNames = []string{"disponibilità", "disponibilita", "assenze", "presenze"}
+)
@@ -17,11 +17,11 @@ const (
 	layoutDateOnlyInITFormat  = "02/01/2006"
 )
 
-func Convert(rows []Row) ([]Row, error) {
+func Convert(ctx config.WorkflowContext, rows []Row) ([]Row, error) {
 	out := make([]Row, 0, len(rows))
 
 	for _, row := range rows {
-		converted, err := convertRow(row)
+		converted, err := convertRow(ctx, row)
 		if err != nil {
 			return nil, errors.Wrapf(err, "errore nella riga %d", row.rowNumber)
 		}
@@ -31,17 +31,29 @@ func Convert(rows []Row) ([]Row, error) {
 	return out, nil
 }
 
-func convertRow(r Row) (Row, error) {
+func convertRow(ctx config.WorkflowContext, r Row) (Row, error) {
 
-	dataHalfDay, start, end, err := getStartAndEndTimes(r)
-	if err != nil {
-		return Row{}, err
-	}
+	var err error
+	if strings.TrimSpace(r.TimesRawString) == "" {
+		// only a lunch can be booked without a turn, the parser proposes one
+		if !isLunchRoom(r.Room) {
+			return Row{}, errors.New("orario mancante, atteso HH:MM-HH:MM")
+		}
+		r.Date, err = parseDate(r.DateRawString, ctx.Config.Location())
+		if err != nil {
+			return Row{}, err
+		}
+	} else {
+		dataHalfDay, start, end, err := getStartAndEndTimes(r, ctx.Config.Location())
+		if err != nil {
+			return Row{}, err
+		}
 
-	r.Date = dataHalfDay
-	r.StartTime = start
-	r.EndTime = end
-	r.Duration = r.EndTime.Sub(r.StartTime)
+		r.Date = dataHalfDay
+		r.StartTime = start
+		r.EndTime = end
+		r.Duration = r.EndTime.Sub(r.StartTime)
+	}
 
 	if r.NumPayingRawString != "" {
 		r.NumPaying, err = strconv.Atoi(r.NumPayingRawString)
@@ -73,17 +85,32 @@ func convertRow(r Row) (Row, error) {
 	return r, nil
 }
 
-func getStartAndEndTimes(r Row) (time.Time, time.Time, time.Time, error) {
-	localTimeZone := config.TimeZone()
+func getStartAndEndTimes(r Row, localTimeZone *time.Location) (time.Time, time.Time, time.Time, error) {
+	return parseDateAndTimes(r.DateRawString, r.TimesRawString, localTimeZone)
+}
+
+func parseDate(dateRawString string, localTimeZone *time.Location) (time.Time, error) {
+	data, err := time.Parse(layoutDateOnlyInITFormat, dateRawString)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "il valore '%s' non e' una data valida nel formato 'GG/MM/YYYY'", dateRawString)
+	}
+
+	return time.Date(data.Year(), data.Month(), data.Day(), 12, 0, 0, 0, localTimeZone), nil
+}
 
-	data, err := time.Parse(layoutDateOnlyInITFormat, r.DateRawString)
+func parseDateAndTimes(dateRawString, timesRawString string, localTimeZone *time.Location) (time.Time, time.Time, time.Time, error) {
+	data, err := parseDate(dateRawString, localTimeZone)
 	if err != nil {
-		return time.Time{}, time.Time{}, time.Time{}, errors.Wrapf(err, "il valore '%s' non e' una data valida nel formato 'GG/MM/YYYY'", r.DateRawString)
+		return time.Time{}, time.Time{}, time.Time{}, err
 	}
 
-	dataHalfDay := time.Date(data.Year(), data.Month(), data.Day(), 12, 0, 0, 0, localTimeZone)
+	dataHalfDay := data
 
-	orari := strings.Split(r.TimesRawString, "-")
+	orari := strings.Split(timesRawString, "-")
+	if len(orari) != 2 {
+		return time.Time{}, time.Time{}, time.Time{}, errors.Errorf(
+			"il valore '%s' non e' un intervallo di orari valido nel formato 'HH:MM-HH:MM'", timesRawString)
+	}
 
 	v := strings.TrimSpace(orari[0])
 	start, err := time.Parse(layoutTimeOnlyWithMinutes, v)
@@ -101,10 +128,10 @@ func getStartAndEndTimes(r Row) (time.Time, time.Time, time.Time, error) {
 	v = strings.TrimSpace(orari[1])
 	end, err := time.Parse(layoutTimeOnlyWithMinutes, v)
 	if err != nil {
-		start, err = time.Parse(layoutTimeOnlyWithSeconds, v)
+		end, err = time.Parse(layoutTimeOnlyWithSeconds, v)
 	}
 	if err != nil {
-		start, err = time.Parse(layoutTimeOnlyWithHours, v)
+		end, err = time.Parse(layoutTimeOnlyWithHours, v)
 	}
 	if err != nil {
 		return time.Time{}, time.Time{}, time.Time{}, errors.Wrapf(err,
@@ -138,3 +165,64 @@ func parseLocalizedBoolean(raw string) (*bool, error) {
 
 	return nil, errors.Errorf("il valore '%s' non è un flag booleano valido", raw)
 }
+
+func ConvertAvailability(ctx config.WorkflowContext, rows []AvailabilityRow) ([]AvailabilityRow, error) {
+	out := make([]AvailabilityRow, 0, len(rows))
+
+	for _, row := range rows {
+		converted, err := convertAvailabilityRow(row, ctx.Config.Location())
+		if err != nil {
+			return nil, errors.Wrapf(err, "errore nella riga %d delle disponibilità", row.rowNumber)
+		}
+		out = append(out, converted)
+	}
+
+	return out, nil
+}
+
+func convertAvailabilityRow(r AvailabilityRow, localTimeZone *time.Location) (AvailabilityRow, error) {
+	var err error
+
+	if strings.TrimSpace(r.TimesRawString) == "" {
+		r.Date, err = parseDate(r.DateRawString, localTimeZone)
+		if err != nil {
+			return AvailabilityRow{}, err
+		}
+		r.AllDay = true
+		r.StartTime = time.Date(r.Date.Year(), r.Date.Month(), r.Date.Day(), 0, 0, 0, 0, r.Date.Location())
+		r.EndTime = r.StartTime.AddDate(0, 0, 1)
+	} else {
+		r.Date, r.StartTime, r.EndTime, err = parseDateAndTimes(r.DateRawString, r.TimesRawString, localTimeZone)
+		if err != nil {
+			return AvailabilityRow{}, err
+		}
+	}
+
+	r.IsAbsence, err = parseAvailabilityKind(r.KindRawString)
+	if err != nil {
+		return AvailabilityRow{}, errors.Wrapf(err, "il valore del campo 'tipo' ('%s') non e' valido", r.KindRawString)
+	}
+
+	return r, nil
+}
+
+// parseAvailabilityKind tells absences (the default) from working hours.
+func parseAvailabilityKind(raw string) (bool, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return true, nil
+	}
+
+	for _, prefix := range []string{"assen", "ferie", "malattia", "permesso", "riposo"} {
+		if strings.HasPrefix(raw, prefix) {
+			return true, nil
+		}
+	}
+	for _, prefix := range []string{"presen", "disponib", "turno", "orario", "lavoro"} {
+		if strings.HasPrefix(raw, prefix) {
+			return false, nil
+		}
+	}
+
+	return false, errors.Errorf("atteso 'assenza' o 'presenza' e non '%s'", raw)
+}
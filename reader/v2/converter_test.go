@@ -0,0 +1,53 @@
+package reader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+func TestParseDateAndTimes(t *testing.T) {
+	rome := config.TimeZone()
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		date          string
+		times         string
+		timeZone      *time.Location
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}
+
+	testCases := []testCase{
+		{"10/03/2025", "09:30-10:30", rome, time.Date(2025, 3, 10, 9, 30, 0, 0, rome), time.Date(2025, 3, 10, 10, 30, 0, 0, rome)},
+		// the end before the start is on the day after
+		{"10/03/2025", "21:00-00:30", rome, time.Date(2025, 3, 10, 21, 0, 0, 0, rome), time.Date(2025, 3, 11, 0, 30, 0, 0, rome)},
+		{"10/03/2025", "23:30-01", rome, time.Date(2025, 3, 10, 23, 30, 0, 0, rome), time.Date(2025, 3, 11, 1, 0, 0, 0, rome)},
+		{"31/12/2025", "22:00-02:00:00", rome, time.Date(2025, 12, 31, 22, 0, 0, 0, rome), time.Date(2026, 1, 1, 2, 0, 0, 0, rome)},
+		// ending exactly at midnight
+		{"10/03/2025", "22:00-00:00", rome, time.Date(2025, 3, 10, 22, 0, 0, 0, rome), time.Date(2025, 3, 11, 0, 0, 0, 0, rome)},
+		// after midnight, written on the calendar day
+		{"11/03/2025", "00:30-01:30", rome, time.Date(2025, 3, 11, 0, 30, 0, 0, rome), time.Date(2025, 3, 11, 1, 30, 0, 0, rome)},
+		// times are read in the configured time zone
+		{"10/03/2025", "21:00-00:30", newYork, time.Date(2025, 3, 10, 21, 0, 0, 0, newYork), time.Date(2025, 3, 11, 0, 30, 0, 0, newYork)},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			date, start, end, err := parseDateAndTimes(testCase.date, testCase.times, testCase.timeZone)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.timeZone, date.Location())
+			assert.True(t, testCase.expectedStart.Equal(start), "expected start %s, got %s", testCase.expectedStart, start)
+			assert.True(t, testCase.expectedEnd.Equal(end), "expected end %s, got %s", testCase.expectedEnd, end)
+			assert.Equal(t, testCase.timeZone, start.Location())
+		})
+	}
+}
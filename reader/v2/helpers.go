@@ -3,6 +3,8 @@ package reader
 import (
 	"strings"
 	"unicode"
+
+	"github.com/fabiofenoglio/excelconv/database"
 )
 
 func stringToCode(raw string) string {
@@ -23,3 +25,9 @@ func removeWhiteSpaces(str string) string {
 	}
 	return b.String()
 }
+
+// isLunchRoom tells whether the room of the row is the lunch one, where a row can be booked without a time.
+func isLunchRoom(raw string) bool {
+	knownRoom, isKnown := database.GetKnownRoom(stringToCode(raw))
+	return isKnown && knownRoom.Code == database.LunchRoomCode
+}
@@ -1,5 +1,6 @@
 package reader
 
 type Input struct {
-	FilePath string
+	FilePath             string
+	AvailabilityFilePath string
 }
@@ -49,3 +49,27 @@ type Row struct {
 	Confirmed                   *bool
 	IsPlaceholderNumeroAttivita bool
 }
+
+type AvailabilityRow struct {
+	// campi calcolati in fase di lettura
+	rowNumber uint
+
+	// campi che verranno esposti così come letti, senza conversione
+
+	Operator string `column:"educatore" required:"true"`
+	Note     string `column:"nota"`
+
+	// campi che verranno convertiti prima di essere esposti
+
+	DateRawString  string `column:"data" required:"true"`
+	TimesRawString string `column:"orario"`
+	KindRawString  string `column:"tipo"`
+
+	// campi che saranno esposti dopo apposita conversione
+
+	Date      time.Time
+	StartTime time.Time
+	EndTime   time.Time
+	AllDay    bool
+	IsAbsence bool
+}
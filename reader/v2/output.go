@@ -2,18 +2,33 @@ package reader
 
 type OutputRow Row
 
+// RowNumber is the number of the row in the input sheet
+func (r OutputRow) RowNumber() uint {
+	return r.rowNumber
+}
+
+type OutputAvailabilityRow AvailabilityRow
+
 type Output struct {
-	Rows []OutputRow
+	Rows         []OutputRow
+	Availability []OutputAvailabilityRow
 }
 
-func ToOutput(rows []Row) Output {
+func ToOutput(rows []Row, availability []AvailabilityRow) Output {
 	outRows := make([]OutputRow, 0, len(rows))
 
 	for _, row := range rows {
 		outRows = append(outRows, OutputRow(row))
 	}
 
+	outAvailability := make([]OutputAvailabilityRow, 0, len(availability))
+
+	for _, row := range availability {
+		outAvailability = append(outAvailability, OutputAvailabilityRow(row))
+	}
+
 	return Output{
-		Rows: outRows,
+		Rows:         outRows,
+		Availability: outAvailability,
 	}
 }
@@ -38,18 +38,54 @@ func ReadFromFile(ctx config.WorkflowContext, input string) ([]Row, error) {
 
 	startingHeaderCell := excel.NewCell(sheetName, 1, 4)
 
+	tableRows, err := readTable(ctx, f, startingHeaderCell, reflect.TypeOf(Row{}))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Row, 0, len(tableRows))
+
+	for i, tableRow := range tableRows {
+		row := Row{
+			ID:        i + 1,
+			rowNumber: tableRow.rowNumber,
+		}
+		tableRow.assignTo(&row)
+		results = append(results, row)
+	}
+
+	return results, nil
+}
+
+type tableRow struct {
+	rowNumber uint
+	values    map[string]string
+}
+
+func (r tableRow) assignTo(target interface{}) {
+	val := reflect.ValueOf(target).Elem()
+	for fieldName, content := range r.values {
+		val.FieldByName(fieldName).SetString(content)
+	}
+}
+
+// readTable reads a table having the headers row at startingHeaderCell,
+// mapping each column to the field of rowType having a matching `column` tag.
+// Reading stops at the first completely empty row.
+func readTable(ctx config.WorkflowContext, f *excelize.File, startingHeaderCell excel.Cell, rowType reflect.Type) ([]tableRow, error) {
+	log := ctx.Logger
+	var err error
+
 	columnNameToFieldNameMap := make(map[string]string)
-	sampleRow := &Row{}
-	val := reflect.ValueOf(sampleRow).Elem()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
 		columnName := stringToCode(field.Tag.Get("column"))
 		if columnName != "" {
 			columnNameToFieldNameMap[columnName] = field.Name
 		}
 	}
 
-	currentHeaderCell := startingHeaderCell
+	currentHeaderCell := startingHeaderCell.Copy()
 	headers := make([]string, 0, 10)
 	fieldNameToColumnNumberMap := make(map[string]uint)
 
@@ -85,8 +121,8 @@ func ReadFromFile(ctx config.WorkflowContext, input string) ([]Row, error) {
 		currentHeaderCell.MoveRight(1)
 	}
 
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
 		columnName := field.Tag.Get("column")
 
 		required := false
@@ -105,7 +141,7 @@ func ReadFromFile(ctx config.WorkflowContext, input string) ([]Row, error) {
 				errMsg := fmt.Sprintf("field '%s' is NOT mapped by any column", field.Name)
 				if required {
 					log.Error(errMsg)
-					return nil, errors.Errorf("nel file di input manca una colonna con nome '%s'", columnName)
+					return nil, errors.Errorf("nel foglio '%s' manca una colonna con nome '%s'", startingHeaderCell.SheetName(), columnName)
 				} else {
 					log.Warn(errMsg)
 				}
@@ -116,18 +152,15 @@ func ReadFromFile(ctx config.WorkflowContext, input string) ([]Row, error) {
 	log.Debug("mapping build completed")
 	log.Debug("all required fields have a valid mapping column")
 
-	results := make([]Row, 0, 20)
+	results := make([]tableRow, 0, 20)
 
 	currentCell := startingHeaderCell.AtBottom(1)
-	id := 1
 
 	for {
-		row := Row{
-			ID:        id,
+		row := tableRow{
 			rowNumber: currentCell.Row(),
+			values:    make(map[string]string),
 		}
-		id++
-		anyNonNil := false
 
 		for fieldName, columnNumber := range fieldNameToColumnNumberMap {
 			cell := currentCell.AtColumn(columnNumber)
@@ -145,14 +178,13 @@ func ReadFromFile(ctx config.WorkflowContext, input string) ([]Row, error) {
 			cellContent = trimmed
 
 			if cellContent != "" {
-				anyNonNil = true
-				reflect.ValueOf(&row).Elem().FieldByName(fieldName).SetString(cellContent)
+				row.values[fieldName] = cellContent
 
 				log.Debugf("setting row %d.%s to '%s' by cell %s", len(results), fieldName, cellContent, cell.Code())
 			}
 		}
 
-		if !anyNonNil {
+		if len(row.values) == 0 {
 			break
 		}
 
@@ -4,11 +4,35 @@ import (
 	_ "time/tzdata"
 
 	"github.com/fabiofenoglio/excelconv/config"
+	"github.com/fabiofenoglio/excelconv/rules"
+	"github.com/pkg/errors"
 )
 
+// RuleA0 is a rule applied to the rows right after reading and converting them.
+type RuleA0 interface {
+	rules.Rule
+	Apply(ctx config.WorkflowContext, rows []Row) ([]Row, error)
+}
+
+var ruleA0Registry = rules.NewRegistry[RuleA0](rules.StageA0)
+
+func RegisteredRulesA0() []RuleA0 {
+	return ruleA0Registry.All()
+}
+
 func ApplyRuleA0Level(
-	_ config.WorkflowContext,
+	ctx config.WorkflowContext,
 	rows []Row,
 ) ([]Row, error) {
+	for _, rule := range ruleA0Registry.Enabled(ctx) {
+		ctx.Logger.Debugf("applying rule %s", rule.Code())
+
+		var err error
+		rows, err = rule.Apply(ctx, rows)
+		if err != nil {
+			return nil, errors.Wrapf(err, "errore nella regola %s", rule.Code())
+		}
+	}
+
 	return rows, nil
 }
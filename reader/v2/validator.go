@@ -7,6 +7,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+var (
+	timeRangeRegexp = regexp.MustCompile(`^([0-9]|0[0-9]|1[0-9]|2[0-3]):([0-9]|[0-5][0-9])\s*\-\s*([0-9]|0[0-9]|1[0-9]|2[0-3]):([0-9]|[0-5][0-9])$`)
+	dateRegexp      = regexp.MustCompile(`^\d{2}\/\d{2}\/\d{4}$`)
+)
+
 func Validate(rows []Row) error {
 	for _, row := range rows {
 		if err := validateRow(row); err != nil {
@@ -21,12 +26,40 @@ func validateRow(r Row) error {
 		return errors.New("codice mancante")
 	}
 
-	timeRangeRegexp := regexp.MustCompile(`^([0-9]|0[0-9]|1[0-9]|2[0-3]):([0-9]|[0-5][0-9])\s*\-\s*([0-9]|0[0-9]|1[0-9]|2[0-3]):([0-9]|[0-5][0-9])$`)
-	if !timeRangeRegexp.MatchString(r.TimesRawString) {
+	if r.TimesRawString == "" {
+		// a lunch can be booked without a turn, the parser proposes one
+		if !isLunchRoom(r.Room) {
+			return errors.New("orario mancante, atteso HH:MM-HH:MM")
+		}
+	} else if !timeRangeRegexp.MatchString(r.TimesRawString) {
 		return errors.Errorf("orario non valido, atteso HH:MM-HH:MM e non '%s'", r.TimesRawString)
 	}
 
-	dateRegexp := regexp.MustCompile(`^\d{2}\/\d{2}\/\d{4}$`)
+	if !dateRegexp.MatchString(r.DateRawString) {
+		return errors.Errorf("data non valida, atteso GG/MM/YYYY e non '%s'", r.DateRawString)
+	}
+
+	return nil
+}
+
+func ValidateAvailability(rows []AvailabilityRow) error {
+	for _, row := range rows {
+		if err := validateAvailabilityRow(row); err != nil {
+			return errors.Wrapf(err, "la riga %d delle disponibilità non è valida", row.rowNumber)
+		}
+	}
+	return nil
+}
+
+func validateAvailabilityRow(r AvailabilityRow) error {
+	if r.Operator == "" {
+		return errors.New("educatore mancante")
+	}
+
+	if r.TimesRawString != "" && !timeRangeRegexp.MatchString(r.TimesRawString) {
+		return errors.Errorf("orario non valido, atteso HH:MM-HH:MM o nessun valore per l'intera giornata e non '%s'", r.TimesRawString)
+	}
+
 	if !dateRegexp.MatchString(r.DateRawString) {
 		return errors.Errorf("data non valida, atteso GG/MM/YYYY e non '%s'", r.DateRawString)
 	}
@@ -0,0 +1,39 @@
+package reader
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRowTimes(t *testing.T) {
+	type testCase struct {
+		room  string
+		times string
+		valid bool
+	}
+
+	testCases := []testCase{
+		{"Museo", "09:30-10:30", true},
+		{"Museo", "", false},
+		{"Museo", "9.30", false},
+		// only a lunch can be booked without a turn
+		{"Pranzo", "", true},
+		{" pranzo ", "", true},
+		{"Pranzo", "12:00-13:00", true},
+		{"Pranzo", "12", false},
+	}
+
+	for i, testCase := range testCases {
+		testCase := testCase
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			err := validateRow(Row{BookingCode: "B001", Room: testCase.room, DateRawString: "10/03/2025", TimesRawString: testCase.times})
+			if testCase.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
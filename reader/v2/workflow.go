@@ -32,7 +32,7 @@ func Execute(ctx config.WorkflowContext, input Input) (Output, error) {
 	}
 
 	span = sentry.StartSpan(ctx.Context, "convert rows data")
-	rows, err = Convert(rows)
+	rows, err = Convert(ctx, rows)
 	span.Finish()
 	if err != nil {
 		return Output{}, errors.Wrap(err, "errore nella conversione dei dati di input")
@@ -45,8 +45,29 @@ func Execute(ctx config.WorkflowContext, input Input) (Output, error) {
 		return Output{}, errors.Wrap(err, "errore nell'applicazione delle regole di livello A0")
 	}
 
+	span = sentry.StartSpan(ctx.Context, "read availability")
+	availability, err := ReadAvailability(ctx.ForContext(span.Context()), input.FilePath, input.AvailabilityFilePath)
+	span.Finish()
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nella lettura delle disponibilità degli educatori")
+	}
+
+	span = sentry.StartSpan(ctx.Context, "validate availability")
+	err = ValidateAvailability(availability)
+	span.Finish()
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nella validazione delle disponibilità degli educatori")
+	}
+
+	span = sentry.StartSpan(ctx.Context, "convert availability")
+	availability, err = ConvertAvailability(ctx, availability)
+	span.Finish()
+	if err != nil {
+		return Output{}, errors.Wrap(err, "errore nella conversione delle disponibilità degli educatori")
+	}
+
 	// randomizer: randomize rows to enforce full sorting
-	rand.Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
+	rand.New(rand.NewSource(ctx.Config.Seed)).Shuffle(len(rows), func(i, j int) { rows[i], rows[j] = rows[j], rows[i] })
 
-	return ToOutput(rows), nil
+	return ToOutput(rows, availability), nil
 }
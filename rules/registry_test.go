@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+type testRule struct {
+	code             string
+	stage            Stage
+	enabledByDefault bool
+}
+
+func (r testRule) Code() string           { return r.code }
+func (r testRule) Stage() Stage           { return r.stage }
+func (r testRule) Description() string    { return r.code }
+func (r testRule) EnabledByDefault() bool { return r.enabledByDefault }
+
+func TestRegistryEnabled(t *testing.T) {
+	registry := NewRegistry[testRule](StageB0)
+	registry.Register(
+		testRule{code: "first", stage: StageB0, enabledByDefault: true},
+		testRule{code: "second", stage: StageB0, enabledByDefault: false},
+		testRule{code: "third", stage: StageB0, enabledByDefault: true},
+	)
+
+	codes := func(rules []testRule) []string {
+		out := make([]string, 0, len(rules))
+		for _, r := range rules {
+			out = append(out, r.Code())
+		}
+		return out
+	}
+
+	ctx := config.WorkflowContext{
+		Context: context.Background(),
+		Logger:  logrus.NewEntry(logrus.New()),
+	}
+	assert.Equal(t, []string{"first", "third"}, codes(registry.Enabled(ctx)))
+
+	ctx.Config.Rules = map[string]bool{"second": true, "third": false}
+	assert.Equal(t, []string{"first", "second"}, codes(registry.Enabled(ctx)))
+
+	assert.Equal(t, []string{"first", "second", "third"}, codes(registry.All()))
+}
+
+func TestRegistryRejectsInvalidRules(t *testing.T) {
+	registry := NewRegistry[testRule](StageB0)
+	registry.Register(testRule{code: "first", stage: StageB0})
+
+	assert.Panics(t, func() {
+		registry.Register(testRule{code: "first", stage: StageB0})
+	})
+	assert.Panics(t, func() {
+		registry.Register(testRule{code: "other", stage: StageA0})
+	})
+	assert.Panics(t, func() {
+		registry.Register(testRule{stage: StageB0})
+	})
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/fabiofenoglio/excelconv/config"
+)
+
+type Stage string
+
+const (
+	// StageA0 rules work on the raw rows, right after reading them
+	StageA0 Stage = "A0"
+	// StageB0 rules work on the parsed rows and groups, before the activities are resolved
+	StageB0 Stage = "B0"
+	// StagePostAggregation rules work on the aggregated schedule, before writing it
+	StagePostAggregation Stage = "post-aggregation"
+)
+
+// Rule is a business rule applied at a given stage of the workflow.
+// Every stage defines its own sub-interface with the Apply method working on the stage data.
+type Rule interface {
+	Code() string
+	Stage() Stage
+	Description() string
+	EnabledByDefault() bool
+}
+
+// Registry keeps the rules of a stage in the order they have to be applied.
+type Registry[R Rule] struct {
+	stage Stage
+	rules []R
+	codes map[string]bool
+}
+
+func NewRegistry[R Rule](stage Stage) *Registry[R] {
+	return &Registry[R]{
+		stage: stage,
+		codes: make(map[string]bool),
+	}
+}
+
+func (r *Registry[R]) Register(rules ...R) {
+	for _, rule := range rules {
+		if rule.Code() == "" {
+			panic("rule must have a code")
+		}
+		if rule.Stage() != r.stage {
+			panic(fmt.Sprintf("rule %s belongs to stage %s and can not be registered in stage %s", rule.Code(), rule.Stage(), r.stage))
+		}
+		if r.codes[rule.Code()] {
+			panic(fmt.Sprintf("rule %s is already registered", rule.Code()))
+		}
+		r.codes[rule.Code()] = true
+		r.rules = append(r.rules, rule)
+	}
+}
+
+func (r *Registry[R]) All() []R {
+	out := make([]R, len(r.rules))
+	copy(out, r.rules)
+	return out
+}
+
+// Enabled returns the rules that are enabled in the given context, in registration order.
+func (r *Registry[R]) Enabled(ctx config.WorkflowContext) []R {
+	out := make([]R, 0, len(r.rules))
+	for _, rule := range r.rules {
+		if ctx.Config.IsRuleEnabled(rule.Code(), rule.EnabledByDefault()) {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// LogChange records a change applied by a rule.
+func LogChange(ctx config.WorkflowContext, rule Rule, format string, args ...interface{}) {
+	ctx.Logger.
+		WithField("rule", rule.Code()).
+		WithField("stage", string(rule.Stage())).
+		Infof(format, args...)
+}
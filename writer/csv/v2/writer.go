@@ -0,0 +1,262 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	"github.com/fabiofenoglio/excelconv/config"
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
+	"github.com/pkg/errors"
+)
+
+const separator = ';'
+
+func ComputeOperatorSuggestionsOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "suggerimenti")
+}
+
+func ComputeNormalizationsOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "normalizzazioni")
+}
+
+func ComputeReferentContactsOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "contatti")
+}
+
+func ComputePlacementExplanationsOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "piazzamento")
+}
+
+func ComputeLayoutChangesOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "spostamenti")
+}
+
+func ComputeChangesOutputFile(inputFile string) string {
+	return computeOutputFile(inputFile, "modifiche")
+}
+
+func computeOutputFile(inputFile string, suffix string) string {
+	outPath := filepath.Dir(inputFile)
+	inputName := filepath.Base(inputFile)
+	inputExt := filepath.Ext(inputFile)
+	return outPath + "/" + strings.TrimSuffix(inputName, inputExt) + "-" + suffix + ".csv"
+}
+
+func WriteOperatorSuggestions(ctx config.WorkflowContext, suggestions []parser2.OperatorSuggestion, anagraphicsRef *parser2.OutputAnagraphics) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing operator suggestions with CSV writer")
+
+	records := [][]string{
+		{"codice", "data", "orario", "aula", "evento", "codice educatore", "educatore suggerito"},
+	}
+
+	for _, suggestion := range suggestions {
+		room := anagraphicsRef.Rooms[suggestion.RoomCode]
+		activity := anagraphicsRef.Activities[suggestion.ActivityCode]
+		operator := anagraphicsRef.Operators[suggestion.OperatorCode]
+
+		records = append(records, []string{
+			suggestion.BookingCode,
+			suggestion.StartTime.Format("02/01/2006"),
+			suggestion.StartTime.Format("15:04") + " - " + suggestion.EndTime.Format("15:04"),
+			room.Name,
+			activity.Name,
+			operator.Code,
+			operator.Name,
+		})
+	}
+
+	return serialize(records)
+}
+
+func WriteNormalizations(ctx config.WorkflowContext, normalizations []parser2.ActivityNameNormalization) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing activity name normalizations with CSV writer")
+
+	records := [][]string{
+		{"nome originale", "nome normalizzato", "confidenza", "righe"},
+	}
+
+	for _, normalization := range normalizations {
+		rowNumbers := make([]string, 0, len(normalization.RowNumbers))
+		for _, rowNumber := range normalization.RowNumbers {
+			rowNumbers = append(rowNumbers, strconv.FormatUint(uint64(rowNumber), 10))
+		}
+
+		records = append(records, []string{
+			normalization.Original,
+			normalization.Normalized,
+			fmt.Sprintf("%.0f%%", normalization.Confidence*100),
+			strings.Join(rowNumbers, ", "),
+		})
+	}
+
+	return serialize(records)
+}
+
+func WriteReferentContacts(ctx config.WorkflowContext, contacts []parser2.ReferentContact, anagraphicsRef *parser2.OutputAnagraphics) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing referent contacts with CSV writer")
+
+	records := [][]string{
+		{"nome", "email", "scuola", "tipo scuola", "gruppi", "date"},
+	}
+
+	for _, contact := range contacts {
+		school := anagraphicsRef.Schools[contact.SchoolCode]
+
+		groups := make([]string, 0, len(contact.GroupCodes))
+		for _, code := range contact.GroupCodes {
+			groups = append(groups, strings.ToUpper(code))
+		}
+		dates := make([]string, 0, len(contact.Dates))
+		for _, date := range contact.Dates {
+			dates = append(dates, date.Format("02/01/2006"))
+		}
+
+		records = append(records, []string{
+			strings.Join(contact.Names, ", "),
+			strings.Join(contact.Emails, ", "),
+			school.DisplayName(),
+			school.DisplayType(),
+			strings.Join(groups, ", "),
+			strings.Join(dates, ", "),
+		})
+	}
+
+	return serialize(records)
+}
+
+// WritePlacementExplanations writes a line for each slot evaluated for each activity, with the points of every factor.
+func WritePlacementExplanations(ctx config.WorkflowContext, explanations []aggregator2.PlacementExplanation, anagraphicsRef *parser2.OutputAnagraphics) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing placement explanations with CSV writer")
+
+	header := []string{"data", "orario", "aula", "evento", "gruppi", "colonna", "disponibile", "punteggio", "scelta"}
+	header = append(header, aggregator2.SlotScoreFactors...)
+	header = append(header, "note")
+	records := [][]string{header}
+
+	for _, explanation := range explanations {
+		room := anagraphicsRef.Rooms[explanation.RoomCode]
+		activity := anagraphicsRef.Activities[explanation.ActivityCode]
+
+		groups := make([]string, 0, len(explanation.GroupCodes))
+		for _, code := range explanation.GroupCodes {
+			groups = append(groups, strings.ToUpper(code))
+		}
+
+		common := []string{
+			explanation.Day.Format("02/01/2006"),
+			explanation.StartTime.Format("15:04") + " - " + explanation.EndTime.Format("15:04"),
+			room.Name,
+			activity.Name,
+			strings.Join(groups, ", "),
+		}
+
+		if len(explanation.Candidates) == 0 {
+			record := append(append([]string{}, common...), strconv.Itoa(explanation.ChosenSlot+1), "", "", "sì")
+			record = append(record, make([]string, len(aggregator2.SlotScoreFactors))...)
+			records = append(records, append(record, explanation.Note))
+			continue
+		}
+
+		for _, candidate := range explanation.Candidates {
+			record := append(append([]string{}, common...), strconv.Itoa(candidate.SlotIndex+1))
+			if !candidate.Fits {
+				record = append(record, "no", "", "")
+				record = append(record, make([]string, len(aggregator2.SlotScoreFactors))...)
+				records = append(records, append(record, ""))
+				continue
+			}
+
+			chosen := ""
+			if candidate.SlotIndex == explanation.ChosenSlot {
+				chosen = "sì"
+			}
+			record = append(record, "sì", strconv.Itoa(candidate.Score), chosen)
+			for _, factor := range aggregator2.SlotScoreFactors {
+				record = append(record, strconv.Itoa(candidate.PointsFor(factor)))
+			}
+			records = append(records, append(record, explanation.Note))
+		}
+	}
+
+	return serialize(records)
+}
+
+func WriteLayoutChanges(ctx config.WorkflowContext, changes []aggregator2.LayoutChange, anagraphicsRef *parser2.OutputAnagraphics) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing layout changes with CSV writer")
+
+	records := [][]string{
+		{"data", "orario", "aula", "evento", "prenotazioni", "colonna precedente", "colonna"},
+	}
+
+	for _, change := range changes {
+		records = append(records, []string{
+			change.Day.Format("02/01/2006"),
+			change.StartTime.Format("15:04") + " - " + change.EndTime.Format("15:04"),
+			anagraphicsRef.Rooms[change.RoomCode].Name,
+			anagraphicsRef.Activities[change.ActivityCode].Name,
+			strings.Join(change.BookingCodes, ", "),
+			strconv.Itoa(change.PreviousSlot + 1),
+			strconv.Itoa(change.Slot + 1),
+		})
+	}
+
+	return serialize(records)
+}
+
+func WriteChanges(ctx config.WorkflowContext, changes []parser2.BookingChange) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing changes with CSV writer")
+
+	records := [][]string{
+		{"modifica", "codice", "data", "orario", "aula", "evento", "gruppo", "riga", "campo", "prima", "dopo"},
+	}
+
+	for _, change := range changes {
+		common := []string{
+			change.KindLabel(),
+			change.BookingCode,
+			change.Date.Format("02/01/2006"),
+			change.StartTime.Format("15:04") + " - " + change.EndTime.Format("15:04"),
+			change.Room,
+			change.Activity,
+			change.Group,
+			"",
+		}
+		if change.RowNumber > 0 {
+			common[7] = strconv.FormatUint(uint64(change.RowNumber), 10)
+		}
+
+		if len(change.Fields) == 0 {
+			records = append(records, append(common, "", "", ""))
+			continue
+		}
+		// one line per field, to be filtered in a spreadsheet
+		for _, field := range change.Fields {
+			records = append(records, append(append([]string{}, common...), field.Field, field.Old, field.New))
+		}
+	}
+
+	return serialize(records)
+}
+
+func serialize(records [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+	w := csv.NewWriter(&buffer)
+	w.Comma = separator
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, errors.Wrap(err, "error serializing data as CSV")
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
 	"github.com/xuri/excelize/v2"
 
 	"github.com/fabiofenoglio/excelconv/excel"
@@ -14,7 +15,7 @@ func buildContentOfActivityComment(c WriteContext, groupedActivities aggregator2
 	cellComment := ``
 
 	for _, warning := range groupedActivities.Warnings() {
-		cellComment += "⚠️ " + warning.Message + "\n\n"
+		cellComment += warningIcon(warning) + " " + warning.Message + "\n\n"
 	}
 
 	room := c.anagraphicsRef.Rooms[groupedActivities.Rows[0].RoomCode]
@@ -25,7 +26,32 @@ func buildContentOfActivityComment(c WriteContext, groupedActivities aggregator2
 
 	if !groupedActivities.StartTime.IsZero() {
 		cellComment += "Orario: " + groupedActivities.StartTime.Format(layoutTimeOnlyInReadableFormat) + " - " +
-			groupedActivities.EndTime.Format(layoutTimeOnlyInReadableFormat) + "\n"
+			groupedActivities.EndTime.Format(layoutTimeOnlyInReadableFormat)
+		if !aggregator2.IsAlignedToGrid(groupedActivities.StartTime, c.minutesStep) ||
+			!aggregator2.IsAlignedToGrid(groupedActivities.EndTime, c.minutesStep) {
+			cellComment += fmt.Sprintf(" %s (non allineato alla griglia di %d minuti)", offGridIcon, c.minutesStep)
+		}
+		cellComment += "\n"
+	}
+
+	for _, row := range groupedActivities.Rows {
+		if row.Change == nil {
+			continue
+		}
+		prefix := ""
+		if len(groupedActivities.Rows) > 1 {
+			prefix = row.BookingCode + ": "
+		}
+		if row.Change.Kind == parser2.ChangeAdded {
+			cellComment += changedIcon + " " + prefix + "aggiunta rispetto alla versione precedente\n"
+		} else {
+			cellComment += changedIcon + " " + prefix + "modificata rispetto alla versione precedente (" + row.Change.DescribeFields() + ")\n"
+		}
+	}
+
+	if groupedActivities.MovedFromPreviousLayout() {
+		cellComment += fmt.Sprintf("%s Spostata dalla colonna %d alla %d rispetto alla versione precedente\n",
+			movedIcon, *groupedActivities.PreviousSlotIndex+1, groupedActivities.StartingSlotIndex+1)
 	}
 
 	for _, act := range groupedActivities.Rows {
@@ -50,7 +76,11 @@ func buildContentOfActivityComment(c WriteContext, groupedActivities aggregator2
 		}
 
 		if operator.Name != "" {
-			cellComment += "Educatore: " + operator.Name + "\n"
+			if act.OperatorSuggested {
+				cellComment += "💡 Educatore suggerito: " + operator.Name + " (da confermare)\n"
+			} else {
+				cellComment += "Educatore: " + operator.Name + "\n"
+			}
 		}
 
 		if act.OperatorNote != "" {
@@ -118,7 +148,7 @@ func buildContentOfActivityCommentForSingleGroupOfGroupedActivity(c WriteContext
 	cellComment := ``
 
 	for _, warning := range act.Warnings {
-		cellComment += "⚠️ " + warning.Message + "\n\n"
+		cellComment += warningIcon(warning) + " " + warning.Message + "\n\n"
 	}
 
 	operator := c.anagraphicsRef.Operators[act.OperatorCode]
@@ -141,7 +171,11 @@ func buildContentOfActivityCommentForSingleGroupOfGroupedActivity(c WriteContext
 	}
 
 	if operator.Name != "" {
-		cellComment += "Educatore: " + operator.Name + "\n"
+		if act.OperatorSuggested {
+			cellComment += "💡 Educatore suggerito: " + operator.Name + " (da confermare)\n"
+		} else {
+			cellComment += "Educatore: " + operator.Name + "\n"
+		}
 	}
 
 	if act.OperatorNote != "" {
@@ -206,3 +240,14 @@ func addCommentToCell(f *excelize.File, cell excel.Cell, content string) error {
 		Text:   commentText,
 	})
 }
+
+func warningIcon(warning parser2.Warning) string {
+	switch warning.Severity {
+	case parser2.SeverityInfo:
+		return "ℹ️"
+	case parser2.SeverityError:
+		return "⛔"
+	default:
+		return "⚠️"
+	}
+}
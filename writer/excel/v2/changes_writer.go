@@ -0,0 +1,78 @@
+package excel
+
+import (
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
+
+	"github.com/fabiofenoglio/excelconv/excel"
+)
+
+const changesSheetName = "modifiche"
+
+var changesHeaders = []string{"MODIFICA", "DATA", "ORARIO", "AULA", "EVENTO", "PRENOTAZIONE", "GRUPPO", "DETTAGLI"}
+
+// writeChangesSheet lists in a dedicated sheet the activities added, removed and modified since the previous version.
+func writeChangesSheet(c WriteContext, changes []parser2.BookingChange) error {
+	f := c.outputFile
+	if _, err := f.NewSheet(changesSheetName); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(changesSheetName, "A", "A", 3); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(changesSheetName, "B", "E", 14); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(changesSheetName, "F", "H", 30); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(changesSheetName, "I", "I", 80); err != nil {
+		return err
+	}
+
+	cursor := excel.NewCell(changesSheetName, 2, 2)
+	if err := f.SetCellValue(changesSheetName, cursor.Code(), "✏️ MODIFICHE RISPETTO ALLA VERSIONE PRECEDENTE"); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(changesSheetName, cursor.Code(), cursor.Code(), c.styleRegister.DayHeaderStyle().SingleCell()); err != nil {
+		return err
+	}
+	cursor.MoveBottom(1)
+
+	if err := writeChangesRow(c, cursor, changesHeaders, c.styleRegister.Get(schoolRecapHeaderStyle)); err != nil {
+		return err
+	}
+	cursor.MoveBottom(1)
+
+	for _, change := range changes {
+		values := []string{
+			change.KindLabel(),
+			change.Date.Format("02/01/2006"),
+			change.StartTime.Format(layoutTimeOnlyInReadableFormat) + " - " + change.EndTime.Format(layoutTimeOnlyInReadableFormat),
+			change.Room,
+			change.Activity,
+			change.BookingCode,
+			change.Group,
+			change.DescribeFields(),
+		}
+		if err := writeChangesRow(c, cursor, values, c.styleRegister.SchoolRecapContactStyle()); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+	}
+
+	return nil
+}
+
+func writeChangesRow(c WriteContext, startCell excel.Cell, values []string, style *RegisteredStyleV2) error {
+	f := c.outputFile
+	cursor := startCell.Copy()
+
+	for _, value := range values {
+		if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), value); err != nil {
+			return err
+		}
+		cursor.MoveRight(1)
+	}
+
+	return f.SetCellStyle(startCell.SheetName(), startCell.Code(), cursor.AtLeft(1).Code(), style.SingleCell())
+}
@@ -17,7 +17,8 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 	minGroupWidth := uint(12)
 	minGroupWidthPerSlot := uint(5)
 	minDayWidthInCells := uint(23)
-	boxCellHeight := float64(20)
+	// the height of the rows scales with the step, so that an hour takes the same space on the printed page
+	boxCellHeight := float64(20) * float64(c.minutesStep) / float64(config.DefaultGridStepMinutes)
 	moreSlotsAtBottom := 0 // add if you want to show some empty time rows after the last one
 
 	cursor := startCell.Copy()
@@ -131,10 +132,7 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 	cursor = startCell.AtBottom(3)
 
 	relativeToDay := func(startTime time.Time, competenceDate time.Time) aggregator2.TimeOfDay {
-		if startTime.YearDay() == competenceDate.YearDay() {
-			return aggregator2.TimeOfDay{Hour: startTime.Hour(), Minute: startTime.Minute()}
-		}
-		return aggregator2.TimeOfDay{Hour: startTime.Hour() + 24, Minute: startTime.Minute()}
+		return aggregator2.RelativeToDay(startTime, competenceDate, c.timeZone)
 	}
 
 	minHourToShowForThisDay := relativeToDay(day.StartAt, day.Day)
@@ -153,11 +151,12 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 		effectiveTime := relativeToDay(currentTime, day.Day)
 
 		{
-			if day.NumeroAttivitaMarkers[currentTime] > 0 {
+			nextTime := currentTime.Add(time.Duration(c.minutesStep) * time.Minute)
+			if numMarkers := sumMarkersInRow(day.NumeroAttivitaMarkers, currentTime, nextTime); numMarkers > 0 {
 				if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), fmt.Sprintf(
 					"%d / %d",
-					day.NumeroAttivitaConfermateMarkers[currentTime],
-					day.NumeroAttivitaMarkers[currentTime],
+					sumMarkersInRow(day.NumeroAttivitaConfermateMarkers, currentTime, nextTime),
+					numMarkers,
 				)); err != nil {
 					return zero, err
 				}
@@ -294,7 +293,8 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 
 		for slotIndex, slot := range group.Slots {
 			for _, act := range slot.GroupedActivities {
-				if act.StartingSlotIndex != slot.SlotIndex {
+				// the lunches without a turn are listed in the lunch section of the day
+				if act.StartingSlotIndex != slot.SlotIndex || act.StartTime.IsZero() {
 					continue
 				}
 				var operators []parser2.Operator
@@ -316,14 +316,18 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 				actStartCell := cursor.Copy()
 				actEndCell := cursor.Copy().AtRight(uint(act.NumOccupiedSlots - 1))
 
+				// activities not aligned to the grid are drawn on all the rows they touch
+				snappedStart, snappedEnd := aggregator2.SnapToGrid(act.StartTime, act.EndTime, c.minutesStep)
+				offGrid := !aggregator2.IsAlignedToGrid(act.StartTime, c.minutesStep) || !aggregator2.IsAlignedToGrid(act.EndTime, c.minutesStep)
+
 				for {
 					if !inRange {
-						if !exited && !timeCursor.Before(act.StartTime) {
+						if !exited && !timeCursor.Before(snappedStart) {
 							inRange = true
 							actStartCell.MoveRow(startCell.Row() + 3 + uint(i))
 						}
 					} else {
-						if !timeCursor.Before(act.EndTime) {
+						if !timeCursor.Before(snappedEnd) {
 							inRange = false
 							exited = true
 							actEndCell.MoveRow(startCell.Row() + 3 + uint(i-1))
@@ -353,7 +357,13 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 						style = c.styleRegister.NoOperatorNeededStyle()
 					}
 				}
-				if len(act.Warnings()) > 0 {
+				if act.AnyOperatorSuggested() {
+					style = c.styleRegister.Merge(style, c.styleRegister.HighlightForSuggestedOperatorStyle())
+				}
+				if act.AnyChanged() {
+					style = c.styleRegister.Merge(style, c.styleRegister.HighlightForChangedStyle())
+				}
+				if act.HasRelevantWarnings() {
 					style = style.WithWarning()
 				} else if !act.AnyConfirmed && ctx.Config.EnableUnconfirmedHighlight {
 					style = c.styleRegister.Merge(style, c.styleRegister.HighlightForUnconfirmedStyle())
@@ -393,8 +403,10 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 					}
 					toWrite = strings.TrimSuffix(toWrite, ", ")
 
-					if len(act.Warnings()) > 0 {
+					if act.HasRelevantWarnings() {
 						toWrite = "⚠️ " + toWrite
+					} else if offGrid {
+						toWrite = offGridIcon + " " + toWrite
 					}
 
 					if err := f.SetCellValue(
@@ -495,8 +507,10 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 
 						for r := actStartCell.Row(); r <= actEndCell.Row(); r++ {
 							effectiveWrite := writeInCell
-							if len(act.Warnings()) > 0 && slotCnt == 0 && actEndCell.Row() > actStartCell.Row() && r == actStartCell.Row() {
+							if act.HasRelevantWarnings() && slotCnt == 0 && actEndCell.Row() > actStartCell.Row() && r == actStartCell.Row() {
 								effectiveWrite = "⚠️"
+							} else if offGrid && slotCnt == 0 && r == actStartCell.Row() {
+								effectiveWrite = offGridIcon + " " + writeInCell
 							}
 
 							if err := f.SetCellValue(
@@ -569,6 +583,18 @@ func writeDayGrid(ctx config.WorkflowContext, c WriteContext, day aggregator2.Sc
 	}, nil
 }
 
+// sumMarkersInRow counts the markers falling in the row of the grid between from and to,
+// including the ones not aligned to the grid.
+func sumMarkersInRow(markers map[time.Time]int, from, to time.Time) int {
+	sum := 0
+	for at, count := range markers {
+		if !at.Before(from) && at.Before(to) {
+			sum += count
+		}
+	}
+	return sum
+}
+
 type DayGridWriteResult struct {
 	RowsPlacement map[int]excel.CellBox
 }
@@ -0,0 +1,137 @@
+package excel
+
+import (
+	"strings"
+
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
+
+	"github.com/fabiofenoglio/excelconv/excel"
+)
+
+const financeSheetName = "incassi"
+
+var financeAmountHeaders = []string{"PAGANTI", "PREVISTO", "ACCONTI RICEVUTI", "ACCONTI IN ATTESA", "DA INCASSARE"}
+
+// writeFinanceSheet writes in a dedicated sheet the revenue per day, per school and per group.
+func writeFinanceSheet(c WriteContext, finance aggregator2.FinanceSummary) error {
+	f := c.outputFile
+	if _, err := f.NewSheet(financeSheetName); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(financeSheetName, "A", "A", 3); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(financeSheetName, "B", "C", 35); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(financeSheetName, "D", "H", 18); err != nil {
+		return err
+	}
+
+	cursor := excel.NewCell(financeSheetName, 2, 2)
+
+	writeTable := func(title string, labelHeaders []string, entries []aggregator2.FinanceEntry, labels func(aggregator2.FinanceEntry) []string) error {
+		if err := f.SetCellValue(financeSheetName, cursor.Code(), title); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(financeSheetName, cursor.Code(), cursor.Code(), c.styleRegister.DayHeaderStyle().SingleCell()); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+
+		// label columns are always two, so that the amounts are aligned in all the tables
+		headers := append(append([]string{}, labelHeaders...), make([]string, 2-len(labelHeaders))...)
+		headers = append(headers, financeAmountHeaders...)
+		if err := writeFinanceRow(c, cursor, headers, nil, c.styleRegister.Get(schoolRecapHeaderStyle)); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+
+		for _, entry := range entries {
+			entryLabels := labels(entry)
+			entryLabels = append(entryLabels, make([]string, 2-len(entryLabels))...)
+			if err := writeFinanceRow(c, cursor, entryLabels, &entry, c.styleRegister.SchoolRecapContactStyle()); err != nil {
+				return err
+			}
+			cursor.MoveBottom(1)
+		}
+
+		if err := writeFinanceRow(c, cursor, []string{"TOTALE", ""}, &finance.Total, c.styleRegister.SchoolRecapStyle()); err != nil {
+			return err
+		}
+		cursor.MoveBottom(3)
+		return nil
+	}
+
+	if err := writeTable("💶 INCASSI PER GIORNO", []string{"GIORNO"}, finance.Days, func(entry aggregator2.FinanceEntry) []string {
+		return []string{entry.Day.Format("02/01/2006")}
+	}); err != nil {
+		return err
+	}
+
+	if err := writeTable("🏫 INCASSI PER SCUOLA", []string{"SCUOLA", "TIPO"}, finance.Schools, func(entry aggregator2.FinanceEntry) []string {
+		school := c.anagraphicsRef.Schools[entry.Code]
+		return []string{school.DisplayName(), school.DisplayType()}
+	}); err != nil {
+		return err
+	}
+
+	if err := writeTable("👥 INCASSI PER GRUPPO", []string{"GRUPPO", "SCUOLA"}, finance.Groups, func(entry aggregator2.FinanceEntry) []string {
+		groupRef := c.anagraphicsRef.VisitingGroups[entry.Code]
+		label := strings.ToUpper(entry.Code)
+		if class := c.anagraphicsRef.SchoolClasses[groupRef.SchoolClassCode].FullDescription(); class != "" {
+			label += " - " + class
+		}
+		return []string{label, c.anagraphicsRef.Schools[groupRef.SchoolCode].DisplayName()}
+	}); err != nil {
+		return err
+	}
+
+	if len(finance.UnpricedActivityTypes) > 0 {
+		names := make([]string, 0, len(finance.UnpricedActivityTypes))
+		for _, code := range finance.UnpricedActivityTypes {
+			names = append(names, c.anagraphicsRef.ActivityTypes[code].Name)
+		}
+		if err := f.SetCellValue(financeSheetName, cursor.Code(), "⚠️ Tipologie senza prezzo a listino: "+strings.Join(names, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFinanceRow(c WriteContext, startCell excel.Cell, labels []string, entry *aggregator2.FinanceEntry, style *RegisteredStyleV2) error {
+	f := c.outputFile
+	cursor := startCell.Copy()
+
+	for _, label := range labels {
+		if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), label); err != nil {
+			return err
+		}
+		cursor.MoveRight(1)
+	}
+
+	if entry == nil {
+		return f.SetCellStyle(startCell.SheetName(), startCell.Code(), cursor.AtLeft(1).Code(), style.SingleCell())
+	}
+
+	if err := f.SetCellStyle(startCell.SheetName(), startCell.Code(), cursor.Code(), style.SingleCell()); err != nil {
+		return err
+	}
+	if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), entry.NumPaying); err != nil {
+		return err
+	}
+	cursor.MoveRight(1)
+
+	amountsStart := cursor.Copy()
+	for _, amount := range []parser2.Money{entry.Expected, entry.Received, entry.PendingAdvances, entry.Outstanding} {
+		if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), amount.Euros()); err != nil {
+			return err
+		}
+		cursor.MoveRight(1)
+	}
+
+	currency := c.styleRegister.Merge(style, c.styleRegister.Get(currencyStyle))
+	return f.SetCellStyle(amountsStart.SheetName(), amountsStart.Code(), cursor.AtLeft(1).Code(), currency.SingleCell())
+}
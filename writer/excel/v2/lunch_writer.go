@@ -0,0 +1,82 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	"github.com/fabiofenoglio/excelconv/excel"
+)
+
+func writeLunchTableForDay(c WriteContext, day aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots, startCell excel.Cell, availableColumns uint) error {
+	cursor := startCell.Copy()
+	rightColumn := startCell.Column() + availableColumns - 1
+	columnWidths := []uint{4, 3, 0}
+	rowsWritten := 0
+
+	writeColumns := func(values []string, style *RegisteredStyleV2) error {
+		if err := writeTableRow(c, cursor.AtColumn(startCell.Column()), rightColumn, columnWidths, values, style); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+		return nil
+	}
+
+	if err := writeColumns([]string{"🍽 PRANZO", "POSTI", "GRUPPI"}, c.styleRegister.Get(schoolRecapHeaderStyle)); err != nil {
+		return err
+	}
+
+	displayCodes := make(map[string]string)
+	for _, visitingGroup := range day.VisitingGroups {
+		displayCodes[visitingGroup.VisitingGroupCode] = visitingGroup.DisplayCode
+	}
+	describeGroups := func(codes []string) string {
+		out := make([]string, 0, len(codes))
+		for _, code := range codes {
+			if displayCode, ok := displayCodes[code]; ok {
+				out = append(out, displayCode)
+			} else {
+				out = append(out, code)
+			}
+		}
+		return strings.Join(out, ", ")
+	}
+
+	for _, turn := range day.Lunch.Turns {
+		seats := fmt.Sprintf("%d", turn.SeatsUsed)
+		if turn.Seats > 0 {
+			seats = fmt.Sprintf("%d / %d", turn.SeatsUsed, turn.Seats)
+		}
+		groups := describeGroups(turn.VisitingGroupCodes)
+		style := c.styleRegister.SchoolRecapStyle()
+		if turn.IsOverbooked() {
+			groups = "⚠️ OLTRE LA CAPIENZA: " + groups
+			style = c.styleRegister.SchoolRecapNotesStyle()
+		}
+
+		if err := writeColumns([]string{
+			turn.StartTime.Format(layoutTimeOnlyInReadableFormat) + " - " + turn.EndTime.Format(layoutTimeOnlyInReadableFormat),
+			seats,
+			groups,
+		}, style); err != nil {
+			return err
+		}
+		rowsWritten++
+	}
+
+	if len(day.Lunch.Unassigned) > 0 {
+		if err := writeColumns([]string{"SENZA TURNO", "", "⚠️ " + describeGroups(day.Lunch.Unassigned)},
+			c.styleRegister.SchoolRecapNotesStyle()); err != nil {
+			return err
+		}
+		rowsWritten++
+	}
+
+	// leave the same space in every day, so that the following sections stay aligned
+	for rowsWritten < c.allData.CommonData.MaxLunchRowsPerDay {
+		cursor.MoveBottom(1)
+		rowsWritten++
+	}
+
+	return nil
+}
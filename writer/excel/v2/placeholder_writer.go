@@ -1,18 +1,22 @@
 package excel
 
 import (
+	"sort"
 	"strings"
+	"time"
 
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
 	"github.com/fabiofenoglio/excelconv/excel"
 )
 
-func writePlaceholdersForDay(c WriteContext, startCell excel.Cell, availableColumns uint) error {
+func writePlaceholdersForDay(c WriteContext, day aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots, startCell excel.Cell, availableColumns uint) error {
 	f := c.outputFile
 	cursor := startCell.Copy()
 
 	type placeholder struct {
 		emoji string
 		text  string
+		value string
 	}
 
 	// write placeholder for info at the bottom
@@ -22,12 +26,12 @@ func writePlaceholdersForDay(c WriteContext, startCell excel.Cell, availableColu
 		{emoji: "🔀", text: "Cambio Stefano"},
 		{emoji: "🔌", text: "On / off museo"},
 		{emoji: "🛠", text: "Allest. / disallest."},
-		{emoji: "🚷", text: "Assenti"},
+		{emoji: "🚷", text: "Assenti", value: describeAbsentOperators(c, day)},
 		{emoji: "📝", text: "Appuntamenti / note"},
 		{emoji: "🚨", text: "Responsabile emergenza / antincendio"},
 		{emoji: "🧯", text: "Addetto antincendio / impianti"},
 		{emoji: "⛑", text: "Primo soccorso"},
-		{emoji: "🚌", text: "Orari navetta dalle - alle"},
+		{emoji: "🚌", text: "Orari navetta dalle - alle", value: describeShuttleHours(day)},
 	}
 
 	cursor = cursor.AtBottom(1)
@@ -56,7 +60,7 @@ func writePlaceholdersForDay(c WriteContext, startCell excel.Cell, availableColu
 			return err
 		}
 
-		if err := f.SetCellValue(cursor.SheetName(), valueCursors.Code(), ""); err != nil {
+		if err := f.SetCellValue(cursor.SheetName(), valueCursors.Code(), rowToWrite.value); err != nil {
 			return err
 		}
 		if err := f.MergeCell(cursor.SheetName(), valueCursors.Code(), valueCursors.AtColumn(lastColumn).Code()); err != nil {
@@ -76,3 +80,34 @@ func writePlaceholdersForDay(c WriteContext, startCell excel.Cell, availableColu
 
 	return nil
 }
+
+// describeAbsentOperators lists the operators with an absence overlapping the given day,
+// adding the time range for partial absences.
+func describeAbsentOperators(c WriteContext, day aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots) string {
+	dayStart := time.Date(day.Day.Year(), day.Day.Month(), day.Day.Day(), 0, 0, 0, 0, day.Day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	entries := make([]string, 0)
+	for _, operator := range c.anagraphicsRef.Operators {
+		toWrite := ""
+		for _, a := range operator.Availability {
+			if !a.IsAbsence || !a.Overlaps(dayStart, dayEnd) {
+				continue
+			}
+			if a.AllDay {
+				toWrite = operator.Name
+				break
+			}
+			toWrite += ", " + a.StartTime.Format(layoutTimeOnlyInReadableFormat) + "-" + a.EndTime.Format(layoutTimeOnlyInReadableFormat)
+		}
+		if strings.HasPrefix(toWrite, ", ") {
+			toWrite = operator.Name + " (" + strings.TrimPrefix(toWrite, ", ") + ")"
+		}
+		if toWrite != "" {
+			entries = append(entries, toWrite)
+		}
+	}
+
+	sort.Strings(entries)
+	return strings.Join(entries, ", ")
+}
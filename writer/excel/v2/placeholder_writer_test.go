@@ -0,0 +1,45 @@
+package excel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	"github.com/fabiofenoglio/excelconv/config"
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func TestDescribeAbsentOperators(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 3, day, hour, 0, 0, 0, config.TimeZone())
+	}
+	absence := func(from, to time.Time) parser2.OperatorAvailability {
+		return parser2.OperatorAvailability{Date: from, StartTime: from, EndTime: to, IsAbsence: true}
+	}
+
+	c := WriteContext{anagraphicsRef: &parser2.OutputAnagraphics{Operators: map[string]parser2.Operator{
+		"marco": {Code: "marco", Name: "Marco", Availability: []parser2.OperatorAvailability{
+			{Date: at(10, 0), StartTime: at(10, 0), EndTime: at(11, 0), AllDay: true, IsAbsence: true},
+		}},
+		"jo": {Code: "jo", Name: "Jo", Availability: []parser2.OperatorAvailability{
+			absence(at(10, 9), at(10, 11)),
+			absence(at(10, 14), at(10, 16)),
+		}},
+		// working hours are not absences
+		"ema": {Code: "ema", Name: "Ema", Availability: []parser2.OperatorAvailability{
+			{Date: at(10, 0), StartTime: at(10, 9), EndTime: at(10, 13)},
+		}},
+		// absent on another day
+		"lorenzo": {Code: "lorenzo", Name: "Lorenzo", Availability: []parser2.OperatorAvailability{
+			absence(at(11, 9), at(11, 13)),
+		}},
+	}}}
+
+	day := aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots{Day: at(10, 12)}
+	assert.Equal(t, "Jo (09:00-11:00, 14:00-16:00), Marco", describeAbsentOperators(c, day))
+
+	day = aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots{Day: at(12, 12)}
+	assert.Equal(t, "", describeAbsentOperators(c, day))
+}
@@ -3,38 +3,12 @@ package excel
 import (
 	"fmt"
 	"github.com/fabiofenoglio/excelconv/parser/v2"
-	"regexp"
 	"strings"
 
 	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
 	"github.com/fabiofenoglio/excelconv/excel"
 )
 
-type rewriteRule struct {
-	find    *regexp.Regexp
-	replace string
-}
-
-var schoolNameRewriteRules = []rewriteRule{
-	{find: regexp.MustCompile(`(?mi)^scuola\s*\:*\s*`), replace: ""},
-	{find: regexp.MustCompile(`(?mi)(^|\s)((?:ISTITUTO\s+COMPRENSIVO|I\.C\.|(?:IC))[\s\-]+)+`), replace: "${1}I.C. "},
-	{find: regexp.MustCompile(`(?mi)istruzione\s+secondaria\s*superiore`), replace: "I.S.S."},
-	{find: regexp.MustCompile(`(?mi)(?:^|\s)(primaria)(?:$|\s)`), replace: " EL "},
-	{find: regexp.MustCompile(`(?mi)(?:^|\s)(secondaria\s+(I°?|primo)\s+grado)(?:$|\s)`), replace: " SM "},
-	{find: regexp.MustCompile(`(?mi)(?:^|\s)(secondaria\s+(II°?|secondo)\s+grado)(?:$|\s)`), replace: " SUP "},
-	{find: regexp.MustCompile(`[\r\n]+`), replace: " "},
-	{find: regexp.MustCompile(`[\s\t]+`), replace: " "},
-	{find: regexp.MustCompile(`^\s*`), replace: ""},
-	{find: regexp.MustCompile(`\s*$`), replace: ""},
-}
-
-func rewriteSchoolNameWithRules(raw string) string {
-	for _, r := range schoolNameRewriteRules {
-		raw = r.find.ReplaceAllString(raw, r.replace)
-	}
-	return raw
-}
-
 func writeSchoolsForDay(c WriteContext, groups []aggregator2.VisitingGroupInDay, startCell excel.Cell, availableColumns uint) error {
 	f := c.outputFile
 	commonData := c.allData.CommonData
@@ -143,10 +117,10 @@ func writeSchoolsForDay(c WriteContext, groups []aggregator2.VisitingGroupInDay,
 			// school is different than previous line, write a new one
 			lastSchoolCodeWrote = groupRef.SchoolCode
 
-			if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), rewriteSchoolNameWithRules(schoolRef.Name)); err != nil {
+			if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), schoolRef.DisplayName()); err != nil {
 				return err
 			}
-			if err := f.SetCellValue(cursor.SheetName(), cursor.AtRight(7).Code(), rewriteSchoolNameWithRules(schoolRef.Type)); err != nil {
+			if err := f.SetCellValue(cursor.SheetName(), cursor.AtRight(7).Code(), schoolRef.DisplayType()); err != nil {
 				return err
 			}
 		}
@@ -208,6 +182,9 @@ func writeSchoolsForDay(c WriteContext, groups []aggregator2.VisitingGroupInDay,
 		addNote(groupRef.SpecialProjectNotes)
 		addNote(groupRef.BookingNotes)
 		addNote(groupRef.OperatorNotes)
+		for _, highlight := range groupRef.Highlights {
+			addNote(c.anagraphicsRef.HighlightKinds[highlight].Message)
+		}
 
 		toWrite = strings.TrimSuffix(toWrite, "\n")
 		didWriteNotes := false
@@ -220,14 +197,7 @@ func writeSchoolsForDay(c WriteContext, groups []aggregator2.VisitingGroupInDay,
 
 		cursor.MoveBottom(1)
 
-		toWrite = ""
-		if groupRef.ClassTeacher != "" {
-			toWrite += groupRef.ClassTeacher
-		}
-		if groupRef.ClassRefEmail != "" {
-			toWrite += " - " + groupRef.ClassRefEmail
-		}
-		toWrite = strings.TrimSuffix(toWrite, "\n")
+		toWrite = describeContacts(groupRef)
 
 		writeContactIn := cursor.Copy()
 		if !didWriteNotes {
@@ -312,13 +282,24 @@ func highlightsToStyle(c WriteContext, highlights []parser.HighlightReason) *Reg
 	}
 
 	for _, highlight := range highlights {
-		if highlight == parser.HighlightSpecialProject {
-			return c.styleRegister.HighlightForSpecialProjectStyle()
-		}
-		if highlight == parser.HighlightSpecialNotes {
-			return c.styleRegister.HighlightForSpecialNotesStyle()
+		if kind, ok := c.anagraphicsRef.HighlightKinds[highlight]; ok && kind.Color != "" {
+			return c.styleRegister.HighlightStyle(kind.Color)
 		}
 	}
 
 	return c.styleRegister.SchoolRecapNotesStyle()
 }
+
+func describeContacts(groupRef parser.VisitingGroup) string {
+	out := strings.Join(groupRef.Teachers, ", ")
+	if len(groupRef.Emails) > 0 {
+		if out != "" {
+			out += " - "
+		}
+		out += strings.Join(groupRef.Emails, ", ")
+	}
+	if len(groupRef.InvalidEmails) > 0 {
+		out = strings.TrimSpace(out + " ⚠️ " + strings.Join(groupRef.InvalidEmails, ", "))
+	}
+	return out
+}
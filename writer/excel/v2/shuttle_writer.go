@@ -0,0 +1,175 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
+	"github.com/fabiofenoglio/excelconv/excel"
+)
+
+func writeShuttleTimetableForDay(c WriteContext, day aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots, startCell excel.Cell, availableColumns uint) error {
+	f := c.outputFile
+	shuttle := day.Shuttle
+	cursor := startCell.Copy()
+	rightColumn := startCell.Column() + availableColumns - 1
+
+	writeFullRow := func(text string, style *RegisteredStyleV2, height float64) error {
+		if err := f.MergeCell(cursor.SheetName(), cursor.AtColumn(startCell.Column()).Code(), cursor.AtColumn(rightColumn).Code()); err != nil {
+			return err
+		}
+		if err := f.SetCellValue(cursor.SheetName(), cursor.AtColumn(startCell.Column()).Code(), text); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(cursor.SheetName(), cursor.AtColumn(startCell.Column()).Code(), cursor.AtColumn(rightColumn).Code(),
+			style.SingleCell()); err != nil {
+			return err
+		}
+		if err := f.SetRowHeight(cursor.SheetName(), int(cursor.Row()), height); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+		return nil
+	}
+
+	if err := writeFullRow("🚌 NAVETTE E PARCHEGGIO", c.styleRegister.Get(schoolRecapHeaderStyle), 25); err != nil {
+		return err
+	}
+
+	columnWidths := []uint{1, 9, 3, 3, 0}
+	writeColumns := func(values []string, style *RegisteredStyleV2) error {
+		if err := writeTableRow(c, cursor.AtColumn(startCell.Column()), rightColumn, columnWidths, values, style); err != nil {
+			return err
+		}
+		cursor.MoveBottom(1)
+		return nil
+	}
+
+	if err := writeColumns([]string{"#", "SCUOLA", "ARRIVO", "PARTENZA", "BUS"}, c.styleRegister.Get(schoolRecapHeaderStyle)); err != nil {
+		return err
+	}
+
+	// write bus by bus
+	displayCodes := make(map[string]string)
+	for _, bus := range shuttle.Buses {
+		displayCodes[bus.VisitingGroupCode] = bus.DisplayCode
+
+		groupRef := c.anagraphicsRef.VisitingGroups[bus.VisitingGroupCode]
+		schoolRef := c.anagraphicsRef.Schools[groupRef.SchoolCode]
+
+		description := fmt.Sprintf("%d bus", bus.Vehicles)
+		if bus.Company != "" {
+			description += " - " + bus.Company
+		}
+
+		if err := writeColumns([]string{
+			bus.DisplayCode,
+			schoolRef.DisplayName(),
+			formatShuttleTime(bus.ArrivalTime, bus.EstimatedArrival),
+			formatShuttleTime(bus.DepartureTime, bus.EstimatedDeparture),
+			description,
+		}, c.styleRegister.SchoolRecapStyle()); err != nil {
+			return err
+		}
+	}
+
+	// write the arrivals and departures grouped by time window
+	describeWindows := func(label string, windows []aggregator2.ShuttleWindow) string {
+		entries := make([]string, 0, len(windows))
+		for _, w := range windows {
+			groups := make([]string, 0, len(w.VisitingGroupCodes))
+			for _, code := range w.VisitingGroupCodes {
+				groups = append(groups, displayCodes[code])
+			}
+			entries = append(entries, fmt.Sprintf("%s-%s: %d bus (%s)",
+				w.Start.Format(layoutTimeOnlyInReadableFormat), w.End.Format(layoutTimeOnlyInReadableFormat),
+				w.Vehicles, strings.Join(groups, ", ")))
+		}
+		return label + " " + strings.Join(entries, " / ")
+	}
+
+	if len(shuttle.Arrivals) > 0 {
+		if err := writeFullRow(describeWindows("ARRIVI", shuttle.Arrivals), c.styleRegister.SchoolRecapContactStyle(), 20); err != nil {
+			return err
+		}
+	}
+	if len(shuttle.Departures) > 0 {
+		if err := writeFullRow(describeWindows("PARTENZE", shuttle.Departures), c.styleRegister.SchoolRecapContactStyle(), 20); err != nil {
+			return err
+		}
+	}
+
+	if shuttle.MaxParked > 0 {
+		toWrite := fmt.Sprintf("PARCHEGGIO: massimo %d bus contemporaneamente alle %s",
+			shuttle.MaxParked, shuttle.MaxParkedAt.Format(layoutTimeOnlyInReadableFormat))
+		style := c.styleRegister.SchoolRecapContactStyle()
+		if shuttle.ParkingCapacity > 0 {
+			toWrite += fmt.Sprintf(" (capienza %d)", shuttle.ParkingCapacity)
+		}
+		if shuttle.ExceedsParkingCapacity() {
+			toWrite = "⚠️ " + toWrite + " - CAPIENZA SUPERATA"
+			style = c.styleRegister.SchoolRecapNotesStyle()
+		}
+		if err := writeFullRow(toWrite, style, 20); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTableRow writes the values in a row, merging the cells of each column as in the schools recap.
+// A column with zero width takes the remaining space up to the right column.
+func writeTableRow(c WriteContext, startCell excel.Cell, rightColumn uint, columnWidths []uint, values []string, style *RegisteredStyleV2) error {
+	f := c.outputFile
+	cursor := startCell.Copy()
+
+	for i, value := range values {
+		to := cursor.AtColumn(rightColumn)
+		if columnWidths[i] > 0 {
+			to = cursor.AtRight(columnWidths[i] - 1)
+		}
+		if to.Column() > cursor.Column() {
+			if err := f.MergeCell(cursor.SheetName(), cursor.Code(), to.Code()); err != nil {
+				return err
+			}
+		}
+		if err := f.SetCellValue(cursor.SheetName(), cursor.Code(), value); err != nil {
+			return err
+		}
+		cursor.MoveRight(columnWidths[i])
+	}
+
+	if err := f.SetCellStyle(startCell.SheetName(), startCell.Code(), startCell.AtColumn(rightColumn).Code(), style.SingleCell()); err != nil {
+		return err
+	}
+	return f.SetRowHeight(startCell.SheetName(), int(startCell.Row()), 20)
+}
+
+// formatShuttleTime marks with a "~" the times estimated from the activities of the group.
+func formatShuttleTime(t time.Time, estimated bool) string {
+	if t.IsZero() {
+		return "?"
+	}
+	if estimated {
+		return "~" + t.Format(layoutTimeOnlyInReadableFormat)
+	}
+	return t.Format(layoutTimeOnlyInReadableFormat)
+}
+
+// describeShuttleHours is the time range the shuttle is needed, from the first arrival to the last departure.
+func describeShuttleHours(day aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots) string {
+	shuttle := day.Shuttle
+	if shuttle.FirstArrival.IsZero() && shuttle.LastDeparture.IsZero() {
+		return ""
+	}
+	from, to := "?", "?"
+	if !shuttle.FirstArrival.IsZero() {
+		from = shuttle.FirstArrival.Format(layoutTimeOnlyInReadableFormat)
+	}
+	if !shuttle.LastDeparture.IsZero() {
+		to = shuttle.LastDeparture.Format(layoutTimeOnlyInReadableFormat)
+	}
+	return from + " - " + to
+}
@@ -9,6 +9,7 @@ type StyleDefV2 struct {
 	Fill      *excelize.Fill
 	Border    *StyleDefV2Border
 	Font      *excelize.Font
+	NumFmt    *string
 	AsWarning func(s *excelize.Style)
 }
 
@@ -28,9 +29,10 @@ func (d *StyleDefV2) base() *excelize.Style {
 	}
 
 	return &excelize.Style{
-		Alignment: d.Alignment,
-		Fill:      fill,
-		Font:      d.Font,
+		Alignment:    d.Alignment,
+		Fill:         fill,
+		Font:         d.Font,
+		CustomNumFmt: d.NumFmt,
 	}
 }
 
@@ -141,19 +141,24 @@ var (
 			Bottom: true,
 		},
 	}
-	highlightForSpecialProjectStyle = &StyleDefV2{
+	highlightForSuggestedOperatorStyle = &StyleDefV2{
 		Border: &StyleDefV2Border{
-			Color:  "#9900cc",
-			Style:  5,
+			Color:  "#E69500",
+			Style:  8,
 			Bottom: true,
 			Left:   true,
 			Top:    true,
 			Right:  true,
 		},
+		Font: defaultFontBuilder(&FontOverride{
+			ForceSize: 10,
+			ForceBold: true,
+			Color:     "#8A5A00",
+		}),
 	}
-	highlightForSpecialNotesStyle = &StyleDefV2{
+	highlightForChangedStyle = &StyleDefV2{
 		Border: &StyleDefV2Border{
-			Color:  "#0066ff",
+			Color:  "#C2185B",
 			Style:  5,
 			Bottom: true,
 			Left:   true,
@@ -363,6 +368,12 @@ var (
 		AsWarning: standardWarningVariant,
 	}
 
+	currencyFormat = `"€" #,##0.00`
+	// currencyStyle only sets the number format, to be merged with a complete style
+	currencyStyle = &StyleDefV2{
+		NumFmt: &currencyFormat,
+	}
+
 	softDividerStyle = &StyleDefV2{
 		Border: &StyleDefV2Border{
 			Color:  "333333",
@@ -417,3 +428,16 @@ func buildForOperator(color string) *StyleDefV2 {
 		AsWarning: standardWarningVariant,
 	}
 }
+
+func buildForHighlight(color string) *StyleDefV2 {
+	return &StyleDefV2{
+		Border: &StyleDefV2Border{
+			Color:  color,
+			Style:  5,
+			Bottom: true,
+			Left:   true,
+			Top:    true,
+			Right:  true,
+		},
+	}
+}
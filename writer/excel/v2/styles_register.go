@@ -160,12 +160,24 @@ func (r *StyleRegister) SchoolRecapContactStyle() *RegisteredStyleV2 {
 	return r.registerIfNeeded(schoolRecapContactStyle)
 }
 
-func (r *StyleRegister) HighlightForSpecialProjectStyle() *RegisteredStyleV2 {
-	return r.registerIfNeeded(highlightForSpecialProjectStyle)
+func (r *StyleRegister) HighlightStyle(color string) *RegisteredStyleV2 {
+	key := "highlight/" + strings.ToLower(color)
+	if v, ok := r.registeredStyles[key]; ok {
+		return v
+	}
+
+	reg := r.registerIfNeeded(buildForHighlight(color))
+
+	r.registeredStyles[key] = reg
+	return reg
 }
 
-func (r *StyleRegister) HighlightForSpecialNotesStyle() *RegisteredStyleV2 {
-	return r.registerIfNeeded(highlightForSpecialNotesStyle)
+func (r *StyleRegister) HighlightForSuggestedOperatorStyle() *RegisteredStyleV2 {
+	return r.registerIfNeeded(highlightForSuggestedOperatorStyle)
+}
+
+func (r *StyleRegister) HighlightForChangedStyle() *RegisteredStyleV2 {
+	return r.registerIfNeeded(highlightForChangedStyle)
 }
 
 func (r *StyleRegister) HighlightForUnconfirmedStyle() *RegisteredStyleV2 {
@@ -213,6 +225,7 @@ func (r *StyleRegister) Merge(style1, style2 *RegisteredStyleV2) *RegisteredStyl
 			Fill:      style1.styleDef.Fill,
 			Border:    style1.styleDef.Border,
 			Font:      style1.styleDef.Font,
+			NumFmt:    style1.styleDef.NumFmt,
 			AsWarning: style1.styleDef.AsWarning,
 		}
 	}
@@ -231,6 +244,9 @@ func (r *StyleRegister) Merge(style1, style2 *RegisteredStyleV2) *RegisteredStyl
 	if o.Font != nil {
 		out.Font = o.Font
 	}
+	if o.NumFmt != nil {
+		out.NumFmt = o.NumFmt
+	}
 	if o.AsWarning != nil {
 		out.AsWarning = o.AsWarning
 	}
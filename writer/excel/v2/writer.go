@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	aggregator2 "github.com/fabiofenoglio/excelconv/aggregator/v2"
 	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
@@ -19,12 +20,19 @@ import (
 
 const (
 	layoutTimeOnlyInReadableFormat = "15:04"
+	// offGridIcon marks the activities whose times are not aligned to the rows of the grid
+	offGridIcon = "⏱️"
+	// movedIcon marks the activities placed in another column than in the previous layout
+	movedIcon = "🔀"
+	// changedIcon marks the activities added or modified compared to the previous version of the bookings
+	changedIcon = "✏️"
 )
 
 type WriteContext struct {
 	minHour        aggregator2.TimeOfDay
 	maxHour        aggregator2.TimeOfDay
 	minutesStep    int
+	timeZone       *time.Location
 	allData        aggregator2.Output
 	anagraphicsRef *parser2.OutputAnagraphics
 	outputFile     *excelize.File
@@ -49,6 +57,9 @@ func (w *WriterImpl) Write(ctx config.WorkflowContext, parsed aggregator2.Output
 	// compute the max time range to be shown between all days
 
 	minHourToShow, maxHourToShow := parsed.CommonData.CommonTimespan.Start, parsed.CommonData.CommonTimespan.End
+	gridStep := ctx.Config.GridStepMinutes()
+	// the grid starts on a row aligned to the step even when the first activity is not
+	minHourToShow.Minute -= minHourToShow.Minute % gridStep
 	log.Debugf("will show the range %d to %d (inclusive)", minHourToShow, maxHourToShow)
 
 	// group by start date, ordering by start time ASC
@@ -64,7 +75,8 @@ func (w *WriterImpl) Write(ctx config.WorkflowContext, parsed aggregator2.Output
 	wc := WriteContext{
 		minHour:        minHourToShow,
 		maxHour:        maxHourToShow,
-		minutesStep:    15,
+		minutesStep:    gridStep,
+		timeZone:       ctx.Config.Location(),
 		allData:        parsed,
 		anagraphicsRef: anagraphicsRef,
 		outputFile:     f,
@@ -152,6 +164,24 @@ func (w *WriterImpl) Write(ctx config.WorkflowContext, parsed aggregator2.Output
 	}
 	span.Finish()
 
+	if ctx.Config.PriceList.IsConfigured() && !parsed.Finance.IsEmpty() {
+		span = sentry.StartSpan(ctx.Context, "write finance")
+		if err := writeFinanceSheet(wc, parsed.Finance); err != nil {
+			span.Finish()
+			return nil, errors.Wrap(err, "error writing finance sheet")
+		}
+		span.Finish()
+	}
+
+	if len(parsed.Changes) > 0 {
+		span = sentry.StartSpan(ctx.Context, "write changes")
+		if err := writeChangesSheet(wc, parsed.Changes); err != nil {
+			span.Finish()
+			return nil, errors.Wrap(err, "error writing changes sheet")
+		}
+		span.Finish()
+	}
+
 	span = sentry.StartSpan(ctx.Context, "write to buffer")
 	out, err := f.WriteToBuffer()
 	if err != nil {
@@ -194,6 +224,15 @@ func writeDayWithDetails(ctx config.WorkflowContext, c WriteContext, groupByDay
 
 	numAvailableColumns := tracker.CoveredArea().RightColumn() - tracker.Column()
 
+	// WRITE LUNCH TURNS FOR THE DAY
+	if c.allData.CommonData.MaxLunchRowsPerDay > 0 {
+		err := writeLunchTableForDay(c, groupByDay, tracker, numAvailableColumns)
+		if err != nil {
+			return zero, errors.Wrap(err, "error writing lunch turns for day")
+		}
+		tracker.MoveAtBottomLeftOfCoveredArea()
+	}
+
 	// WRITE SCHOOL/GROUPS FOR THE DAY
 	schoolGroupsForThisDay := groupByDay.VisitingGroups
 	if len(schoolGroupsForThisDay) > 0 {
@@ -219,11 +258,21 @@ func writeDayWithDetails(ctx config.WorkflowContext, c WriteContext, groupByDay
 
 	// WRITE PLACEHOLDERS FOR ORDER OF THE DAY
 	{
-		err := writePlaceholdersForDay(c, tracker, numAvailableColumns)
+		err := writePlaceholdersForDay(c, groupByDay, tracker, numAvailableColumns)
 		if err != nil {
 			return zero, errors.Wrap(err, "error writing placeholders for OOD")
 		}
 	}
 
+	// WRITE SHUTTLE TIMETABLE FOR THE DAY
+	if !groupByDay.Shuttle.IsEmpty() {
+		tracker.MoveAtBottomLeftOfCoveredArea()
+
+		err := writeShuttleTimetableForDay(c, groupByDay, tracker, numAvailableColumns)
+		if err != nil {
+			return zero, errors.Wrap(err, "error writing shuttle timetable")
+		}
+	}
+
 	return out, nil
 }
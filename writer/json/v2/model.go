@@ -8,5 +8,7 @@ import (
 type SerializableOutput struct {
 	CommonData     aggregator2.CommonData                                   `json:"CommonData"`
 	Days           []aggregator2.ScheduleForSingleDayWithRoomsAndGroupSlots `json:"Days"`
+	Finance        aggregator2.FinanceSummary                               `json:"Finance"`
 	AnagraphicsRef *parser2.OutputAnagraphics                               `json:"Anagraphics"`
+	Changes        []parser2.BookingChange                                  `json:"Changes,omitempty"`
 }
@@ -23,7 +23,9 @@ func (w *WriterImpl) Write(ctx config.WorkflowContext, parsed aggregator2.Output
 	out := SerializableOutput{
 		CommonData:     parsed.CommonData,
 		Days:           parsed.Days,
+		Finance:        parsed.Finance,
 		AnagraphicsRef: anagraphicsRef,
+		Changes:        parsed.Changes,
 	}
 
 	serialized, err := json.MarshalIndent(out, "", "  ")
@@ -40,3 +42,22 @@ func (w *WriterImpl) ComputeDefaultOutputFile(inputFile string) string {
 	inputExt := filepath.Ext(inputFile)
 	return outPath + "/" + strings.TrimSuffix(inputName, inputExt) + "-parsed.json"
 }
+
+func ComputePlacementExplanationsOutputFile(inputFile string) string {
+	outPath := filepath.Dir(inputFile)
+	inputName := filepath.Base(inputFile)
+	inputExt := filepath.Ext(inputFile)
+	return outPath + "/" + strings.TrimSuffix(inputName, inputExt) + "-piazzamento.json"
+}
+
+func WritePlacementExplanations(ctx config.WorkflowContext, explanations []aggregator2.PlacementExplanation) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing placement explanations with JSON writer")
+
+	serialized, err := json.MarshalIndent(explanations, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "error serializing placement explanations as JSON")
+	}
+
+	return serialized, nil
+}
@@ -0,0 +1,64 @@
+package vcard
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/fabiofenoglio/excelconv/config"
+	parser2 "github.com/fabiofenoglio/excelconv/parser/v2"
+)
+
+func ComputeReferentContactsOutputFile(inputFile string) string {
+	outPath := filepath.Dir(inputFile)
+	inputName := filepath.Base(inputFile)
+	inputExt := filepath.Ext(inputFile)
+	return outPath + "/" + strings.TrimSuffix(inputName, inputExt) + "-contatti.vcf"
+}
+
+// WriteReferentContacts writes the contacts as vCard 3.0, one card for each referent.
+func WriteReferentContacts(ctx config.WorkflowContext, contacts []parser2.ReferentContact, anagraphicsRef *parser2.OutputAnagraphics) ([]byte, error) {
+	log := ctx.Logger
+	log.Debug("writing referent contacts with vCard writer")
+
+	var buffer bytes.Buffer
+	line := func(key, value string) {
+		buffer.WriteString(key + ":" + value + "\r\n")
+	}
+
+	for _, contact := range contacts {
+		school := anagraphicsRef.Schools[contact.SchoolCode]
+
+		line("BEGIN", "VCARD")
+		line("VERSION", "3.0")
+		line("FN", escape(contact.DisplayName()))
+		if len(contact.Names) > 0 {
+			line("N", escape(contact.Names[0])+";;;;")
+		} else {
+			line("N", ";;;;")
+		}
+		for _, email := range contact.Emails {
+			line("EMAIL;TYPE=INTERNET", email)
+		}
+		if school.DisplayName() != "" {
+			line("ORG", escape(school.DisplayName()))
+		}
+
+		groups := make([]string, 0, len(contact.GroupCodes))
+		for _, code := range contact.GroupCodes {
+			groups = append(groups, strings.ToUpper(code))
+		}
+		dates := make([]string, 0, len(contact.Dates))
+		for _, date := range contact.Dates {
+			dates = append(dates, date.Format("02/01/2006"))
+		}
+		line("NOTE", escape("Gruppi: "+strings.Join(groups, ", ")+" - Date: "+strings.Join(dates, ", ")))
+		line("END", "VCARD")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(value)
+}